@@ -0,0 +1,133 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"GoTorch/internal/history"
+)
+
+const historyUsage = "Usage: cli history list [--store path] [--map KEY] [--since YYYY-MM-DD] [--until YYYY-MM-DD] [--limit N]\n" +
+	"       cli history show [--store path] <id>\n" +
+	"       cli history delete [--store path] <id>"
+
+// runHistory lists, shows, queries and deletes saved map runs.
+func runHistory(args []string) int {
+	if len(args) == 0 {
+		fmt.Println(historyUsage)
+		return 2
+	}
+	cmd, args := args[0], args[1:]
+	fs := flag.NewFlagSet("history "+cmd, flag.ContinueOnError)
+	fs.SetOutput(os.Stdout)
+	storePath := fs.String("store", history.DefaultPath(), "History file")
+	mapKey := fs.String("map", "", "Only runs of this map key")
+	since := fs.String("since", "", "Only runs started on/after this date (YYYY-MM-DD)")
+	until := fs.String("until", "", "Only runs started before this date (YYYY-MM-DD)")
+	limit := fs.Int("limit", 0, "Only the N most recent runs")
+	if err := fs.Parse(args); err != nil {
+		fmt.Println(historyUsage)
+		return 2
+	}
+	store := history.Open(*storePath)
+
+	switch cmd {
+	case "list":
+		q := history.Query{MapKey: *mapKey, Limit: *limit}
+		var err error
+		if q.Since, err = parseDate(*since); err != nil {
+			fmt.Println("error: --since:", err)
+			return 2
+		}
+		if q.Until, err = parseDate(*until); err != nil {
+			fmt.Println("error: --until:", err)
+			return 2
+		}
+		recs, err := store.Query(q)
+		if err != nil {
+			fmt.Println("error:", err)
+			return 1
+		}
+		printHistory(recs)
+		return 0
+	case "show", "delete":
+		if fs.NArg() != 1 {
+			fmt.Println(historyUsage)
+			return 2
+		}
+		id := fs.Arg(0)
+		if cmd == "delete" {
+			if err := store.Delete(id); err != nil {
+				fmt.Println("error:", err)
+				return 1
+			}
+			fmt.Println("Deleted", id)
+			return 0
+		}
+		r, err := store.Get(id)
+		if err != nil {
+			fmt.Println("error:", err)
+			return 1
+		}
+		printRecord(r)
+		return 0
+	default:
+		fmt.Println(historyUsage)
+		return 2
+	}
+}
+
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
+
+func printHistory(recs []history.Record) {
+	if len(recs) == 0 {
+		fmt.Println("No saved runs.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tStarted\tMap\tDuration\tDrops\tEarnings")
+	for _, r := range recs {
+		var drops int
+		for _, n := range r.Tally {
+			drops += n
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%.2f\n", r.ID, r.StartedAt.Format("2006-01-02 15:04"), mapLabel(r.Session()), r.Duration().Truncate(time.Second), drops, r.Earnings())
+	}
+	_ = w.Flush()
+}
+
+func printRecord(r history.Record) {
+	fmt.Printf("ID: %s\n", r.ID)
+	fmt.Printf("Map: %s\n", mapLabel(r.Session()))
+	fmt.Printf("Started: %s\n", r.StartedAt.Format(time.RFC3339))
+	fmt.Printf("Duration: %s\n", r.Duration().Truncate(time.Second))
+	fmt.Printf("Earnings: %.2f\n", r.Earnings())
+	if len(r.Spent) > 0 {
+		fmt.Printf("Cost: %.2f\nNet: %.2f\n", r.Cost(), r.Net())
+	}
+	ids := make([]int, 0, len(r.Tally))
+	for id := range r.Tally {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		fmt.Printf("- %d x%d @ %.4f\n", id, r.Tally[id], r.Prices[id])
+	}
+	ids = ids[:0]
+	for id := range r.Spent {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		fmt.Printf("- spent %d x%d @ %.4f\n", id, r.Spent[id], r.SpentPrices[id])
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"GoTorch/internal/history"
+	"GoTorch/internal/tracker"
+)
+
+func TestRunHistoryListShowDelete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store := history.Open(path)
+	start := time.Date(2025, 11, 4, 19, 0, 0, 0, time.Local)
+	rec, err := store.Append(history.NewRecord(start, tracker.MapSession{MapKey: "YJ_A", Region: "07YJ", StartedAt: start, EndedAt: start.Add(2 * time.Minute), Tally: map[int]int{1001: 3}}, func(int) float64 { return 1.5 }))
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	var code int
+	out := captureStdout(t, func() { code = run([]string{"history", "list", "--store", path, "--map", "YJ_A", "--since", "2025-11-04"}) })
+	if code != 0 || !strings.Contains(out, rec.ID) || !strings.Contains(out, "YJ_A (07YJ)") || !strings.Contains(out, "4.50") {
+		t.Fatalf("unexpected list output (code %d)\n%s", code, out)
+	}
+	out = captureStdout(t, func() { code = run([]string{"history", "list", "--store", path, "--until", "2025-11-04"}) })
+	if code != 0 || !strings.Contains(out, "No saved runs.") {
+		t.Fatalf("expected empty filtered list (code %d)\n%s", code, out)
+	}
+	out = captureStdout(t, func() { code = run([]string{"history", "show", "--store", path, rec.ID}) })
+	if code != 0 || !strings.Contains(out, "- 1001 x3 @ 1.5000") {
+		t.Fatalf("unexpected show output (code %d)\n%s", code, out)
+	}
+	out = captureStdout(t, func() { code = run([]string{"history", "delete", "--store", path, rec.ID}) })
+	if code != 0 {
+		t.Fatalf("delete failed (code %d)\n%s", code, out)
+	}
+	captureStdout(t, func() { code = run([]string{"history", "show", "--store", path, rec.ID}) })
+	if code != 1 {
+		t.Fatalf("expected show of deleted id to fail, got %d", code)
+	}
+}
+
+func TestRunHistoryUsage(t *testing.T) {
+	var code int
+	captureStdout(t, func() { code = run([]string{"history"}) })
+	if code != 2 {
+		t.Fatalf("expected 2, got %d", code)
+	}
+	captureStdout(t, func() { code = run([]string{"history", "bogus"}) })
+	if code != 2 {
+		t.Fatalf("expected 2 for unknown subcommand, got %d", code)
+	}
+}
@@ -5,55 +5,169 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
 	"time"
 
+	"GoTorch/internal/app"
+	"GoTorch/internal/goals"
+	"GoTorch/internal/items"
+	"GoTorch/internal/logfiles"
 	"GoTorch/internal/parser"
 	"GoTorch/internal/tailer"
 	"GoTorch/internal/tracker"
+	"GoTorch/internal/types"
 )
 
+const usage = "Usage: cli --log <path or glob>... [--from-start] [--poll-ms N] [--tail-mode auto|poll|watch] [--overflow block|drop-oldest|spill] [--debug] [--once] [--format text|json] [--items file] [--idle 5m] [--goal spec]... [--load-snapshot file] [--save-snapshot file]\n" +
+	"       cli stats --log <path> [--items full_table.json]\n" +
+	"       cli history list|show|delete ...\n" +
+	"       cli replay [--format text|json|csv|md] <log, dir or archive>...\n" +
+	"       cli prices [--items file] [--max-age 24h] [--all]"
+
 func main() {
 	os.Exit(run(os.Args[1:]))
 }
 
 // run is the testable entrypoint for the CLI. It returns an exit code rather than exiting directly.
 func run(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "stats":
+			return runStats(args[1:])
+		case "history":
+			return runHistory(args[1:])
+		case "replay":
+			return runReplay(args[1:])
+		case "prices":
+			return runPrices(args[1:])
+		}
+	}
 	fs := flag.NewFlagSet("cli", flag.ContinueOnError)
 	fs.SetOutput(os.Stdout)
-	logPath := fs.String("log", "", "Path to Torchlight Infinite log file")
+	var logPaths stringList
+	fs.Var(&logPaths, "log", "Torchlight Infinite log file or glob, repeatable to follow several files")
 	fromStart := fs.Bool("from-start", false, "Read from start instead of tailing from end")
 	pollMs := fs.Int("poll-ms", 300, "Polling interval in milliseconds")
+	tailMode := fs.String("tail-mode", "auto", "How to notice new log data: auto (watch where supported), poll or watch")
+	overflowName := fs.String("overflow", "block", "When output falls behind: block reading, drop-oldest lines or spill them to disk")
 	debug := fs.Bool("debug", true, "Print parsed events and errors")
 	once := fs.Bool("once", false, "Process the file once and exit (no live tail)")
+	loadSnap := fs.String("load-snapshot", "", "Start from a tracker snapshot file")
+	saveSnap := fs.String("save-snapshot", "", "Write a tracker snapshot file on exit")
+	format := fs.String("format", "text", "Output format: text or json (NDJSON events and state ticks)")
+	itemsPath := fs.String("items", "", "Item table used to value drops (defaults to the app's lookup)")
+	idle := fs.Duration("idle", tracker.DefaultIdleThreshold, "Gap between events after which time counts as idle (0 disables)")
+	var goalSpecs stringList
+	fs.Var(&goalSpecs, "goal", "Session goal, repeatable: earnings:500, item:<id or name>:20 or maps:30")
 	if err := fs.Parse(args); err != nil {
-		fmt.Println("Usage: cli --log <path> [--from-start] [--poll-ms N] [--debug] [--once]")
+		fmt.Println(usage)
 		return 2
 	}
 
-	if *logPath == "" {
-		fmt.Println("Usage: cli --log <path> [--from-start] [--poll-ms N] [--debug] [--once]")
+	mode, err := tailer.ParseMode(*tailMode)
+	overflow, oerr := tailer.ParseOverflow(*overflowName)
+	if len(logPaths) == 0 || (*format != "text" && *format != "json") || err != nil || oerr != nil {
+		fmt.Println(usage)
 		return 2
 	}
 
-	// Resolve env vars in path (Windows %USERPROFILE%, etc.)
-	resolved := os.ExpandEnv(*logPath)
-	if resolved != *logPath {
-		*logPath = resolved
+	// Resolve env vars in paths (Windows %USERPROFILE%, etc.)
+	for i, path := range logPaths {
+		logPaths[i] = os.ExpandEnv(path)
 	}
+	single := len(logPaths) == 1 && !tailer.IsPattern(logPaths[0])
 
 	p := parser.New()
 	trk := tracker.New()
+	if *loadSnap != "" {
+		b, err := os.ReadFile(*loadSnap)
+		if err == nil {
+			trk, err = tracker.Restore(b)
+		}
+		if err != nil {
+			fmt.Println("error: load snapshot:", err)
+			return 1
+		}
+	}
+	trk.SetIdleThreshold(*idle)
 
-	if *once {
-		if err := processOnce(*logPath, p, trk, *debug); err != nil {
+	// In JSON mode every output line is an NDJSON record; text printers are replaced.
+	onEvent := func(ev *types.Event) {
+		if *debug {
+			fmt.Printf("[%s] %s\n", ev.Time.Format(time.Kitchen), ev.Kind)
+		}
+	}
+	var diag io.Writer = os.Stdout
+	// tick reports the state as of now: the wall clock while following, the last logged event with --once
+	var tick func(trk *tracker.Tracker, now time.Time)
+	var diagnose func(app.UIDiagnostics, app.UITailerStats)
+	var goalSet *goals.Set
+	newGoals := func(cat *items.Catalog) error {
+		if len(goalSpecs) == 0 {
+			return nil
+		}
+		goalSet = goals.NewSet()
+		for _, spec := range goalSpecs {
+			g, err := goals.Parse(spec, cat)
+			if err != nil {
+				return err
+			}
+			goalSet.Add(g)
+		}
+		return nil
+	}
+	switch *format {
+	case "text":
+		// an unreadable table only leaves earnings at zero
+		cat, err := loadCLIItems(*itemsPath)
+		if err != nil {
+			fmt.Println("warning: no item prices loaded:", err)
+		}
+		if err := newGoals(cat); err != nil {
 			fmt.Println("error:", err)
+			return 2
+		}
+		tick = func(trk *tracker.Tracker, now time.Time) {
+			printState(trk, now)
+			printRates(trk, cat, now)
+			printGoals(trk, goalSet, cat, now)
+		}
+		diagnose = func(d app.UIDiagnostics, s app.UITailerStats) {
+			printTailerStats(s)
+			printDiagnostics(d)
+		}
+	case "json":
+		diag = os.Stderr
+		cat, err := loadCLIItems(*itemsPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error: load items:", err)
+			return 1
+		}
+		if err := newGoals(cat); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 2
+		}
+		em := newJSONEmitter(os.Stdout, cat)
+		em.goals = goalSet
+		onEvent = em.event
+		tick = em.state
+		diagnose = em.diagnostics
+	}
+
+	if *once {
+		if err := processLogs(logPaths, p, trk, onEvent); err != nil {
+			fmt.Fprintln(diag, "error:", err)
+			return 1
+		}
+		tick(trk, offlineNow(trk))
+		if err := writeSnapshot(*saveSnap, trk); err != nil {
+			fmt.Fprintln(diag, "error: save snapshot:", err)
 			return 1
 		}
-		printState(trk)
 		return 0
 	}
 
@@ -65,63 +179,154 @@ func run(args []string) int {
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-sigCh
-		fmt.Println("\nStopping...")
+		fmt.Fprintln(diag, "\nStopping...")
 		cancel()
 	}()
 
-	lines := make(chan string, 1024)
-	t := tailer.New(tailer.Options{Path: *logPath, FromStart: *fromStart, PollEvery: time.Duration(*pollMs) * time.Millisecond})
-
-	go func() {
-		if err := t.Start(ctx, lines); err != nil {
-			if *debug {
-				fmt.Println("tailer error:", err)
-			}
+	opt := tailer.Options{FromStart: *fromStart, PollEvery: time.Duration(*pollMs) * time.Millisecond, Mode: mode, Overflow: overflow}
+	tailErr := func(err error) {
+		if err != nil && *debug {
+			fmt.Fprintln(diag, "tailer error:", err)
 		}
-	}()
-
+	}
 	lastPrint := time.Now()
-	scanner := bufio.NewScanner(readerFromChan(ctx, lines))
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-	for scanner.Scan() {
-		line := scanner.Text()
+	var snapshot func() (app.UIDiagnostics, app.UITailerStats)
+	handle := func(line string) {
 		if ev := p.Parse(line); ev != nil {
 			trk.OnEvent(ev)
-			if *debug {
-				fmt.Printf("[%s] %s\n", ev.Time.Format(time.Kitchen), ev.Kind)
-			}
+			onEvent(ev)
 		}
 		if time.Since(lastPrint) >= 1*time.Second {
-			printState(trk)
+			tick(trk, time.Now())
+			diagnose(snapshot())
 			lastPrint = time.Now()
 		}
 	}
-	if err := scanner.Err(); err != nil && ctx.Err() == nil {
-		fmt.Println("scanner error:", err)
+
+	if single {
+		opt.Path = logPaths[0]
+		t := tailer.New(opt)
+		snapshot = func() (app.UIDiagnostics, app.UITailerStats) {
+			return app.ToUIDiagnostics(t), app.ToUITailerStats(t)
+		}
+		lines := make(chan string, 1024)
+		go func() { tailErr(t.Start(ctx, lines)) }()
+
+		scanner := bufio.NewScanner(readerFromChan(ctx, lines))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			handle(scanner.Text())
+		}
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			fmt.Fprintln(diag, "scanner error:", err)
+		}
+	} else {
+		// several files: history is merged by timestamp, then lines arrive as they are written
+		m := tailer.NewMulti(tailer.MultiOptions{Paths: logPaths, Options: opt, Timestamp: p.Timestamp})
+		snapshot = func() (app.UIDiagnostics, app.UITailerStats) {
+			return app.ToUIDiagnostics(m), app.ToUIMultiStats(m)
+		}
+		lines := make(chan tailer.Line, 1024)
+		go func() { tailErr(m.Start(ctx, lines)) }()
+	follow:
+		for {
+			select {
+			case l := <-lines:
+				handle(l.Text)
+			case <-ctx.Done():
+				break follow
+			}
+		}
+	}
+	diagnose(snapshot())
+	if err := writeSnapshot(*saveSnap, trk); err != nil {
+		fmt.Fprintln(diag, "error: save snapshot:", err)
+		return 1
 	}
 	return 0
 }
 
+// offlineNow is the time --once output is evaluated at: the last logged event, so the time
+// between the end of the log and today is not reported as idle.
+func offlineNow(trk *tracker.Tracker) time.Time {
+	if at := trk.GetState().LastEventAt; !at.IsZero() {
+		return at
+	}
+	return time.Now()
+}
+
+// writeSnapshot saves the tracker state to path; an empty path is a no-op.
+func writeSnapshot(path string, trk *tracker.Tracker) error {
+	if path == "" {
+		return nil
+	}
+	b, err := trk.Snapshot()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
 func processOnce(path string, p *parser.Parser, trk *tracker.Tracker, debug bool) error {
-	f, err := os.Open(path)
+	return processLogs([]string{path}, p, trk, func(ev *types.Event) {
+		if debug {
+			fmt.Printf("[%s] %s\n", ev.Time.Format(time.Kitchen), ev.Kind)
+		}
+	})
+}
+
+// processLog feeds every line of a log (a plain or gzip file, or a zip member) through the
+// parser and tracker, calling onEvent for each parsed event.
+func processLog(l logfiles.Log, p *parser.Parser, trk *tracker.Tracker, onEvent func(*types.Event)) error {
+	r, err := l.Open()
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	s := bufio.NewScanner(f)
+	defer r.Close()
+	s := bufio.NewScanner(r)
 	s.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
 	for s.Scan() {
 		line := s.Text()
 		if ev := p.Parse(line); ev != nil {
 			trk.OnEvent(ev)
-			if debug {
-				fmt.Printf("[%s] %s\n", ev.Time.Format(time.Kitchen), ev.Kind)
-			}
+			onEvent(ev)
 		}
 	}
+	// apply a bag sync still pending at the end of the file
+	trk.Flush()
 	return s.Err()
 }
 
+// processLogs is processLog for files, globs, directories and archives. Their logs are read
+// together, merged in timestamp order, so backups follow each other chronologically and logs
+// written side by side interleave. A log starting after the ones before it have ended is a
+// new game session: the map left open is ended and the inventory baseline starts over.
+func processLogs(paths []string, p *parser.Parser, trk *tracker.Tracker, onEvent func(*types.Event)) error {
+	logs, err := logfiles.List(tailer.Expand(paths))
+	if err != nil {
+		return err
+	}
+	srcs := make([]tailer.Source, len(logs))
+	for i, l := range logs {
+		srcs[i] = tailer.Source{Name: l.String(), Open: l.Open}
+	}
+	// logs are opened as the merge reaches them, so a long history of backups is read a few
+	// files at a time
+	err = tailer.MergeSources(srcs, p.Timestamp, func(l tailer.Line) error {
+		if l.Disjoint {
+			trk.EndGame()
+		}
+		if ev := p.Parse(l.Text); ev != nil {
+			trk.OnEvent(ev)
+			onEvent(ev)
+		}
+		return nil
+	})
+	// apply a bag sync still pending at the end of the logs
+	trk.Flush()
+	return err
+}
+
 func readerFromChan(ctx context.Context, ch <-chan string) *chanReader {
 	return &chanReader{ctx: ctx, ch: ch}
 }
@@ -148,19 +353,47 @@ func (r *chanReader) Read(p []byte) (int, error) {
 	}
 }
 
-func printState(trk *tracker.Tracker) {
+// printDiagnostics prints the tailer's line counters, so dropped or spilled lines are visible.
+func printDiagnostics(d app.UIDiagnostics) {
+	how := "polling"
+	if d.Watching {
+		how = "watching"
+	}
+	fmt.Printf("Tailer: %s, %d lines (%d bytes) read, %d sent, %d dropped, %d spilled, %d queued\n",
+		how, d.LinesRead, d.BytesRead, d.LinesSent, d.LinesDropped, d.LinesSpilled, d.Queued)
+}
+
+// printTailerStats prints whether the log is still growing, to tell a game that stopped
+// logging apart from a quiet session.
+func printTailerStats(s app.UITailerStats) {
+	if len(s.Sources) == 0 {
+		printSourceStats("Log", s)
+		return
+	}
+	for _, src := range s.Sources {
+		printSourceStats("Log "+src.Path, src)
+	}
+}
+
+func printSourceStats(label string, s app.UITailerStats) {
+	last := "no data yet"
+	if s.LastDataAt != 0 {
+		last = "last data " + time.Since(time.UnixMilli(s.LastDataAt)).Truncate(time.Second).String() + " ago"
+	}
+	fmt.Printf("%s: %s, %d of %d bytes, %s, %d rotations\n", label, s.Health, s.Offset, s.Size, last, s.Rotations)
+	if s.Error != "" {
+		fmt.Println(label, "error:", s.Error)
+	}
+}
+
+// printState prints the current session as of now.
+func printState(trk *tracker.Tracker, now time.Time) {
 	st := trk.GetState()
 	status := "Idle"
 	if st.InMap && st.Current.Active {
 		status = "In Map"
 	}
-	dur := time.Since(st.Current.StartedAt)
-	if !st.Current.Active {
-		dur = st.Current.EndedAt.Sub(st.Current.StartedAt)
-	}
-	if dur < 0 {
-		dur = 0
-	}
+	dur := st.CurrentDuration(now)
 	fmt.Println("------------------------------")
 	fmt.Printf("Status: %s\n", status)
 	if st.Current.StartedAt.IsZero() {
@@ -168,7 +401,14 @@ func printState(trk *tracker.Tracker) {
 		return
 	}
 	fmt.Printf("Session: %s\n", st.Current.StartedAt.Format(time.RFC3339))
+	if st.Current.MapKey != "" {
+		fmt.Printf("Map: %s\n", mapLabel(st.Current))
+	}
 	fmt.Printf("Duration: %s\n", dur.Truncate(time.Second))
+	if !st.SessionStartedAt.IsZero() {
+		act := st.Activity(now)
+		fmt.Printf("Time: in map %s, town %s, idle %s\n", act.InMap.Truncate(time.Second), act.Town.Truncate(time.Second), act.Idle.Truncate(time.Second))
+	}
 
 	// Totals this session by ConfigBaseID
 	if len(st.Current.Tally) == 0 {
@@ -189,6 +429,18 @@ func printState(trk *tracker.Tracker) {
 		}
 	}
 
+	// Completed maps with their names
+	if len(st.Completed) > 0 {
+		fmt.Printf("Completed maps: %d\n", len(st.Completed))
+		last := st.Completed
+		if len(last) > 5 {
+			last = last[len(last)-5:]
+		}
+		for _, m := range last {
+			fmt.Printf("- %s %s\n", mapLabel(m), m.Duration().Truncate(time.Second))
+		}
+	}
+
 	// Last few events
 	if len(st.LastEvents) > 0 {
 		last := st.LastEvents
@@ -201,3 +453,49 @@ func printState(trk *tracker.Tracker) {
 		}
 	}
 }
+
+// mapLabel formats a map session's identity for display, e.g. "YJ_YongZhouHuiLang200 (07YJ)".
+func mapLabel(m tracker.MapSession) string {
+	if m.MapKey == "" {
+		return "(unknown map)"
+	}
+	if m.Region == "" {
+		return m.MapKey
+	}
+	return m.MapKey + " (" + m.Region + ")"
+}
+
+// printRates prints the session earnings rates over wall-clock and active time.
+func printRates(trk *tracker.Tracker, cat *items.Catalog, now time.Time) {
+	st := trk.GetState()
+	if st.SessionStartedAt.IsZero() {
+		return
+	}
+	ui := app.BuildUIState(st, cat, now)
+	fmt.Printf("Earnings/hour: %.1f (active time: %.1f)\n", ui.EarningsPerHour, ui.ActiveEarningsPerHour)
+	fmt.Printf("Net earnings/hour: %.1f (active time: %.1f)\n", ui.NetEarningsPerHour, ui.ActiveNetEarningsPerHour)
+}
+
+// printGoals prints the progress of each goal and a line for every goal met since the last call.
+func printGoals(trk *tracker.Tracker, set *goals.Set, cat *items.Catalog, now time.Time) {
+	if set == nil {
+		return
+	}
+	all, met := set.Update(trk.GetState(), cat.PriceOf, now)
+	for _, p := range all {
+		fmt.Printf("Goal %s: %s %.0f/%.0f (%.0f%%)\n", p.ID, p.Label, p.Current, p.Target, p.Percent)
+	}
+	for _, p := range met {
+		fmt.Printf("Goal met: %s\n", p.Label)
+	}
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
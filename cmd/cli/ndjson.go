@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"GoTorch/internal/app"
+	"GoTorch/internal/goals"
+	"GoTorch/internal/items"
+	"GoTorch/internal/pricing"
+	"GoTorch/internal/tracker"
+	"GoTorch/internal/types"
+)
+
+// ndjsonEvent is one parsed log event in --format json output.
+type ndjsonEvent struct {
+	Type   string     `json:"type"` // always "event"
+	Time   int64      `json:"time"`
+	Kind   string     `json:"kind"`
+	MapKey string     `json:"mapKey,omitempty"`
+	Region string     `json:"region,omitempty"`
+	Bag    *ndjsonBag `json:"bag,omitempty"`
+}
+
+// ndjsonBag is the bag payload of an event, enriched from the item table.
+type ndjsonBag struct {
+	PageID      int     `json:"pageId"`
+	SlotID      int     `json:"slotId"`
+	ItemID      int     `json:"itemId"`
+	Num         int     `json:"num"`
+	Name        string  `json:"name,omitempty"`
+	Type        string  `json:"type,omitempty"`
+	Price       float64 `json:"price"`
+	PriceStatus string  `json:"priceStatus,omitempty"` // fresh, stale or unknown
+}
+
+// ndjsonState is a state tick; it carries the same fields as app.UIState.
+type ndjsonState struct {
+	Type string `json:"type"` // always "state"
+	app.UIState
+}
+
+// ndjsonGoal is written once when a goal is met.
+type ndjsonGoal struct {
+	Type string `json:"type"` // always "goal"
+	app.UIGoal
+}
+
+// ndjsonDiagnostics carries the tailer counters of a live tail; it has the fields of app.UIDiagnostics.
+type ndjsonDiagnostics struct {
+	Type string `json:"type"` // always "diagnostics"
+	app.UIDiagnostics
+}
+
+// ndjsonTailer is the tailer's position and health; it has the fields of app.UITailerStats.
+type ndjsonTailer struct {
+	Type string `json:"type"` // always "tailer"
+	app.UITailerStats
+}
+
+// jsonEmitter writes NDJSON records, one object per line.
+type jsonEmitter struct {
+	enc   *json.Encoder
+	items *items.Catalog
+	goals *goals.Set // optional; progress is added to state ticks
+}
+
+func newJSONEmitter(w io.Writer, cat *items.Catalog) *jsonEmitter {
+	return &jsonEmitter{enc: json.NewEncoder(w), items: cat}
+}
+
+func (e *jsonEmitter) event(ev *types.Event) {
+	out := ndjsonEvent{Type: "event", Time: ev.Time.UnixMilli(), Kind: ev.Kind.String()}
+	if ev.Scene != nil {
+		out.MapKey = ev.Scene.MapKey
+		out.Region = ev.Scene.Region
+	}
+	if ev.Bag != nil {
+		b := &ndjsonBag{PageID: ev.Bag.PageID, SlotID: ev.Bag.SlotID, ItemID: ev.Bag.ConfigBaseID, Num: ev.Bag.Num}
+		if info, ok := e.items.GetInt(ev.Bag.ConfigBaseID); ok {
+			b.Name, b.Type, b.Price = info.Name, info.Type, info.Price
+			b.PriceStatus = pricing.Staleness(info.LastUpdate, time.Now(), pricing.DefaultMaxAge)
+		}
+		out.Bag = b
+	}
+	_ = e.enc.Encode(out)
+}
+
+func (e *jsonEmitter) state(trk *tracker.Tracker, now time.Time) {
+	st := trk.GetState()
+	ui := app.BuildUIState(st, e.items, now)
+	if e.goals != nil {
+		progress, met := e.goals.Update(st, e.items.PriceOf, now)
+		ui.Goals = app.ToUIGoals(progress)
+		for _, g := range app.ToUIGoals(met) {
+			_ = e.enc.Encode(ndjsonGoal{Type: "goal", UIGoal: g})
+		}
+	}
+	_ = e.enc.Encode(ndjsonState{Type: "state", UIState: ui})
+}
+
+func (e *jsonEmitter) diagnostics(d app.UIDiagnostics, s app.UITailerStats) {
+	_ = e.enc.Encode(ndjsonTailer{Type: "tailer", UITailerStats: s})
+	_ = e.enc.Encode(ndjsonDiagnostics{Type: "diagnostics", UIDiagnostics: d})
+}
+
+// loadCLIItems reads the item table at path, or uses the app's lookup order when path is empty.
+// On error it returns an empty catalog so callers can carry on without prices.
+func loadCLIItems(path string) (*items.Catalog, error) {
+	if path == "" {
+		return items.Load(), nil
+	}
+	cat, err := items.LoadFile(path)
+	if err != nil {
+		return items.New(nil, "none"), err
+	}
+	return cat, nil
+}
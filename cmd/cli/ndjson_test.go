@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunOnceJSONEmitsEventsAndState(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "ue.log")
+	if err := os.WriteFile(p, []byte(testMapStart+testBagInit+testBagMod), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	itemsPath := filepath.Join(dir, "items.json")
+	if err := os.WriteFile(itemsPath, []byte(`{"1001":{"name":"Ember","type":"Fuel","price":5}}`), 0o644); err != nil {
+		t.Fatalf("write items: %v", err)
+	}
+	var code int
+	out := captureStdout(t, func() { code = run([]string{"--log", p, "--once", "--format", "json", "--items", itemsPath}) })
+	if code != 0 {
+		t.Fatalf("expected 0, got %d\n%s", code, out)
+	}
+
+	var records []map[string]json.RawMessage
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		var rec map[string]json.RawMessage
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatalf("line is not JSON: %q: %v", sc.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 4 {
+		t.Fatalf("expected 3 events + 1 state, got %d\n%s", len(records), out)
+	}
+	var ev ndjsonEvent
+	_ = json.Unmarshal(mustJSON(t, records[0]), &ev)
+	if ev.Type != "event" || ev.Kind != "MapStart" || ev.MapKey != "YJ_YongZhouHuiLang200" {
+		t.Fatalf("unexpected first event: %+v", ev)
+	}
+	_ = json.Unmarshal(mustJSON(t, records[2]), &ev)
+	if ev.Bag == nil || ev.Bag.ItemID != 1001 || ev.Bag.Name != "Ember" || ev.Bag.Price != 5 {
+		t.Fatalf("unexpected bag event: %+v", ev)
+	}
+	var st ndjsonState
+	_ = json.Unmarshal(mustJSON(t, records[3]), &st)
+	if st.Type != "state" || !st.InMap || st.TotalDrops != 2 || st.Tally["1001"].Name != "Ember" || st.EarningsPerSession != 10 {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+}
+
+func TestRunOnceJSONKeepsErrorsOffStdout(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "ue.log")
+	if err := os.WriteFile(p, []byte(testMapStart+testBagInit+testBagMod), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	itemsPath := filepath.Join(dir, "items.json")
+	if err := os.WriteFile(itemsPath, []byte(`{}`), 0o644); err != nil {
+		t.Fatalf("write items: %v", err)
+	}
+	snap := filepath.Join(dir, "missing", "snap.json")
+	var code int
+	out := captureStdout(t, func() {
+		code = run([]string{"--log", p, "--once", "--format", "json", "--items", itemsPath, "--save-snapshot", snap})
+	})
+	if code != 1 {
+		t.Fatalf("expected 1 for an unwritable snapshot, got %d\n%s", code, out)
+	}
+	sc := bufio.NewScanner(strings.NewReader(out))
+	for sc.Scan() {
+		if !json.Valid(sc.Bytes()) {
+			t.Fatalf("line is not JSON: %q", sc.Text())
+		}
+	}
+}
+
+func TestRunRejectsUnknownFormat(t *testing.T) {
+	var code int
+	captureStdout(t, func() { code = run([]string{"--log", "x.log", "--format", "xml"}) })
+	if code != 2 {
+		t.Fatalf("expected 2, got %d", code)
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return b
+}
+
+func TestRunOnceMergesSeveralLogsByTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	// the pickup comes first on the command line but last in time
+	pickup := filepath.Join(dir, "steam.log")
+	game := filepath.Join(dir, "standalone.log")
+	if err := os.WriteFile(pickup, []byte(testBagMod), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	// the game log goes on past the pickup, so both belong to one game session
+	later := strings.Replace(testBagMod, "19.20.47", "19.20.48", 1)
+	if err := os.WriteFile(game, []byte(testMapStart+testBagInit+later), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var code int
+	out := captureStdout(t, func() {
+		code = run([]string{"--log", pickup, "--log", filepath.Join(dir, "stand*.log"), "--once", "--format", "json"})
+	})
+	if code != 0 {
+		t.Fatalf("expected 0, got %d\n%s", code, out)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	var st ndjsonState
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &st); err != nil {
+		t.Fatalf("state: %v\n%s", err, out)
+	}
+	if st.Type != "state" || !st.InMap || st.TotalDrops != 2 {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+
+	captureStdout(t, func() { code = run([]string{"--log", pickup, "--log", filepath.Join(dir, "missing.log"), "--once"}) })
+	if code != 1 {
+		t.Fatalf("expected 1 for a missing log, got %d", code)
+	}
+}
+
+func TestRunOnceReadsCompressedBackups(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "UE_game-backup-2025.11.04-19.20.45.log.gz"))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write([]byte(testMapStart + testBagInit + testBagMod)); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	f.Close()
+	if err := os.WriteFile(filepath.Join(dir, "UE_game.log"), []byte(strings.Replace(testMapStart, "19.20.45", "20.00.00", 1)), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var code int
+	out := captureStdout(t, func() { code = run([]string{"--log", dir, "--once", "--format", "json"}) })
+	if code != 0 {
+		t.Fatalf("expected 0, got %d\n%s", code, out)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	var st ndjsonState
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &st); err != nil {
+		t.Fatalf("state: %v\n%s", err, out)
+	}
+	if st.Type != "state" || !st.InMap || st.TotalDrops != 2 || len(st.Maps) != 2 || st.Maps[0].End == 0 {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+}
+
+func TestRunOnceEndsTheMapLeftOpenByABackup(t *testing.T) {
+	dir := t.TempDir()
+	at := func(line, ts string) string { return strings.Replace(line, "19.20.45:474", ts, 1) }
+	// the game was closed inside a map at 10:02 and started again at 20:00
+	backup := at(testMapStart, "10.00.00:000") +
+		strings.Replace(testBagInit, "19.20.46", "10.00.01", 1) +
+		strings.Replace(strings.Replace(testBagMod, "19.20.47", "10.02.00", 1), "Num = 2", "Num = 3", 1)
+	if err := os.WriteFile(filepath.Join(dir, "UE_game-backup-2025.11.04-10.02.00.log"), []byte(backup), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	// the new game lists the bag first, then enters a map and picks up one more
+	live := strings.Replace(strings.Replace(testBagInit, "19.20.46", "20.00.00", 1), "Num = 0", "Num = 10", 1) +
+		at(testMapStart, "20.00.05:000") +
+		strings.Replace(strings.Replace(testBagMod, "19.20.47", "20.01.00", 1), "Num = 2", "Num = 11", 1)
+	if err := os.WriteFile(filepath.Join(dir, "UE_game.log"), []byte(live), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var code int
+	out := captureStdout(t, func() { code = run([]string{"--log", dir, "--once", "--format", "json"}) })
+	if code != 0 {
+		t.Fatalf("expected 0, got %d\n%s", code, out)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	var st ndjsonState
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &st); err != nil {
+		t.Fatalf("state: %v\n%s", err, out)
+	}
+	if len(st.Maps) != 2 || st.Maps[0].DurationMs != 120000 {
+		t.Fatalf("expected the backup's map to end at its last event, got %+v", st.Maps)
+	}
+	// the new game's bag listing is a new baseline, not a drop
+	if !st.InMap || st.TotalDrops != 4 || st.Tally["1001"].Count != 1 {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+}
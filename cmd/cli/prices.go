@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"GoTorch/internal/items"
+	"GoTorch/internal/pricing"
+)
+
+const pricesUsage = "Usage: cli prices [--items full_table.json] [--max-age 24h] [--all]"
+
+// runPrices reports how old the item table's prices are.
+func runPrices(args []string) int {
+	fs := flag.NewFlagSet("prices", flag.ContinueOnError)
+	fs.SetOutput(os.Stdout)
+	itemsPath := fs.String("items", "", "Item table to check (defaults to the app's lookup)")
+	maxAge := fs.Duration("max-age", pricing.DefaultMaxAge, "Prices older than this are stale")
+	all := fs.Bool("all", false, "List every item, not only stale and unknown ones")
+	if err := fs.Parse(args); err != nil {
+		fmt.Println(pricesUsage)
+		return 2
+	}
+	cat, err := loadCLIItems(*itemsPath)
+	if err != nil {
+		fmt.Println("error:", err)
+		return 1
+	}
+	printPriceAges(cat, time.Now(), *maxAge, *all)
+	return 0
+}
+
+func printPriceAges(cat *items.Catalog, now time.Time, maxAge time.Duration, all bool) {
+	ids := cat.IDs()
+	counts := map[string]int{}
+	for _, id := range ids {
+		it, _ := cat.Get(id)
+		counts[pricing.Staleness(it.LastUpdate, now, maxAge)]++
+	}
+	fmt.Printf("Prices from %s: %d fresh, %d stale, %d unknown (max age %s)\n",
+		cat.Source(), counts[pricing.StatusFresh], counts[pricing.StatusStale], counts[pricing.StatusUnknown], maxAge)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := false
+	for _, id := range ids {
+		it, _ := cat.Get(id)
+		status := pricing.Staleness(it.LastUpdate, now, maxAge)
+		if status == pricing.StatusFresh && !all {
+			continue
+		}
+		if !header {
+			fmt.Fprintln(w, "ID\tName\tPrice\tStatus\tAge")
+			header = true
+		}
+		age := "-"
+		if it.LastUpdate > 0 {
+			age = now.Sub(time.Unix(int64(it.LastUpdate), 0)).Truncate(time.Minute).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.4g\t%s\t%s\n", id, it.Name, it.Price, status, age)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunPricesReportsAges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.json")
+	fresh := time.Now().Add(-time.Hour).Unix()
+	old := time.Now().Add(-72 * time.Hour).Unix()
+	table := fmt.Sprintf(`{"1":{"name":"Fresh","price":1,"last_update":%d},"2":{"name":"Old","price":2,"last_update":%d},"3":{"name":"Never","price":3}}`, fresh, old)
+	if err := os.WriteFile(path, []byte(table), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var code int
+	out := captureStdout(t, func() { code = run([]string{"prices", "--items", path}) })
+	if code != 0 || !strings.Contains(out, "1 fresh, 1 stale, 1 unknown") {
+		t.Fatalf("unexpected summary (code %d)\n%s", code, out)
+	}
+	if strings.Contains(out, "Fresh") || !strings.Contains(out, "Old") || !strings.Contains(out, "Never") {
+		t.Fatalf("expected only stale and unknown items listed\n%s", out)
+	}
+	out = captureStdout(t, func() { code = run([]string{"prices", "--items", path, "--all", "--max-age", "100h"}) })
+	if code != 0 || !strings.Contains(out, "2 fresh, 0 stale, 1 unknown") || !strings.Contains(out, "Fresh") {
+		t.Fatalf("unexpected --all output (code %d)\n%s", code, out)
+	}
+	captureStdout(t, func() { code = run([]string{"prices", "--items", filepath.Join(t.TempDir(), "none.json")}) })
+	if code != 1 {
+		t.Fatalf("expected exit 1 for missing table, got %d", code)
+	}
+}
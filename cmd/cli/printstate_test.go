@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"GoTorch/internal/items"
 	"GoTorch/internal/tracker"
 	"GoTorch/internal/types"
 )
@@ -29,7 +30,7 @@ func captureStdout(t *testing.T, fn func()) string {
 
 func TestPrintState_NoSessionYet(t *testing.T) {
 	trk := tracker.New()
-	out := captureStdout(t, func() { printState(trk) })
+	out := captureStdout(t, func() { printState(trk, time.Now()) })
 	if !strings.Contains(out, "No session yet.") {
 		t.Fatalf("expected 'No session yet.' in output\n%s", out)
 	}
@@ -38,16 +39,19 @@ func TestPrintState_NoSessionYet(t *testing.T) {
 func TestPrintState_ActiveAndEndedSession(t *testing.T) {
 	trk := tracker.New()
 	start := time.Now().Add(-3 * time.Second)
-	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start})
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start, Scene: &types.SceneEvent{MapKey: "YJ_A", Region: "07YJ"}})
 	// +5 items
 	trk.OnEvent(&types.Event{Kind: types.EventBagInit, Time: start, Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 42, Num: 0}})
 	trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start.Add(time.Second), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 42, Num: 5}})
 
 	// Active session print
-	outActive := captureStdout(t, func() { printState(trk) })
+	outActive := captureStdout(t, func() { printState(trk, time.Now()) })
 	if !strings.Contains(outActive, "Status: In Map") {
 		t.Fatalf("expected 'In Map' in output\n%s", outActive)
 	}
+	if !strings.Contains(outActive, "Map: YJ_A (07YJ)") {
+		t.Fatalf("expected map name in output\n%s", outActive)
+	}
 	if !strings.Contains(outActive, "Tally:") {
 		t.Fatalf("expected Tally in output\n%s", outActive)
 	}
@@ -55,11 +59,38 @@ func TestPrintState_ActiveAndEndedSession(t *testing.T) {
 	// End session and print again to hit ended branch and items/hour
 	end := start.Add(2 * time.Second)
 	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: end})
-	outEnded := captureStdout(t, func() { printState(trk) })
+	outEnded := captureStdout(t, func() { printState(trk, time.Now()) })
 	if !strings.Contains(outEnded, "Status: Idle") {
 		t.Fatalf("expected 'Idle' in output\n%s", outEnded)
 	}
+	if !strings.Contains(outEnded, "- YJ_A (07YJ)") {
+		t.Fatalf("expected completed map listed in output\n%s", outEnded)
+	}
 	if !strings.Contains(outEnded, "Items/hour:") {
 		t.Fatalf("expected Items/hour in output\n%s", outEnded)
 	}
 }
+
+func TestPrintStateAndRatesShowActiveTime(t *testing.T) {
+	trk := tracker.New()
+	start := time.Now().Add(-time.Hour)
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start})
+	trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start.Add(time.Minute), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 42, Num: 6}})
+	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(2 * time.Minute)})
+	// half an hour AFK, then a second map
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start.Add(32 * time.Minute)})
+	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(34 * time.Minute)})
+	cat := items.New(map[string]items.Item{"42": {Name: "Ember", Price: 1}}, "test")
+
+	out := captureStdout(t, func() {
+		printState(trk, time.Now())
+		printRates(trk, cat, time.Now())
+	})
+	// the time since the last map ended is an open idle gap
+	if !strings.Contains(out, "Time: in map 4m0s, town 0s, idle 56m") {
+		t.Fatalf("expected activity split in output\n%s", out)
+	}
+	if !strings.Contains(out, "Earnings/hour: 10.6 (active time: 90.0)") {
+		t.Fatalf("expected wall-clock and active rates in output\n%s", out)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"GoTorch/internal/logfiles"
+	"GoTorch/internal/parser"
+	"GoTorch/internal/pricehistory"
+	"GoTorch/internal/report"
+	"GoTorch/internal/tracker"
+	"GoTorch/internal/types"
+)
+
+const replayUsage = "Usage: cli replay [--items full_table.json] [--price-history file] [--format text|json|csv|md] [--top N] [--out file] <log, dir or archive>..."
+
+// runReplay rebuilds every map run from historical logs and prints a priced report.
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.SetOutput(os.Stdout)
+	itemsPath := fs.String("items", "", "Item table used to value drops (defaults to the app's lookup)")
+	format := fs.String("format", "text", "Report format: "+strings.Join(report.Formats, ", "))
+	top := fs.Int("top", 10, "Number of top items by value (0 = all)")
+	outPath := fs.String("out", "", "Write the report to a file instead of stdout")
+	histPath := fs.String("price-history", "", "Price history used to value drops at the time they happened")
+	if err := fs.Parse(args); err != nil || fs.NArg() == 0 {
+		fmt.Println(replayUsage)
+		return 2
+	}
+
+	if !validFormat(*format) {
+		fmt.Fprintf(os.Stderr, "error: unknown format %q\n", *format)
+		return 2
+	}
+
+	files, err := collectLogs(fs.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "error: no log files found")
+		return 1
+	}
+	cat, err := loadCLIItems(*itemsPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "warning: no item prices loaded:", err)
+	}
+
+	// Without a price history, drops are valued at the item table prices either way.
+	var atDrop tracker.PriceFunc
+	if *histPath != "" {
+		ix, err := pricehistory.Open(*histPath).Index()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error: price history:", err)
+			return 1
+		}
+		atDrop = func(id int, at time.Time) float64 {
+			if p, ok := ix.PriceAt(strconv.Itoa(id), at); ok {
+				return p
+			}
+			return cat.PriceOf(id)
+		}
+	}
+
+	p := parser.New()
+	sessions := make([]report.Session, 0, len(files))
+	for _, f := range files {
+		// each log file is a separate game session with its own inventory baseline
+		trk := tracker.New()
+		if atDrop != nil {
+			trk.SetPricing(atDrop)
+		}
+		if err := processLog(f, p, trk, func(*types.Event) {}); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		sessions = append(sessions, report.Session{Source: f.String(), Maps: trk.GetState().Completed})
+	}
+	rep := report.Build(sessions, func(id int) (string, float64) {
+		it, _ := cat.GetInt(id)
+		return it.Name, it.Price
+	}, *top)
+
+	var w io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := report.Write(w, rep, *format); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+	return 0
+}
+
+func validFormat(f string) bool {
+	for _, v := range report.Formats {
+		if f == v {
+			return true
+		}
+	}
+	return false
+}
+
+// collectLogs expands arguments into logs. Directories contribute their *.log, *.log.gz and
+// *.zip files; backups are ordered by the time in their name, other logs keep their order.
+func collectLogs(args []string) ([]logfiles.Log, error) {
+	paths := make([]string, len(args))
+	for i, a := range args {
+		paths[i] = os.ExpandEnv(a)
+	}
+	return logfiles.List(paths)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"GoTorch/internal/pricehistory"
+	"GoTorch/internal/pricing"
+	"GoTorch/internal/report"
+)
+
+const (
+	testMapStart = "[2025.11.04-19.20.45:474][302]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = /Game/Art/Maps/UI/LoginScene/LoginScene NextSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200'\n"
+	testBagInit  = "[2025.11.04-19.20.46:000][302]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = 0\n"
+	testBagMod   = "[2025.11.04-19.20.47:000][302]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = 2\n"
+	testMapEnd   = "[2025.11.04-19.21.45:474][302]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200' NextSceneName = World'/Game/Art/Maps/01SD/XZ_YuJinZhiXiBiNanSuo200/XZ_YuJinZhiXiBiNanSuo200.XZ_YuJinZhiXiBiNanSuo200'\n"
+)
+
+func TestRunReplayDirectoryJSON(t *testing.T) {
+	dir := t.TempDir()
+	logs := filepath.Join(dir, "logs")
+	if err := os.Mkdir(logs, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	run1 := testMapStart + testBagInit + testBagMod + testMapEnd
+	for _, name := range []string{"a.log", "b.log"} {
+		if err := os.WriteFile(filepath.Join(logs, name), []byte(run1), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	// non-log files in the directory are ignored
+	_ = os.WriteFile(filepath.Join(logs, "notes.txt"), []byte(run1), 0o644)
+	itemsPath := filepath.Join(dir, "items.json")
+	if err := os.WriteFile(itemsPath, []byte(`{"1001":{"name":"Ember","price":5}}`), 0o644); err != nil {
+		t.Fatalf("write items: %v", err)
+	}
+	outPath := filepath.Join(dir, "report.json")
+
+	var code int
+	out := captureStdout(t, func() {
+		code = run([]string{"replay", "--items", itemsPath, "--format", "json", "--out", outPath, logs})
+	})
+	if code != 0 {
+		t.Fatalf("expected 0, got %d\n%s", code, out)
+	}
+	b, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	var rep report.Report
+	if err := json.Unmarshal(b, &rep); err != nil {
+		t.Fatalf("parse report: %v", err)
+	}
+	if rep.Totals.Sessions != 2 || rep.Totals.Runs != 2 || rep.Totals.Earnings != 20 {
+		t.Fatalf("unexpected totals: %+v", rep.Totals)
+	}
+	if len(rep.TopItems) != 1 || rep.TopItems[0].Name != "Ember" || rep.TopItems[0].Count != 4 {
+		t.Fatalf("unexpected top items: %+v", rep.TopItems)
+	}
+}
+
+func TestRunReplayTextAndErrors(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "ue.log")
+	if err := os.WriteFile(p, []byte(testMapStart+testBagInit+testBagMod+testMapEnd), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var code int
+	out := captureStdout(t, func() { code = run([]string{"replay", "--items", filepath.Join(dir, "none.json"), p}) })
+	if code != 0 || !strings.Contains(out, "Runs: 1") || !strings.Contains(out, "YJ_YongZhouHuiLang200") {
+		t.Fatalf("unexpected text report (code %d)\n%s", code, out)
+	}
+	captureStdout(t, func() { code = run([]string{"replay"}) })
+	if code != 2 {
+		t.Fatalf("expected 2 without inputs, got %d", code)
+	}
+	captureStdout(t, func() { code = run([]string{"replay", "--format", "xml", p}) })
+	if code != 2 {
+		t.Fatalf("expected 2 for unknown format, got %d", code)
+	}
+	captureStdout(t, func() { code = run([]string{"replay", filepath.Join(dir, "missing.log")}) })
+	if code != 1 {
+		t.Fatalf("expected 1 for missing input, got %d", code)
+	}
+}
+
+func TestRunReplayValuesDropsWithPriceHistory(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "a.log")
+	if err := os.WriteFile(logPath, []byte(testMapStart+testBagInit+testBagMod+testMapEnd), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	itemsPath := filepath.Join(dir, "items.json")
+	_ = os.WriteFile(itemsPath, []byte(`{"1001":{"name":"Ember","price":5}}`), 0o644)
+	histPath := filepath.Join(dir, "prices.jsonl")
+	at := time.Date(2025, 11, 1, 0, 0, 0, 0, time.Local)
+	if _, err := pricehistory.Open(histPath).Record(map[string]pricing.PriceUpdate{"1001": {Price: 3, LastUpdate: float64(at.Unix())}}, at, "test"); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	outPath := filepath.Join(dir, "report.json")
+
+	var code int
+	out := captureStdout(t, func() {
+		code = run([]string{"replay", "--items", itemsPath, "--price-history", histPath, "--format", "json", "--out", outPath, logPath})
+	})
+	if code != 0 {
+		t.Fatalf("expected 0, got %d\n%s", code, out)
+	}
+	b, _ := os.ReadFile(outPath)
+	var rep report.Report
+	if err := json.Unmarshal(b, &rep); err != nil {
+		t.Fatalf("parse report: %v", err)
+	}
+	if rep.Totals.Earnings != 10 || rep.Totals.AtDrop != 6 || rep.Runs[0].AtDrop != 6 {
+		t.Fatalf("expected current 10 and at-drop 6, got %+v", rep.Totals)
+	}
+}
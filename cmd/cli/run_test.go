@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -29,6 +30,23 @@ func TestRunOnceSucceeds(t *testing.T) {
 	}
 }
 
+func TestRunOnceReportsTimeUpToTheLastEvent(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "ue.log")
+	if err := os.WriteFile(p, []byte(testMapStart+testBagInit+testBagMod), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var code int
+	out := captureStdout(t, func() { code = run([]string{"--log", p, "--once", "--debug=false", "--idle", "3m"}) })
+	if code != 0 {
+		t.Fatalf("expected 0, got %d\n%s", code, out)
+	}
+	// the log ended long ago; that is not idle time of the session
+	if !strings.Contains(out, "Time: in map 1s, town 0s, idle 0s") {
+		t.Fatalf("expected activity up to the last event\n%s", out)
+	}
+}
+
 func TestRunEnvExpansion(t *testing.T) {
 	dir := t.TempDir()
 	p := filepath.Join(dir, "ue.log")
@@ -41,3 +59,72 @@ func TestRunEnvExpansion(t *testing.T) {
 		t.Fatalf("expected 0 with env-expanded path, got %d", code)
 	}
 }
+
+func TestRunOnceSaveAndLoadSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+	snap := filepath.Join(dir, "snap.json")
+	if err := os.WriteFile(first, []byte(""+
+		"[2025.11.04-19.20.45:474][302]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = /Game/Art/Maps/UI/LoginScene/LoginScene NextSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200'\n"+
+		"[2025.11.04-19.20.46:000][302]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = 5\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	// The second log continues the same map; the snapshot supplies the inventory baseline
+	if err := os.WriteFile(second, []byte("[2025.11.04-19.20.47:000][302]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = 7\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var code int
+	captureStdout(t, func() { code = run([]string{"--log", first, "--once", "--debug=false", "--save-snapshot", snap}) })
+	if code != 0 {
+		t.Fatalf("save run exit %d", code)
+	}
+	out := captureStdout(t, func() { code = run([]string{"--log", second, "--once", "--debug=false", "--load-snapshot", snap}) })
+	if code != 0 {
+		t.Fatalf("load run exit %d", code)
+	}
+	if !strings.Contains(out, "Tally: 1001=2") || !strings.Contains(out, "Status: In Map") {
+		t.Fatalf("expected restored map with +2 drop\n%s", out)
+	}
+	captureStdout(t, func() { code = run([]string{"--log", second, "--once", "--load-snapshot", filepath.Join(dir, "missing.json")}) })
+	if code != 1 {
+		t.Fatalf("expected exit 1 for missing snapshot, got %d", code)
+	}
+}
+
+func TestRunOnceReportsMetGoals(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "ue.log")
+	itemsPath := filepath.Join(dir, "items.json")
+	if err := os.WriteFile(itemsPath, []byte(`{"1001":{"name":"Ember","type":"t","price":2}}`), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(logPath, []byte(""+
+		"[2025.11.04-19.20.45:474][302]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = /Game/Art/Maps/UI/LoginScene/LoginScene NextSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200'\n"+
+		"[2025.11.04-19.20.46:000][302]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = 3\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var code int
+	out := captureStdout(t, func() {
+		code = run([]string{"--log", logPath, "--once", "--debug=false", "--items", itemsPath, "--goal", "item:Ember:3", "--goal", "earnings:10"})
+	})
+	if code != 0 {
+		t.Fatalf("exit %d\n%s", code, out)
+	}
+	if !strings.Contains(out, "Goal met: Collect 3 Ember") || strings.Contains(out, "Goal met: Earn 10") {
+		t.Fatalf("expected only the item goal met\n%s", out)
+	}
+	if !strings.Contains(out, "Goal g2: Earn 10 6/10 (60%)") {
+		t.Fatalf("expected earnings goal progress\n%s", out)
+	}
+	captureStdout(t, func() { code = run([]string{"--log", logPath, "--once", "--goal", "gold:5"}) })
+	if code != 2 {
+		t.Fatalf("expected exit 2 for an invalid goal, got %d", code)
+	}
+}
+
+func TestRunRejectsUnknownOverflow(t *testing.T) {
+	if code := run([]string{"--log", "ue.log", "--overflow", "discard"}); code != 2 {
+		t.Fatalf("expected exit code 2 for an unknown overflow policy, got %d", code)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"GoTorch/internal/items"
+	"GoTorch/internal/parser"
+	"GoTorch/internal/stats"
+	"GoTorch/internal/tracker"
+)
+
+const statsUsage = "Usage: cli stats --log <path> [--items full_table.json]"
+
+// runStats processes a log once and prints per-map profitability statistics.
+func runStats(args []string) int {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.SetOutput(os.Stdout)
+	logPath := fs.String("log", "", "Path to Torchlight Infinite log file")
+	itemsPath := fs.String("items", "", "Item table used to value drops (defaults to the app's lookup)")
+	if err := fs.Parse(args); err != nil || *logPath == "" {
+		fmt.Println(statsUsage)
+		return 2
+	}
+
+	trk := tracker.New()
+	if err := processOnce(os.ExpandEnv(*logPath), parser.New(), trk, false); err != nil {
+		fmt.Println("error:", err)
+		return 1
+	}
+	cat, err := loadCLIItems(*itemsPath)
+	if err != nil {
+		fmt.Println("warning: no item prices loaded:", err)
+	}
+	printMapStats(stats.ByMap(trk.GetState().Completed, cat.PriceOf), cat)
+	return 0
+}
+
+// printMapStats prints one row per map, then the items each map dropped with their average
+// count per run, most frequent first. cat names the items.
+func printMapStats(ms []stats.MapStats, cat *items.Catalog) {
+	if len(ms) == 0 {
+		fmt.Println("No completed maps.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Map\tRegion\tRuns\tMean\tMedian\tP90\tAvg earnings\tEarnings/hour")
+	for _, m := range ms {
+		key := m.MapKey
+		if key == "" {
+			key = "(unknown map)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%.2f\t%.1f\n",
+			key, m.Region, m.Runs,
+			m.MeanDuration.Truncate(time.Second), m.MedianDuration.Truncate(time.Second), m.P90Duration.Truncate(time.Second),
+			m.MeanEarnings, m.EarningsPerHour)
+	}
+	_ = w.Flush()
+
+	for _, m := range ms {
+		if len(m.DropRate) == 0 {
+			continue
+		}
+		key := m.MapKey
+		if key == "" {
+			key = "(unknown map)"
+		}
+		fmt.Printf("\nDrop rates for %s (per run):\n", key)
+		ids := make([]int, 0, len(m.DropRate))
+		for id := range m.DropRate {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			a, b := m.DropRate[ids[i]], m.DropRate[ids[j]]
+			if a != b {
+				return a > b
+			}
+			return ids[i] < ids[j]
+		})
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  Item\tID\tPer run")
+		for _, id := range ids {
+			name := "(unknown)"
+			if it, ok := cat.GetInt(id); ok && it.Name != "" {
+				name = it.Name
+			}
+			fmt.Fprintf(w, "  %s\t%d\t%.2f\n", name, id, m.DropRate[id])
+		}
+		_ = w.Flush()
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunStatsPrintsPerMapTable(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "ue.log")
+	data := "" +
+		"[2025.11.04-19.20.45:474][302]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = /Game/Art/Maps/UI/LoginScene/LoginScene NextSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200'\n" +
+		"[2025.11.04-19.20.46:000][302]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = 0\n" +
+		"[2025.11.04-19.20.47:000][302]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = 2\n" +
+		"[2025.11.04-19.21.45:474][302]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200' NextSceneName = World'/Game/Art/Maps/01SD/XZ_YuJinZhiXiBiNanSuo200/XZ_YuJinZhiXiBiNanSuo200.XZ_YuJinZhiXiBiNanSuo200'\n"
+	if err := os.WriteFile(logPath, []byte(data), 0o644); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+	itemsPath := filepath.Join(dir, "items.json")
+	if err := os.WriteFile(itemsPath, []byte(`{"1001":{"name":"x","price":5}}`), 0o644); err != nil {
+		t.Fatalf("write items: %v", err)
+	}
+	var code int
+	out := captureStdout(t, func() { code = run([]string{"stats", "--log", logPath, "--items", itemsPath}) })
+	if code != 0 {
+		t.Fatalf("expected 0, got %d\n%s", code, out)
+	}
+	if !strings.Contains(out, "YJ_YongZhouHuiLang200") || !strings.Contains(out, "10.00") || !strings.Contains(out, "600.0") {
+		t.Fatalf("unexpected stats output\n%s", out)
+	}
+}
+
+func TestRunStatsMissingLog(t *testing.T) {
+	var code int
+	captureStdout(t, func() { code = run([]string{"stats"}) })
+	if code != 2 {
+		t.Fatalf("expected 2, got %d", code)
+	}
+}
+
+func TestRunStatsPrintsDropRatesPerMap(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "ue.log")
+	const (
+		yj    = "World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200'"
+		hide  = "World'/Game/Art/Maps/01SD/XZ_YuJinZhiXiBiNanSuo200/XZ_YuJinZhiXiBiNanSuo200.XZ_YuJinZhiXiBiNanSuo200'"
+		login = "/Game/Art/Maps/UI/LoginScene/LoginScene"
+	)
+	scene := func(ts, from, to string) string {
+		return "[2025.11.04-" + ts + ":000][302]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = " + from + " NextSceneName = " + to + "\n"
+	}
+	bag := func(ts, kind, slot, id, num string) string {
+		return "[2025.11.04-" + ts + ":000][302]GameLog: Display: [Game] BagMgr@:" + kind + " PageId = 1 SlotId = " + slot + " ConfigBaseId = " + id + " Num = " + num + "\n"
+	}
+	data := scene("19.20.00", login, yj) +
+		bag("19.20.01", "InitBagData", "1", "1001", "0") +
+		bag("19.20.02", "Modfy BagItem", "1", "1001", "2") +
+		scene("19.21.00", yj, hide) +
+		scene("19.22.00", hide, yj) +
+		bag("19.22.01", "Modfy BagItem", "1", "1001", "3") +
+		bag("19.22.02", "Modfy BagItem", "2", "1002", "1") +
+		scene("19.23.00", yj, hide)
+	if err := os.WriteFile(logPath, []byte(data), 0o644); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+	itemsPath := filepath.Join(dir, "items.json")
+	if err := os.WriteFile(itemsPath, []byte(`{"1001":{"name":"Flame Elementium","price":5},"1002":{"name":"Ember","price":1}}`), 0o644); err != nil {
+		t.Fatalf("write items: %v", err)
+	}
+	var code int
+	out := captureStdout(t, func() { code = run([]string{"stats", "--log", logPath, "--items", itemsPath}) })
+	if code != 0 {
+		t.Fatalf("expected 0, got %d\n%s", code, out)
+	}
+	if !strings.Contains(out, "Drop rates for YJ_YongZhouHuiLang200") {
+		t.Fatalf("missing drop-rate section\n%s", out)
+	}
+	flame := strings.Index(out, "Flame Elementium  1001  1.50")
+	ember := strings.Index(out, "Ember             1002  0.50")
+	if flame < 0 || ember < 0 || ember < flame {
+		t.Fatalf("unexpected drop rates\n%s", out)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"GoTorch/internal/items"
+	"GoTorch/internal/pricehistory"
+)
+
+const historyUsage = "Usage: updateprices history series --id ID [--since YYYY-MM-DD] [--store path]\n" +
+	"       updateprices history change --id ID [--window 168h] [--store path]\n" +
+	"       updateprices history movers [--window 168h] [--top 10] [--store path]"
+
+// runHistory queries the recorded price history.
+func runHistory(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, historyUsage)
+		return 2
+	}
+	cmd, args := args[0], args[1:]
+	fs := flag.NewFlagSet("history "+cmd, flag.ContinueOnError)
+	storePath := fs.String("store", pricehistory.DefaultPath(), "Price history file")
+	file := fs.String("file", "full_table.json", "Item table used for item names")
+	id := fs.String("id", "", "Item id (ConfigBaseID)")
+	since := fs.String("since", "", "Only points on/after this date (YYYY-MM-DD)")
+	window := fs.Duration("window", 7*24*time.Hour, "Window for change and movers")
+	top := fs.Int("top", 10, "Number of movers to list (0 = all)")
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, historyUsage)
+		return 2
+	}
+	store := pricehistory.Open(*storePath)
+	names := func(string) string { return "" }
+	if cat, err := items.LoadFile(*file); err == nil {
+		names = func(id string) string {
+			it, _ := cat.Get(id)
+			return it.Name
+		}
+	}
+
+	switch cmd {
+	case "series":
+		if *id == "" {
+			fmt.Fprintln(os.Stderr, historyUsage)
+			return 2
+		}
+		var from time.Time
+		if *since != "" {
+			t, err := time.ParseInLocation("2006-01-02", *since, time.Local)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "error: --since:", err)
+				return 2
+			}
+			from = t
+		}
+		points, err := store.Series(*id, from, time.Time{})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		if len(points) == 0 {
+			fmt.Println("No price points.")
+			return 0
+		}
+		fmt.Printf("%s %s\n", *id, names(*id))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Time\tPrice\tSource")
+		for _, p := range points {
+			fmt.Fprintf(w, "%s\t%.4g\t%s\n", p.At.Local().Format("2006-01-02 15:04"), p.Price, p.Source)
+		}
+		_ = w.Flush()
+		return 0
+	case "change":
+		if *id == "" {
+			fmt.Fprintln(os.Stderr, historyUsage)
+			return 2
+		}
+		c, err := store.Change(*id, *window, time.Now())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		fmt.Printf("%s %s: %.4g -> %.4g (%+.1f%%) over %s\n", *id, names(*id), c.From, c.To, c.Percent, *window)
+		return 0
+	case "movers":
+		movers, err := store.Movers(*window, time.Now(), *top)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		if len(movers) == 0 {
+			fmt.Println("No price changes.")
+			return 0
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tName\tFrom\tTo\tChange")
+		for _, c := range movers {
+			fmt.Fprintf(w, "%s\t%s\t%.4g\t%.4g\t%+.1f%%\n", c.ItemID, names(c.ItemID), c.From, c.To, c.Percent)
+		}
+		_ = w.Flush()
+		return 0
+	}
+	fmt.Fprintln(os.Stderr, historyUsage)
+	return 2
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"GoTorch/internal/pricehistory"
+	"GoTorch/internal/pricing"
+)
+
+func TestMain(m *testing.M) {
+	// keep runs that write prices from touching the user's price history
+	dir, err := os.MkdirTemp("", "updateprices")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("GOTORCH_PRICE_HISTORY", filepath.Join(dir, "price_history.jsonl"))
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	old := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	os.Stdout = w
+	fn()
+	w.Close()
+	os.Stdout = old
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	return buf.String()
+}
+
+func TestRunHistorySubcommands(t *testing.T) {
+	dir := t.TempDir()
+	store := filepath.Join(dir, "prices.jsonl")
+	table := filepath.Join(dir, "full_table.json")
+	_ = os.WriteFile(table, []byte(`{"10":{"name":"Ember","price":1}}`), 0644)
+	s := pricehistory.Open(store)
+	now := time.Now()
+	for i, p := range []float64{2, 3} {
+		at := now.Add(time.Duration(i-2) * 24 * time.Hour)
+		if _, err := s.Record(map[string]pricing.PriceUpdate{"10": {Price: p, LastUpdate: float64(at.Unix())}}, at, "remote"); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	var code int
+	out := captureStdout(t, func() { code = run([]string{"history", "series", "--store", store, "--file", table, "--id", "10"}) })
+	if code != 0 || !strings.Contains(out, "10 Ember") || strings.Count(out, "remote") != 2 {
+		t.Fatalf("unexpected series output (code %d)\n%s", code, out)
+	}
+	out = captureStdout(t, func() { code = run([]string{"history", "change", "--store", store, "--id", "10", "--window", "72h"}) })
+	if code != 0 || !strings.Contains(out, "2 -> 3 (+50.0%)") {
+		t.Fatalf("unexpected change output (code %d)\n%s", code, out)
+	}
+	out = captureStdout(t, func() { code = run([]string{"history", "movers", "--store", store, "--file", table}) })
+	if code != 0 || !strings.Contains(out, "Ember") || !strings.Contains(out, "+50.0%") {
+		t.Fatalf("unexpected movers output (code %d)\n%s", code, out)
+	}
+	if code := run([]string{"history", "series"}); code != 2 {
+		t.Fatalf("expected exit 2 without --id, got %d", code)
+	}
+}
+
+func TestRunRecordsPriceHistory(t *testing.T) {
+	ts := priceServer(map[string]pricing.PriceUpdate{"10": {Price: 9.9, LastUpdate: 111}})
+	defer ts.Close()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "full_table.json")
+	hist := filepath.Join(dir, "prices.jsonl")
+	_ = os.WriteFile(path, []byte(`{"10":{"name":"x","price":1}}`), 0644)
+
+	args := []string{"--file", path, "--endpoint", ts.URL, "--backup=false", "--history", hist}
+	captureStdout(t, func() { run(args) })
+	captureStdout(t, func() { run(args) })
+	points, err := pricehistory.Open(hist).Series("10", time.Time{}, time.Time{})
+	if err != nil || len(points) != 1 || points[0].Price != 9.9 {
+		t.Fatalf("expected one recorded point, got %+v, %v", points, err)
+	}
+}
+
+func priceServer(prices map[string]pricing.PriceUpdate) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(prices)
+	}))
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -11,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	"GoTorch/internal/items"
+	"GoTorch/internal/pricehistory"
 	"GoTorch/internal/pricing"
 )
 
@@ -19,12 +20,23 @@ func main() {
 }
 
 func run(args []string) int {
+	if len(args) > 0 && args[0] == "history" {
+		return runHistory(args[1:])
+	}
 	fs := flag.NewFlagSet("updateprices", flag.ContinueOnError)
 	file := fs.String("file", "full_table.json", "Path to the item table JSON file to update")
 	endpoint := fs.String("endpoint", "", "Pricing endpoint URL (defaults to built-in)")
 	dryRun := fs.Bool("dry-run", false, "Do not write changes, only report what would change")
 	backup := fs.Bool("backup", true, "Create a .bak backup before writing")
 	timeout := fs.Duration("timeout", 8*time.Second, "HTTP timeout for the pricing request")
+	sources := fs.String("sources", "remote", "Comma-separated price sources in priority order: remote, local, overrides, embedded")
+	policy := fs.String("policy", "first-wins", "How prices from several sources are merged: first-wins, freshest or median")
+	localPath := fs.String("local", "", "Local price file for the local source (e.g. price.json)")
+	namesPath := fs.String("names", "", "Id table mapping names in --local to ids (e.g. en_id_table.json)")
+	overridesPath := fs.String("overrides", "", "Price overrides file keyed by item id for the overrides source")
+	cachePath := fs.String("cache", "", "Remote price cache file for conditional requests and offline use")
+	offline := fs.Bool("offline", false, "Never hit the network; serve remote prices from --cache")
+	historyPath := fs.String("history", pricehistory.DefaultPath(), "Price history file every fetched price is appended to (empty to disable)")
 	if err := fs.Parse(args); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		usage(fs)
@@ -38,37 +50,72 @@ func run(args []string) int {
 		return 2
 	}
 
-	// Load existing JSON as a generic map to preserve unknown fields
-	existing, err := loadJSON(path)
+	// Load the item catalog; unknown fields are preserved when it is written back
+	cat, err := items.LoadFile(path)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "failed to read file:", err)
 		return 1
 	}
 
-	// Fetch remote prices
+	pol, err := pricing.ParsePolicy(*policy)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	prov, err := pricing.Config{
+		Sources:       pricing.ParseSources(*sources),
+		Policy:        pol,
+		Endpoint:      *endpoint,
+		CachePath:     *cachePath,
+		Offline:       *offline,
+		LocalPath:     *localPath,
+		NamesPath:     *namesPath,
+		OverridesPath: *overridesPath,
+		Embedded:      items.EmbeddedPrices(),
+		OnError: func(provider string, err error) {
+			fmt.Fprintln(os.Stderr, "warning: price source failed:", err)
+		},
+	}.Build()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	// Fetch prices
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
-	updates, err := pricing.FetchRemotePrices(ctx, *endpoint)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "failed to fetch remote pricing:", err)
+	updates, err := prov.Prices(ctx)
+	if errors.Is(err, pricing.ErrStale) {
+		fmt.Fprintln(os.Stderr, "warning: no fresh prices, using cached ones:", err)
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to fetch pricing:", err)
 		return 1
 	}
 
 	// Merge updates
-	changed, total := mergePrices(existing, updates)
-	fmt.Printf("Remote items: %d, Updated entries: %d\n", total, changed)
+	changed, total := cat.ApplyPrices(updates)
+	fmt.Printf("Priced items (%s): %d, Updated entries: %d\n", prov.Name(), total, changed)
 
 	if *dryRun {
 		fmt.Println("Dry-run: no changes written.")
 		return 0
 	}
 
+	if *historyPath != "" {
+		added, err := pricehistory.Open(*historyPath).Record(updates, time.Now(), prov.Name())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "warning: could not record price history:", err)
+		} else {
+			fmt.Printf("Price history: %d new points\n", added)
+		}
+	}
+
 	if *backup {
 		if err := writeBackup(path); err != nil {
 			fmt.Fprintln(os.Stderr, "warning: could not create backup:", err)
 		}
 	}
-	if err := writeJSON(path, existing); err != nil {
+	if err := cat.WriteFile(path); err != nil {
 		fmt.Fprintln(os.Stderr, "failed to write file:", err)
 		return 1
 	}
@@ -77,29 +124,7 @@ func run(args []string) int {
 }
 
 func usage(fs *flag.FlagSet) {
-	fmt.Fprintf(os.Stderr, "Usage: %s [--file full_table.json] [--endpoint URL] [--dry-run] [--backup=true] [--timeout 8s]\n", fs.Name())
-}
-
-// loadJSON reads the file path into a generic nested map.
-func loadJSON(path string) (map[string]map[string]interface{}, error) {
-	b, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	var m map[string]map[string]interface{}
-	if err := json.Unmarshal(b, &m); err != nil {
-		return nil, err
-	}
-	return m, nil
-}
-
-// writeJSON writes the map back to disk with indentation.
-func writeJSON(path string, m map[string]map[string]interface{}) error {
-	b, err := json.MarshalIndent(m, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(path, b, 0644)
+	fmt.Fprintf(os.Stderr, "Usage: %s [--file full_table.json] [--endpoint URL] [--sources remote,local,overrides,embedded] [--policy first-wins|freshest|median] [--local price.json --names en_id_table.json] [--overrides file] [--cache file] [--offline] [--history file] [--dry-run] [--backup=true] [--timeout 8s]\n", fs.Name())
 }
 
 func writeBackup(path string) error {
@@ -124,42 +149,3 @@ func writeBackup(path string) error {
 	}
 	return os.WriteFile(bak, data, 0644)
 }
-
-// mergePrices applies Price and LastUpdate from updates into existing map, only for existing ids.
-func mergePrices(existing map[string]map[string]interface{}, updates map[string]pricing.PriceUpdate) (changed int, total int) {
-	for id, u := range updates {
-		total++
-		if row, ok := existing[id]; ok {
-			var prevPrice, prevLU interface{}
-			if v, ok := row["price"]; ok {
-				prevPrice = v
-			}
-			if v, ok := row["last_update"]; ok {
-				prevLU = v
-			}
-			// Always set price and last_update
-			row["price"] = u.Price
-			row["last_update"] = u.LastUpdate
-			// detect changes conservatively
-			if !equalJSONNumber(prevPrice, u.Price) || !equalJSONNumber(prevLU, u.LastUpdate) {
-				changed++
-			}
-		}
-	}
-	return changed, total
-}
-
-func equalJSONNumber(prev interface{}, now float64) bool {
-	switch v := prev.(type) {
-	case float64:
-		return v == now
-	case json.Number:
-		f, err := v.Float64()
-		if err == nil {
-			return f == now
-		}
-		return false
-	default:
-		return false
-	}
-}
@@ -8,77 +8,39 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"GoTorch/internal/items"
 	"GoTorch/internal/pricing"
 )
 
-func TestEqualJSONNumber(t *testing.T) {
-	cases := []struct {
-		prev interface{}
-		now  float64
-		ok   bool
-	}{
-		{float64(1.0), 1.0, true},
-		{json.Number("1"), 1.0, true},
-		{json.Number("1.5"), 1.5, true},
-		{json.Number("bad"), 0.0, false},
-		{"1", 1.0, false},
-		{nil, 0.0, false},
-	}
-	for i, c := range cases {
-		if got := equalJSONNumber(c.prev, c.now); got != c.ok {
-			t.Fatalf("case %d: equalJSONNumber(%T,%v)=%v want %v", i, c.prev, c.now, got, c.ok)
-		}
-	}
-}
-
-func TestMergePrices(t *testing.T) {
-	existing := map[string]map[string]interface{}{
-		"100": {"name": "foo", "price": json.Number("1"), "last_update": json.Number("1000")},
-		"200": {"name": "bar", "price": 2.0, "last_update": 2000.0},
-	}
-	updates := map[string]pricing.PriceUpdate{
-		"100": {Price: 1.5, LastUpdate: 1500}, // change both
-		"300": {Price: 3.0, LastUpdate: 3000}, // not present in existing
-	}
-	changed, total := mergePrices(existing, updates)
-	if total != 2 {
-		t.Fatalf("total=%d want 2", total)
-	}
-	if changed != 1 {
-		t.Fatalf("changed=%d want 1", changed)
-	}
-	if existing["100"]["price"].(float64) != 1.5 || existing["100"]["last_update"].(float64) != 1500 {
-		t.Fatalf("row 100 not updated: %#v", existing["100"])
-	}
-	if _, ok := existing["300"]; ok {
-		t.Fatalf("unexpected creation of new id 300 in existing")
-	}
-}
-
-func TestLoadWriteJSONAndBackup(t *testing.T) {
+func TestCatalogWriteAndBackup(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "table.json")
 	data := map[string]map[string]interface{}{
-		"1": {"name": "a", "price": 1.0, "last_update": 1.0},
+		"1": {"name": "a", "type": "t", "price": 1.0, "last_update": 1.0, "from": "x", "last_time": 42.0},
 	}
 	b, _ := json.Marshal(data)
 	if err := os.WriteFile(path, b, 0644); err != nil {
 		t.Fatalf("write: %v", err)
 	}
-	loaded, err := loadJSON(path)
+	cat, err := items.LoadFile(path)
 	if err != nil {
-		t.Fatalf("loadJSON: %v", err)
-	}
-	if !reflect.DeepEqual(loaded, data) {
-		t.Fatalf("loaded != data: %#v vs %#v", loaded, data)
+		t.Fatalf("LoadFile: %v", err)
 	}
 	// mutate and write
-	loaded["1"]["price"] = 2.0
-	if err := writeJSON(path, loaded); err != nil {
-		t.Fatalf("writeJSON: %v", err)
+	cat.ApplyPrices(map[string]pricing.PriceUpdate{"1": {Price: 2.0, LastUpdate: 1.0}})
+	if err := cat.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	b2, _ := os.ReadFile(path)
+	var after map[string]map[string]interface{}
+	_ = json.Unmarshal(b2, &after)
+	data["1"]["price"] = 2.0
+	if !reflect.DeepEqual(after, data) {
+		t.Fatalf("written != expected (unknown fields must survive): %#v vs %#v", after, data)
 	}
 	// write backup
 	if err := writeBackup(path); err != nil {
@@ -147,3 +109,127 @@ func TestPricingWithTimeoutSmoke(t *testing.T) {
 		// still alive is also fine; just smoke test
 	}
 }
+
+func TestRunOverridesBeatRemote(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]pricing.PriceUpdate{"10": {Price: 9.9, LastUpdate: 111}, "20": {Price: 2, LastUpdate: 111}})
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "full_table.json")
+	overrides := filepath.Join(dir, "overrides.json")
+	_ = os.WriteFile(path, []byte(`{"10":{"name":"x","price":1},"20":{"name":"y","price":1}}`), 0644)
+	_ = os.WriteFile(overrides, []byte(`{"10":{"price":5,"last_update":222}}`), 0644)
+
+	code := run([]string{"--file", path, "--endpoint", ts.URL, "--sources", "overrides,remote", "--overrides", overrides, "--backup=false"})
+	if code != 0 {
+		t.Fatalf("exit code=%d", code)
+	}
+	cat, err := items.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if cat.PriceOf(10) != 5 || cat.PriceOf(20) != 2 {
+		t.Fatalf("unexpected prices: 10=%v 20=%v", cat.PriceOf(10), cat.PriceOf(20))
+	}
+	if code := run([]string{"--file", path, "--policy", "mean"}); code != 2 {
+		t.Fatalf("expected exit 2 for unknown policy, got %d", code)
+	}
+}
+
+func TestRunMergesProviderPricesIntoExistingItems(t *testing.T) {
+	ts := priceServer(map[string]pricing.PriceUpdate{
+		"100": {Price: 1.5, LastUpdate: 1500}, // changed
+		"200": {Price: 2, LastUpdate: 2000},   // same as the table
+		"300": {Price: 3, LastUpdate: 3000},   // not in the table
+	})
+	defer ts.Close()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "full_table.json")
+	_ = os.WriteFile(path, []byte(`{"100":{"name":"foo","price":1,"last_update":1000},"200":{"name":"bar","price":2,"last_update":2000}}`), 0644)
+
+	var code int
+	out := captureStdout(t, func() { code = run([]string{"--file", path, "--endpoint", ts.URL, "--backup=false", "--history", ""}) })
+	if code != 0 {
+		t.Fatalf("exit code=%d\n%s", code, out)
+	}
+	if !strings.Contains(out, "Priced items (remote): 3, Updated entries: 1") {
+		t.Fatalf("unexpected merge counts\n%s", out)
+	}
+	b, _ := os.ReadFile(path)
+	var after map[string]map[string]interface{}
+	_ = json.Unmarshal(b, &after)
+	if after["100"]["price"] != 1.5 || after["100"]["last_update"] != 1500.0 || after["100"]["name"] != "foo" {
+		t.Fatalf("row 100 not updated: %#v", after["100"])
+	}
+	if after["200"]["price"] != 2.0 || after["200"]["last_update"] != 2000.0 {
+		t.Fatalf("row 200 changed: %#v", after["200"])
+	}
+	if _, ok := after["300"]; ok {
+		t.Fatalf("unexpected creation of new id 300: %#v", after)
+	}
+}
+
+func TestRunMergePolicies(t *testing.T) {
+	ts := priceServer(map[string]pricing.PriceUpdate{"10": {Price: 9, LastUpdate: 111}})
+	defer ts.Close()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "full_table.json")
+	overrides := filepath.Join(dir, "overrides.json")
+	_ = os.WriteFile(overrides, []byte(`{"10":{"price":5,"last_update":50}}`), 0644)
+
+	cases := []struct {
+		policy  string
+		sources string
+		want    float64
+	}{
+		{"first-wins", "overrides,remote", 5},
+		{"freshest", "overrides,remote", 9},
+		{"median", "overrides,remote", 7},
+		{"first-wins", "remote,overrides", 9},
+	}
+	for _, c := range cases {
+		_ = os.WriteFile(path, []byte(`{"10":{"name":"x","price":1}}`), 0644)
+		args := []string{"--file", path, "--endpoint", ts.URL, "--sources", c.sources, "--overrides", overrides, "--policy", c.policy, "--backup=false", "--history", ""}
+		var code int
+		out := captureStdout(t, func() { code = run(args) })
+		if code != 0 {
+			t.Fatalf("%s/%s: exit code=%d\n%s", c.policy, c.sources, code, out)
+		}
+		cat, err := items.LoadFile(path)
+		if err != nil {
+			t.Fatalf("LoadFile: %v", err)
+		}
+		if got := cat.PriceOf(10); got != c.want {
+			t.Fatalf("%s/%s: price = %v want %v", c.policy, c.sources, got, c.want)
+		}
+	}
+}
+
+func TestRunKeepsOtherSourcesWhenOneFails(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "full_table.json")
+	overrides := filepath.Join(dir, "overrides.json")
+	_ = os.WriteFile(path, []byte(`{"10":{"name":"x","price":1},"20":{"name":"y","price":1}}`), 0644)
+	_ = os.WriteFile(overrides, []byte(`{"20":{"price":4,"last_update":1}}`), 0644)
+
+	var code int
+	out := captureStdout(t, func() {
+		code = run([]string{"--file", path, "--endpoint", ts.URL, "--sources", "remote,overrides", "--overrides", overrides, "--backup=false", "--history", ""})
+	})
+	if code != 0 {
+		t.Fatalf("exit code=%d\n%s", code, out)
+	}
+	cat, err := items.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if cat.PriceOf(10) != 1 || cat.PriceOf(20) != 4 {
+		t.Fatalf("unexpected prices: 10=%v 20=%v", cat.PriceOf(10), cat.PriceOf(20))
+	}
+}
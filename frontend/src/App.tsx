@@ -4,12 +4,17 @@ import WelcomeSection from './components/WelcomeSection'
 import StatsPanel from './components/StatsPanel'
 import TallyTable from './components/TallyTable'
 import RecentEvents from './components/RecentEvents'
+import GoalsPanel from './components/GoalsPanel'
+import DiagnosticsPanel from './components/DiagnosticsPanel'
 import { hasRuntime, getBackend } from './lib/runtime'
-import type { UIState } from './types/ui'
+import { playAlertSound } from './lib/sound'
+import type { UIDiagnostics, UIDropAlert, UIGoal, UIState, UITailerStats } from './types/ui'
 
 export default function App() {
   const [uid, setUid] = useState('')
   const [state, setUiState] = useState<UIState | null>(null)
+  const [diagnostics, setDiagnostics] = useState<UIDiagnostics | null>(null)
+  const [tailerStats, setTailerStats] = useState<UITailerStats | null>(null)
   const [logPath, setLogPath] = useState('')
   const [readFromStart, setReadFromStart] = useState<boolean>(() => {
     try {
@@ -24,9 +29,36 @@ export default function App() {
     if (!hasRuntime()) return
     const rt = (window as any).runtime
     const off = rt.EventsOn('state', (s: UIState) => setUiState(s))
+    const offGoal = rt.EventsOn('goal-met', (g: UIGoal) => {
+      try { new Notification('Goal met', { body: g.label }) } catch {}
+    })
+    const offAlert = rt.EventsOn('drop-alert', (a: UIDropAlert) => {
+      if (a.sound) playAlertSound()
+      if (a.notify) {
+        try { new Notification(a.rule ? 'Drop alert: ' + a.rule : 'Drop alert', { body: a.text }) } catch {}
+      }
+    })
     return () => {
       try { off() } catch {}
+      try { offGoal() } catch {}
+      try { offAlert() } catch {}
+    }
+  }, [])
+
+  useEffect(() => {
+    if (!hasRuntime()) return
+    const poll = async () => {
+      try {
+        const backend = getBackend()
+        const d = await backend?.Diagnostics?.()
+        if (d) setDiagnostics(d)
+        const s = await backend?.TailerStats?.()
+        if (s) setTailerStats(s)
+      } catch {}
     }
+    poll()
+    const id = setInterval(poll, 2000)
+    return () => clearInterval(id)
   }, [])
 
   const selectLogFile = async () => {
@@ -56,7 +88,11 @@ export default function App() {
       }
       // Persist preference
       try { localStorage.setItem('readFromStart', readFromStart ? '1' : '0') } catch {}
-      if (backend.StartTrackingWithOptions) {
+      // several files or globs are separated by ';'
+      const paths = path.split(';').map(p => p.trim()).filter(Boolean)
+      if (paths.length > 1 && backend.StartTrackingPaths) {
+        await backend.StartTrackingPaths(paths, readFromStart)
+      } else if (backend.StartTrackingWithOptions) {
         await backend.StartTrackingWithOptions(path, readFromStart)
       } else if (backend.StartTracking) {
         await backend.StartTracking(path)
@@ -79,9 +115,38 @@ export default function App() {
     }
   }
 
+  const togglePause = async () => {
+    if (!hasRuntime()) return
+    try {
+      const backend = getBackend()
+      if (state?.paused) await backend?.Resume?.()
+      else await backend?.Pause?.()
+    } catch (e) {
+      console.error(e)
+    }
+  }
+
+  const addGoal = async (spec: string) => {
+    if (!hasRuntime()) return
+    try {
+      await getBackend()?.AddGoal?.(spec)
+    } catch (e) {
+      alert('Invalid goal: ' + e)
+    }
+  }
+
+  const removeGoal = async (id: string) => {
+    if (!hasRuntime()) return
+    try {
+      await getBackend()?.RemoveGoal?.(id)
+    } catch (e) {
+      console.error(e)
+    }
+  }
+
   return (
     <div style={{ fontFamily: 'Inter, system-ui, Arial', padding: 24, color: '#e2e8f0', background: '#0f172a', minHeight: '100vh' }}>
-      <HeaderBar onStart={startTracking} onReset={reset} />
+      <HeaderBar onStart={startTracking} onReset={reset} paused={state?.paused} onTogglePause={togglePause} />
 
       <WelcomeSection
         uid={uid}
@@ -96,10 +161,14 @@ export default function App() {
 
       <StatsPanel state={state} />
 
+      <GoalsPanel goals={state?.goals} onAdd={addGoal} onRemove={removeGoal} />
+
       <section style={{ display: 'grid', gridTemplateColumns: '1fr 1fr', gap: 16 }}>
         <TallyTable tally={state?.tally} />
         <RecentEvents events={state?.recent} />
       </section>
+
+      <DiagnosticsPanel diagnostics={diagnostics} stats={tailerStats} />
     </div>
   )
 }
@@ -0,0 +1,65 @@
+import React from 'react'
+import { card, h2 } from '../uiStyles'
+import { UIDiagnostics, UITailerStats } from '../types/ui'
+
+export type DiagnosticsPanelProps = {
+  diagnostics?: UIDiagnostics | null
+  stats?: UITailerStats | null
+}
+
+const healthLabel: Record<UITailerStats['health'], string> = {
+  waiting: 'Waiting for the log file',
+  following: 'Following',
+  rotated: 'Log rotated, no new data yet',
+  errored: 'Error',
+}
+
+function ago(ms: number) {
+  if (!ms) return 'never'
+  const s = Math.max(0, Math.round((Date.now() - ms) / 1000))
+  return s < 60 ? `${s}s ago` : `${Math.floor(s / 60)}m ${s % 60}s ago`
+}
+
+export default function DiagnosticsPanel({ diagnostics: d, stats: s }: DiagnosticsPanelProps) {
+  if (!d && !s) return null
+  const rows: [string, string | number][] = []
+  if (s) {
+    rows.push(
+      ['Log', s.tailing ? healthLabel[s.health] : 'Stopped'],
+      ['Position', `${s.offset} / ${s.size} bytes`],
+      ['Last data', ago(s.lastDataAt)],
+      ['Rotations', s.rotations],
+    )
+  }
+  if (d) {
+    rows.push(
+      ['Status', d.tailing ? (d.watching ? 'Tailing (watching)' : 'Tailing (polling)') : 'Stopped'],
+      ['Overflow', d.overflow],
+      ['Lines read', d.linesRead],
+      ['Bytes read', d.bytesRead],
+      ['Lines sent', d.linesSent],
+      ['Lines dropped', d.linesDropped],
+      ['Lines spilled', d.linesSpilled],
+      ['Queued', d.queued],
+    )
+  }
+  return (
+    <div style={card}>
+      <h2 style={h2}>Log Tailer</h2>
+      <div style={{ display: 'grid', gridTemplateColumns: 'repeat(4, 1fr)', gap: 8 }}>
+        {rows.map(([label, value]) => (
+          <div key={label}>
+            <div style={{ opacity: 0.7, fontSize: 12 }}>{label}</div>
+            <div style={{ color: label === 'Lines dropped' && d && d.linesDropped > 0 ? '#f87171' : undefined }}>{value}</div>
+          </div>
+        ))}
+      </div>
+      {s?.sources?.map(src => (
+        <div key={src.path} style={{ marginTop: 8, fontSize: 12, opacity: 0.85 }}>
+          {src.path}: {healthLabel[src.health]}, {src.offset} / {src.size} bytes, last data {ago(src.lastDataAt)}
+        </div>
+      ))}
+      {s?.error && <div style={{ color: '#f87171', marginTop: 8 }}>{s.error}</div>}
+    </div>
+  )
+}
@@ -0,0 +1,44 @@
+import React, { useState } from 'react'
+import { btnStyle, card, h2, inputStyle } from '../uiStyles'
+import { UIGoal } from '../types/ui'
+
+export type GoalsPanelProps = {
+  goals?: UIGoal[]
+  onAdd: (spec: string) => void
+  onRemove: (id: string) => void
+}
+
+export default function GoalsPanel({ goals, onAdd, onRemove }: GoalsPanelProps) {
+  const [spec, setSpec] = useState('')
+  const add = () => {
+    if (!spec.trim()) return
+    onAdd(spec.trim())
+    setSpec('')
+  }
+  return (
+    <div style={card}>
+      <h2 style={h2}>Goals</h2>
+      <div style={{ marginBottom: 8 }}>
+        <input
+          value={spec}
+          onChange={(e) => setSpec(e.target.value)}
+          placeholder="earnings:500, item:<id or name>:20 or maps:30"
+          style={inputStyle}
+        />
+        <button onClick={add} style={{ ...btnStyle, marginLeft: 8 }}>Add</button>
+      </div>
+      {goals?.length ? (
+        goals.map((g) => (
+          <div key={g.id} style={{ padding: '4px 0', borderBottom: '1px solid #1e293b' }}>
+            <span style={{ marginRight: 8 }}>{g.done ? '✓' : `${g.percent.toFixed(0)}%`}</span>
+            <span>{g.label}</span>
+            <span style={{ opacity: 0.7, marginLeft: 8 }}>{g.current.toFixed(0)}/{g.target}</span>
+            <button onClick={() => onRemove(g.id)} style={{ ...btnStyle, marginLeft: 8, padding: '2px 8px' }}>×</button>
+          </div>
+        ))
+      ) : (
+        <div style={{ opacity: 0.7 }}>No goals set.</div>
+      )}
+    </div>
+  )
+}
@@ -20,6 +20,10 @@ export default function StatsPanel({ state }: StatsPanelProps) {
 
   const eph = useMemo(() => fmtMoney(state?.earningsPerHour, 1), [state])
   const eps = useMemo(() => fmtMoney(state?.earningsPerSession, 2), [state])
+  const ephAtDrop = useMemo(() => fmtMoney(state?.earningsPerHourAtDrop, 1), [state])
+  const netEph = useMemo(() => fmtMoney(state?.netEarningsPerHour, 1), [state])
+  const activeEph = useMemo(() => fmtMoney(state?.activeEarningsPerHour, 1), [state])
+  const cost = useMemo(() => fmtMoney(state?.costPerSession, 2), [state])
   const avgMapDur = useMemo(() => {
     const ms = state?.avgMapTimeMs || 0
     if (!ms) return '—'
@@ -40,7 +44,12 @@ export default function StatsPanel({ state }: StatsPanelProps) {
         <Stat label="Map Duration" value={mapDur} />
         <Stat label="Earnings/hour" value={eph} />
         <Stat label="Earnings/session" value={eps} />
+        <Stat label="Earnings/hour (at drop)" value={ephAtDrop} />
+        <Stat label="Net earnings/hour" value={netEph} />
+        <Stat label="Earnings/hour (active)" value={activeEph} />
+        <Stat label="Cost/session" value={cost} />
         <Stat label="Avg time/map" value={avgMapDur} />
+        <Stat label="Prices" value={state?.priceStatus ?? 'unknown'} />
       </div>
 
       <div style={{ marginTop: 12 }}>
@@ -50,6 +59,7 @@ export default function StatsPanel({ state }: StatsPanelProps) {
             <thead>
               <tr>
                 <th style={th}>Map #</th>
+                <th style={th}>Map</th>
                 <th style={th}>Earnings</th>
                 <th style={th}>Duration</th>
               </tr>
@@ -61,6 +71,7 @@ export default function StatsPanel({ state }: StatsPanelProps) {
                 return (
                   <tr key={idx}>
                     <td style={td}>{i}</td>
+                    <td style={td}>{m.mapKey || '—'}</td>
                     <td style={td}>{fmtMoney(m.earnings)}</td>
                     <td style={td}>{dur}</td>
                   </tr>
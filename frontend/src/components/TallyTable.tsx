@@ -31,7 +31,9 @@ export default function TallyTable({ tally }: TallyTableProps) {
                 <tr key={id}>
                   <td style={td}>{item.name}</td>
                   <td style={td}>{item.count}</td>
-                  <td style={td}>{total.toFixed(2)}</td>
+                  <td style={td} title={`price ${item.priceStatus ?? 'unknown'}`}>
+                    {total.toFixed(2)}{item.priceStatus === 'stale' ? ' (stale)' : ''}
+                  </td>
                 </tr>
               )
             })}
@@ -20,7 +20,7 @@ export default function WelcomeSection(props: WelcomeSectionProps) {
       <div style={{ display: 'flex', gap: 12, alignItems: 'center', flexWrap: 'wrap' }}>
         <label>Log Path:</label>
         <button onClick={() => selectLogFile()}>Select log file</button>
-        <input value={logPath} onChange={e => onLogPathChange(e.target.value)} placeholder="Select or paste UE_game.log path" style={inputStyle} />
+        <input value={logPath} onChange={e => onLogPathChange(e.target.value)} placeholder="Select or paste UE_game.log path (several separated by ;)" style={inputStyle} />
         <label style={{ display: 'flex', alignItems: 'center', gap: 6 }}>
           <input type="checkbox" checked={readFromStart} onChange={e => onToggleReadFromStart(e.target.checked)} />
           <span>Read old lines (dev)</span>
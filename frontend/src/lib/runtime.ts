@@ -1,8 +1,25 @@
+import type { UIDiagnostics, UIGoal, UIHistoryRecord, UIMapStats, UIPriceChange, UIPricePoint, UITailerStats } from '../types/ui'
+
 export const hasRuntime = () => typeof (window as any).runtime !== 'undefined'
 
 export const getBackend = () => (window as any).go?.app?.App as undefined | {
   SelectLogFile: () => Promise<string>
   StartTrackingWithOptions?: (path: string, fromStart: boolean) => Promise<void>
   StartTracking?: (path: string) => Promise<void>
+  StartTrackingPaths?: (paths: string[], fromStart: boolean) => Promise<void>
   Reset: () => Promise<void>
+  Pause?: () => Promise<boolean>
+  Resume?: () => Promise<boolean>
+  AddGoal?: (spec: string) => Promise<UIGoal>
+  RemoveGoal?: (id: string) => Promise<boolean>
+  MapStats?: () => Promise<UIMapStats[]>
+  ListHistory?: () => Promise<UIHistoryRecord[]>
+  QueryHistory?: (mapKey: string, sinceMs: number, untilMs: number) => Promise<UIHistoryRecord[]>
+  GetHistory?: (id: string) => Promise<UIHistoryRecord>
+  DeleteHistory?: (id: string) => Promise<void>
+  PriceSeries?: (id: string, sinceMs: number) => Promise<UIPricePoint[]>
+  PriceChange?: (id: string, windowHours: number) => Promise<UIPriceChange>
+  PriceMovers?: (windowHours: number, top: number) => Promise<UIPriceChange[]>
+  Diagnostics?: () => Promise<UIDiagnostics>
+  TailerStats?: () => Promise<UITailerStats>
 }
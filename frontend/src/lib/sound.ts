@@ -0,0 +1,19 @@
+// playAlertSound plays a short two-tone chime; it does nothing where Web Audio is unavailable.
+export function playAlertSound() {
+  try {
+    const Ctx = (window as any).AudioContext || (window as any).webkitAudioContext
+    if (!Ctx) return
+    const ctx = new Ctx()
+    const gain = ctx.createGain()
+    gain.gain.value = 0.15
+    gain.connect(ctx.destination)
+    ;[880, 1320].forEach((freq, i) => {
+      const osc = ctx.createOscillator()
+      osc.frequency.value = freq
+      osc.connect(gain)
+      osc.start(ctx.currentTime + i * 0.15)
+      osc.stop(ctx.currentTime + i * 0.15 + 0.12)
+    })
+    setTimeout(() => ctx.close(), 500)
+  } catch {}
+}
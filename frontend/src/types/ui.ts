@@ -1,5 +1,7 @@
 export type UIEvent = { time: number; kind: string }
 
+export type PriceStatus = 'fresh' | 'stale' | 'unknown'
+
 export type UITallyItem = {
   name: string
   type: string
@@ -7,6 +9,7 @@ export type UITallyItem = {
   last_update: number
   from: string
   count: number
+  priceStatus: PriceStatus
 }
 
 export type UIMap = {
@@ -14,6 +17,58 @@ export type UIMap = {
   end: number
   durationMs: number
   earnings: number
+  earningsAtDrop: number
+  cost: number
+  costAtDrop: number
+  net: number
+  mapKey: string
+  region: string
+}
+
+export type UIMapStats = {
+  mapKey: string
+  region: string
+  runs: number
+  meanDurationMs: number
+  medianDurationMs: number
+  p90DurationMs: number
+  meanEarnings: number
+  earningsPerHour: number
+  dropRate: Record<string, number>
+  meanEarningsAtDrop: number
+  earningsPerHourAtDrop: number
+  meanCost: number
+  netEarningsPerHour: number
+}
+
+export type UIHistoryRecord = {
+  id: string
+  sessionStart: number
+  mapKey: string
+  region: string
+  start: number
+  end: number
+  durationMs: number
+  earnings: number
+  cost: number
+  tally: Record<string, number>
+  prices: Record<string, number>
+}
+
+export type UIPricePoint = {
+  time: number
+  price: number
+  source: string
+}
+
+export type UIPriceChange = {
+  itemId: string
+  name: string
+  from: number
+  to: number
+  fromTime: number
+  toTime: number
+  percent: number
 }
 
 export type UIState = {
@@ -29,4 +84,77 @@ export type UIState = {
   earningsPerSession: number
   earningsPerHour: number
   avgMapTimeMs: number
+  priceStatus: PriceStatus
+  earningsPerSessionAtDrop: number
+  earningsPerHourAtDrop: number
+  costPerSession: number
+  netPerSession: number
+  netEarningsPerHour: number
+  netEarningsPerHourAtDrop: number
+  inMapTimeMs: number
+  townTimeMs: number
+  idleTimeMs: number
+  activeEarningsPerHour: number
+  activeNetEarningsPerHour: number
+  paused: boolean
+  pausedTimeMs: number
+  pauses: UIInterval[]
+  goals: UIGoal[]
+}
+
+export type UIGoal = {
+  id: string
+  kind: 'earnings' | 'item' | 'maps'
+  itemId?: string
+  label: string
+  target: number
+  current: number
+  percent: number
+  done: boolean
+  doneAt: number
+}
+
+export type UIDropAlert = {
+  rule: string
+  itemId: string
+  name: string
+  type: string
+  count: number
+  unit: number
+  total: number
+  at: number
+  mapKey: string
+  text: string
+  sound: boolean
+  notify: boolean
+}
+
+export type UIInterval = {
+  start: number
+  end: number
+}
+
+export type UIDiagnostics = {
+  tailing: boolean
+  watching: boolean
+  overflow: string
+  linesRead: number
+  bytesRead: number
+  linesSent: number
+  linesDropped: number
+  linesSpilled: number
+  queued: number
+}
+
+export type UITailerStats = {
+  tailing: boolean
+  path: string
+  health: 'waiting' | 'following' | 'rotated' | 'errored'
+  error?: string
+  waiting: boolean
+  offset: number
+  size: number
+  lastDataAt: number
+  rotations: number
+  sources?: UITailerStats[]
 }
@@ -0,0 +1,186 @@
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"GoTorch/internal/items"
+	"GoTorch/internal/tracker"
+)
+
+// ErrInvalid is returned for rules that cannot match anything.
+var ErrInvalid = errors.New("alerts: invalid rule")
+
+// Rule picks out drops worth an alert. Every criterion that is set must hold; a rule needs at
+// least one. Values are in item table price units.
+type Rule struct {
+	Name          string   `json:"name"`
+	ItemIDs       []int    `json:"item_ids,omitempty"`        // any of these items
+	Types         []string `json:"types,omitempty"`           // any of these item types (case-insensitive)
+	MinUnitValue  float64  `json:"min_unit_value,omitempty"`  // unit price at least this
+	MinTotalValue float64  `json:"min_total_value,omitempty"` // unit price × count at least this
+	// CooldownSeconds is the minimum time between two alerts of this rule, in log time.
+	CooldownSeconds float64 `json:"cooldown_seconds,omitempty"`
+	Sound           bool    `json:"sound,omitempty"`   // ask the UI to play a sound
+	Notify          bool    `json:"notify,omitempty"`  // ask the UI to show an OS notification
+	Webhook         string  `json:"webhook,omitempty"` // URL the alert is POSTed to as a drop_alert webhook event
+}
+
+// Validate reports whether r has a criterion and a usable webhook URL.
+func (r Rule) Validate() error {
+	if len(r.ItemIDs) == 0 && len(r.Types) == 0 && r.MinUnitValue <= 0 && r.MinTotalValue <= 0 {
+		return fmt.Errorf("%w %q: needs item_ids, types, min_unit_value or min_total_value", ErrInvalid, r.Name)
+	}
+	if r.CooldownSeconds < 0 {
+		return fmt.Errorf("%w %q: negative cooldown", ErrInvalid, r.Name)
+	}
+	if r.Webhook != "" && !strings.HasPrefix(r.Webhook, "http://") && !strings.HasPrefix(r.Webhook, "https://") {
+		return fmt.Errorf("%w %q: webhook must be an http(s) URL", ErrInvalid, r.Name)
+	}
+	return nil
+}
+
+// Alert is a drop matched by a rule.
+type Alert struct {
+	Rule    string    `json:"rule"`
+	ItemID  int       `json:"item_id"`
+	Name    string    `json:"name"`
+	Type    string    `json:"type"`
+	Count   int       `json:"count"`
+	Unit    float64   `json:"unit"`
+	Total   float64   `json:"total"`
+	At      time.Time `json:"at"`
+	MapKey  string    `json:"map_key"`
+	Text    string    `json:"text"` // one-line summary, e.g. for chat webhooks
+	Sound   bool      `json:"-"`
+	Notify  bool      `json:"-"`
+	Webhook string    `json:"-"`
+}
+
+// DefaultPath returns the alert rules file location, honoring GOTORCH_ALERT_RULES.
+func DefaultPath() string {
+	if p := os.Getenv("GOTORCH_ALERT_RULES"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "gotorch_alert_rules.json"
+	}
+	return filepath.Join(dir, "GoTorch", "alert_rules.json")
+}
+
+// Load reads a JSON array of rules from path. A missing file means no rules.
+func Load(path string) ([]Rule, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rules []Rule
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("alerts: %s: %w", path, err)
+	}
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// Engine matches drops against rules, applying each rule's cooldown. It is safe for concurrent use.
+type Engine struct {
+	mu    sync.Mutex
+	rules []Rule
+	last  []time.Time // last alert per rule, by index
+}
+
+// NewEngine creates an engine for rules.
+func NewEngine(rules []Rule) *Engine {
+	return &Engine{rules: rules, last: make([]time.Time, len(rules))}
+}
+
+// Rules returns the engine's rules.
+func (e *Engine) Rules() []Rule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Rule(nil), e.rules...)
+}
+
+// Restart forgets the cooldowns, for a new session.
+func (e *Engine) Restart() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.last = make([]time.Time, len(e.rules))
+}
+
+// Check returns an alert for every rule d matches and that is not cooling down. Names, types
+// and, for unpriced drops, unit prices come from cat, which may be nil.
+func (e *Engine) Check(d tracker.Drop, cat *items.Catalog) []Alert {
+	var it items.Item
+	if cat != nil {
+		it, _ = cat.GetInt(d.ItemID)
+	}
+	unit := d.Unit
+	if unit == 0 {
+		unit = it.Price
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var out []Alert
+	for i, r := range e.rules {
+		if !r.match(d, it, unit) {
+			continue
+		}
+		cooldown := time.Duration(r.CooldownSeconds * float64(time.Second))
+		if last := e.last[i]; !last.IsZero() && d.At.Before(last.Add(cooldown)) && !d.At.Before(last) {
+			continue
+		}
+		e.last[i] = d.At
+		a := Alert{Rule: r.Name, ItemID: d.ItemID, Name: it.Name, Type: it.Type, Count: d.Count, Unit: unit,
+			Total: unit * float64(d.Count), At: d.At, MapKey: d.MapKey, Sound: r.Sound, Notify: r.Notify, Webhook: r.Webhook}
+		if a.Name == "" {
+			a.Name = "#" + strconv.Itoa(d.ItemID)
+		}
+		a.Text = text(a)
+		out = append(out, a)
+	}
+	return out
+}
+
+func (r Rule) match(d tracker.Drop, it items.Item, unit float64) bool {
+	if len(r.ItemIDs) > 0 && !slices.Contains(r.ItemIDs, d.ItemID) {
+		return false
+	}
+	if len(r.Types) > 0 && !slices.ContainsFunc(r.Types, func(t string) bool { return strings.EqualFold(t, it.Type) }) {
+		return false
+	}
+	if r.MinUnitValue > 0 && unit < r.MinUnitValue {
+		return false
+	}
+	if r.MinTotalValue > 0 && unit*float64(d.Count) < r.MinTotalValue {
+		return false
+	}
+	return true
+}
+
+func text(a Alert) string {
+	s := fmt.Sprintf("%s x%d", a.Name, a.Count)
+	if a.Total > 0 {
+		s += " worth " + strconv.FormatFloat(a.Total, 'f', 2, 64)
+	}
+	if a.MapKey != "" {
+		s += " in " + a.MapKey
+	}
+	return s
+}
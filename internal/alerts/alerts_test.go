@@ -0,0 +1,75 @@
+package alerts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"GoTorch/internal/items"
+	"GoTorch/internal/tracker"
+)
+
+func testCatalog() *items.Catalog {
+	return items.New(map[string]items.Item{
+		"100300": {Name: "Flame Elementium", Type: "Currency", Price: 1},
+		"5210":   {Name: "Ember", Type: "Ember", Price: 10},
+		"300200": {Name: "Divinity Slate", Type: "Divinity", Price: 150},
+	}, "test")
+}
+
+func TestCheckMatchesRulesWithCooldown(t *testing.T) {
+	e := NewEngine([]Rule{
+		{Name: "slates", Types: []string{"divinity"}, Notify: true},
+		{Name: "big stack", MinTotalValue: 50, CooldownSeconds: 60, Sound: true},
+		{Name: "flame", ItemIDs: []int{100300}, MinUnitValue: 5},
+	})
+	cat := testCatalog()
+	start := time.Now()
+	drop := func(id, n int, d time.Duration) tracker.Drop {
+		return tracker.Drop{ItemID: id, Count: n, At: start.Add(d), MapKey: "YJ_YongZhouHuiLang200"}
+	}
+
+	got := e.Check(drop(300200, 1, 0), cat)
+	if len(got) != 2 || got[0].Rule != "slates" || got[1].Rule != "big stack" || !got[0].Notify || !got[1].Sound {
+		t.Fatalf("unexpected alerts %+v", got)
+	}
+	if got[0].Total != 150 || got[0].Text != "Divinity Slate x1 worth 150.00 in YJ_YongZhouHuiLang200" {
+		t.Fatalf("unexpected alert details %+v", got[0])
+	}
+	// big stack is cooling down; flame is below its unit value threshold
+	if got := e.Check(drop(5210, 6, 30*time.Second), cat); len(got) != 0 {
+		t.Fatalf("expected no alerts during cooldown, got %+v", got)
+	}
+	if got := e.Check(drop(5210, 6, 61*time.Second), cat); len(got) != 1 || got[0].Rule != "big stack" {
+		t.Fatalf("expected alert after cooldown, got %+v", got)
+	}
+	// the price locked in at drop time wins over the item table
+	d := drop(100300, 2, 2*time.Minute)
+	d.Unit = 8
+	if got := e.Check(d, cat); len(got) != 1 || got[0].Rule != "flame" || got[0].Total != 16 {
+		t.Fatalf("expected flame alert at drop price, got %+v", got)
+	}
+	e.Restart()
+	if got := e.Check(drop(5210, 6, 62*time.Second), cat); len(got) != 1 {
+		t.Fatalf("expected cooldown cleared by Restart, got %+v", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	if rules, err := Load(filepath.Join(dir, "missing.json")); err != nil || rules != nil {
+		t.Fatalf("missing file: %v, %v", rules, err)
+	}
+	path := filepath.Join(dir, "rules.json")
+	os.WriteFile(path, []byte(`[{"name":"slates","types":["Divinity"],"cooldown_seconds":30,"webhook":"https://example.com/hook"}]`), 0o644)
+	rules, err := Load(path)
+	if err != nil || len(rules) != 1 || rules[0].CooldownSeconds != 30 || rules[0].Webhook != "https://example.com/hook" {
+		t.Fatalf("Load = %+v, %v", rules, err)
+	}
+	os.WriteFile(path, []byte(`[{"name":"everything"}]`), 0o644)
+	if _, err := Load(path); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for a rule without criteria, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"time"
+
+	"GoTorch/internal/alerts"
+	"GoTorch/internal/tracker"
+	"GoTorch/internal/webhook"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// UIDropAlert is sent to the frontend as a "drop-alert" event when a drop matches an alert rule.
+type UIDropAlert struct {
+	Rule   string  `json:"rule"`
+	ItemID string  `json:"itemId"`
+	Name   string  `json:"name"`
+	Type   string  `json:"type"`
+	Count  int     `json:"count"`
+	Unit   float64 `json:"unit"`
+	Total  float64 `json:"total"`
+	At     int64   `json:"at"`
+	MapKey string  `json:"mapKey"`
+	Text   string  `json:"text"`
+	Sound  bool    `json:"sound"`
+	Notify bool    `json:"notify"`
+}
+
+// loadAlertRules replaces the alert engine with the rules from GOTORCH_ALERT_RULES or the
+// default rules file. Invalid rules are logged and leave no rules active. Each rule webhook
+// URL gets its own dispatcher, so alerts are queued and retried like lifecycle events.
+func (a *App) loadAlertRules() {
+	rules, err := alerts.Load(alerts.DefaultPath())
+	if err != nil && a.isWailsContext() {
+		runtime.LogWarningf(a.ctx, "alert rules not loaded: %v", err)
+	}
+	a.closeAlertHooks()
+	a.alerts = alerts.NewEngine(rules)
+	for _, r := range rules {
+		if r.Webhook == "" || a.alertHooks[r.Webhook] != nil {
+			continue
+		}
+		s, err := webhook.NewSink(webhook.Config{Name: r.Name, URL: r.Webhook}, nil)
+		if err != nil {
+			if a.isWailsContext() {
+				runtime.LogWarningf(a.ctx, "alert webhook skipped: %v", err)
+			}
+			continue
+		}
+		d := webhook.NewDispatcher([]*webhook.Sink{s})
+		d.OnError = func(err error) {
+			if a.isWailsContext() {
+				runtime.LogWarningf(a.ctx, "drop alert webhook failed: %v", err)
+			}
+		}
+		if a.alertHooks == nil {
+			a.alertHooks = make(map[string]*webhook.Dispatcher)
+		}
+		a.alertHooks[r.Webhook] = d
+	}
+}
+
+// closeAlertHooks delivers the queued alerts like closeWebhooks and forgets the dispatchers.
+func (a *App) closeAlertHooks() {
+	for _, d := range a.alertHooks {
+		d.Close(5 * time.Second)
+	}
+	a.alertHooks = nil
+}
+
+// AlertRules returns the active drop alert rules.
+func (a *App) AlertRules() []alerts.Rule {
+	return a.alerts.Rules()
+}
+
+// onDrop checks a confirmed drop against the alert rules. Each alert is emitted to the UI as a
+// "drop-alert" event and published as a drop_alert event to its rule's webhook, if any.
+func (a *App) onDrop(d tracker.Drop) {
+	for _, al := range a.alerts.Check(d, a.items) {
+		if a.isWailsContext() {
+			runtime.EventsEmit(a.ctx, "drop-alert", toUIDropAlert(al))
+		}
+		if h := a.alertHooks[al.Webhook]; h != nil {
+			h.Publish(webhook.Event{Type: webhook.DropAlert, At: al.At, Text: al.Text, Alert: &webhook.Alert{
+				Rule: al.Rule, ItemID: al.ItemID, Name: al.Name, Type: al.Type,
+				Count: al.Count, Unit: al.Unit, Total: al.Total, MapKey: al.MapKey,
+			}})
+		}
+	}
+}
+
+func toUIDropAlert(al alerts.Alert) UIDropAlert {
+	return UIDropAlert{
+		Rule:   al.Rule,
+		ItemID: intToStr(al.ItemID),
+		Name:   al.Name,
+		Type:   al.Type,
+		Count:  al.Count,
+		Unit:   al.Unit,
+		Total:  al.Total,
+		At:     al.At.UnixMilli(),
+		MapKey: al.MapKey,
+		Text:   al.Text,
+		Sound:  al.Sound,
+		Notify: al.Notify,
+	}
+}
@@ -2,16 +2,23 @@ package app
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"os"
-	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"GoTorch/internal/alerts"
+	"GoTorch/internal/goals"
+	"GoTorch/internal/history"
+	"GoTorch/internal/items"
 	"GoTorch/internal/parser"
+	"GoTorch/internal/pricehistory"
 	"GoTorch/internal/pricing"
+	"GoTorch/internal/stats"
 	"GoTorch/internal/tailer"
 	"GoTorch/internal/tracker"
+	"GoTorch/internal/webhook"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -24,24 +31,91 @@ type App struct {
 	trk      *tracker.Tracker
 	p        *parser.Parser
 	t        *tailer.Tailer
+	multi    *tailer.Multi // set instead of t when following several files
 	lines    chan string
 	cancel   context.CancelFunc
 	emitStop context.CancelFunc
 
-	// item table loaded from full_table.json (or embedded fallback)
-	items       map[string]ItemInfo
-	itemsSource string // diagnostic: where items were loaded from
+	// item catalog loaded from full_table.json (or embedded fallback)
+	items *items.Catalog
+
+	// completed maps are persisted here across restarts
+	history *history.Store
+	// every fetched price point is appended here
+	priceHistory *pricehistory.Store
+	// session goals, checked whenever the UI state is built
+	goals *goals.Set
+	// drop alert rules, checked for every confirmed drop
+	alerts *alerts.Engine
+	// lifecycle events are posted here; nil without configured webhooks
+	webhooks *webhook.Dispatcher
+	// drop alerts are posted here, by rule webhook URL
+	alertHooks map[string]*webhook.Dispatcher
+	// start of the last session reported as ended, so it is reported once
+	endedSession time.Time
+	// tailer checkpoint + tracker snapshot file used to resume after a restart
+	resumePath string
+	readerDone chan struct{}
+	// completed maps so far; the reader saves the resume state as soon as it changes
+	mapsDone atomic.Uint64
+	// when the reader last received a line (unix ns), to settle drops while the log is quiet
+	lastRead atomic.Int64
 }
 
 func New() *App {
-	return &App{trk: tracker.New(), p: parser.New()}
+	a := &App{p: parser.New(), goals: goals.NewSet(), alerts: alerts.NewEngine(nil)}
+	a.trk = a.newTracker()
+	return a
+}
+
+// newTracker creates a fresh tracker wired to the app (see wireTracker).
+func (a *App) newTracker() *tracker.Tracker {
+	return a.wireTracker(tracker.New())
+}
+
+// wireTracker makes trk value drops at the current prices as they happen, check them against
+// the alert rules, persist completed maps to history and publish map events to the webhooks.
+func (a *App) wireTracker(trk *tracker.Tracker) *tracker.Tracker {
+	trk.SetPricing(func(id int, _ time.Time) float64 { return a.priceOf(id) })
+	trk.SetIdleThreshold(idleThreshold())
+	trk.OnMapStart(a.onMapStart)
+	trk.OnMapComplete(func(sessionStartedAt time.Time, m tracker.MapSession) {
+		a.onMapComplete(sessionStartedAt, m)
+		a.mapsDone.Add(1)
+	})
+	trk.OnDrop(a.onDrop)
+	return trk
+}
+
+// idleThreshold returns the inactivity threshold from GOTORCH_IDLE_THRESHOLD (e.g. "3m"),
+// or tracker.DefaultIdleThreshold when unset or invalid.
+func idleThreshold() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("GOTORCH_IDLE_THRESHOLD")); err == nil && d >= 0 {
+		return d
+	}
+	return tracker.DefaultIdleThreshold
+}
+
+// tailMode returns the tailer mode from GOTORCH_TAIL_MODE (auto, poll or watch), or
+// tailer.ModeAuto when unset or invalid.
+func tailMode() tailer.Mode {
+	m, err := tailer.ParseMode(os.Getenv("GOTORCH_TAIL_MODE"))
+	if err != nil {
+		return tailer.ModeAuto
+	}
+	return m
 }
 
 // Startup is called by Wails when the app starts.
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
+	a.history = history.Open(history.DefaultPath())
+	a.priceHistory = pricehistory.Open(pricehistory.DefaultPath())
+	a.resumePath = defaultResumePath()
 	// Load item metadata table on startup
 	a.loadItemTable()
+	a.loadAlertRules()
+	a.loadWebhooks()
 	// Refresh prices from remote endpoint with a short timeout; ignore errors.
 	a.refreshPrices()
 }
@@ -49,117 +123,133 @@ func (a *App) Startup(ctx context.Context) {
 // Shutdown is called by Wails when the app terminates.
 func (a *App) Shutdown(ctx context.Context) {
 	a.Stop()
+	a.closeWebhooks()
 }
 
-// loadItemTable attempts to load full_table.json from common locations, with env override and embedded fallback.
+// loadItemTable loads the item catalog and logs its source.
 func (a *App) loadItemTable() {
-	// default to empty map
-	a.items = map[string]ItemInfo{}
-	a.itemsSource = "none"
-
-	readItemFile := func(path string) (map[string]ItemInfo, bool) {
-		b, err := os.ReadFile(path)
-		if err != nil {
-			return nil, false
-		}
-		var m map[string]ItemInfo
-		if err := json.Unmarshal(b, &m); err != nil || len(m) == 0 {
-			return nil, false
-		}
-		return m, true
-	}
-
-	// 1) Environment variable override
-	if p := os.Getenv("GOTORCH_ITEM_TABLE"); p != "" {
-		if m, ok := readItemFile(p); ok {
-			a.items = m
-			a.itemsSource = "env:" + p
-			goto DONE
-		}
-	}
-	// 2) Working directory
-	if m, ok := readItemFile("full_table.json"); ok {
-		a.items = m
-		a.itemsSource = "file:./full_table.json"
-		goto DONE
-	}
-	// 3) Executable directory
-	if exe, err := os.Executable(); err == nil {
-		dir := filepath.Dir(exe)
-		if m, ok := readItemFile(filepath.Join(dir, "full_table.json")); ok {
-			a.items = m
-			a.itemsSource = "exe_dir:full_table.json"
-			goto DONE
-		}
-	}
-	// 4) Embedded fallback
-	if b, err := readEmbeddedItemTable(); err == nil && len(b) > 0 {
-		var m map[string]ItemInfo
-		if err := json.Unmarshal(b, &m); err == nil && len(m) > 0 {
-			a.items = m
-			a.itemsSource = "embedded"
-			goto DONE
-		}
-	}
-
-DONE:
+	a.items = items.Load()
 	if a.isWailsContext() {
-		runtime.LogInfof(a.ctx, "item table loaded (%d items) from %s", len(a.items), a.itemsSource)
+		runtime.LogInfof(a.ctx, "item table loaded (%d items) from %s", a.items.Len(), a.items.Source())
 	}
 }
 
 // StartTracking starts tailing the given log path and emitting state updates to the UI.
 // By default, it tails from the end (does not read historical lines).
 func (a *App) StartTracking(logPath string) error {
-	return a.startTrackingInternal(logPath, false)
+	return a.startTrackingInternal([]string{logPath}, false)
 }
 
 // StartTrackingWithOptions allows the caller to control whether to read from the start.
 // Set fromStart=true during development to process historical lines; false in production.
 func (a *App) StartTrackingWithOptions(logPath string, fromStart bool) error {
-	return a.startTrackingInternal(logPath, fromStart)
+	return a.startTrackingInternal([]string{logPath}, fromStart)
+}
+
+// StartTrackingPaths follows several log files or glob patterns, e.g. the standalone and Steam
+// clients' logs. With fromStart, their existing lines are merged in timestamp order first.
+// Resuming after a restart only applies to a single file.
+func (a *App) StartTrackingPaths(paths []string, fromStart bool) error {
+	if len(paths) == 0 {
+		return errors.New("no log paths")
+	}
+	return a.startTrackingInternal(paths, fromStart)
 }
 
-func (a *App) startTrackingInternal(logPath string, fromStart bool) error {
+func (a *App) startTrackingInternal(paths []string, fromStart bool) error {
+	// stop previous session if any, letting its reader save the resume state
+	a.stop()
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	single := len(paths) == 1 && !tailer.IsPattern(paths[0])
+	// a session started meanwhile is stopped without waiting
+	a.stopLocked()
+	old := a.trk
 
-	// stop previous session if any
-	if a.cancel != nil {
-		a.cancel()
-		a.cancel = nil
+	// Reset tracker state on each (re)start so timers always begin from fresh events
+	// and no historical state is carried over, unless a saved checkpoint for this log
+	// lets us continue the interrupted run.
+	a.trk = a.newTracker()
+	var resume *tailer.Checkpoint
+	if !fromStart && single {
+		if trk, cp := loadResume(a.resumePath, paths[0]); trk != nil {
+			a.trk = a.wireTracker(trk)
+			resume = cp
+		}
 	}
-	if a.emitStop != nil {
-		a.emitStop()
-		a.emitStop = nil
+	if resume == nil {
+		a.endSession(old)
+		a.goals.Restart()
+		a.alerts.Restart()
 	}
 
-	// Reset tracker state on each (re)start so timers always begin from fresh events
-	// and no historical state is carried over.
-	a.trk = tracker.New()
-
 	ctx, cancel := context.WithCancel(a.ctx)
 	a.cancel = cancel
 
 	lines := make(chan string, 2048)
 	a.lines = lines
-	a.t = tailer.New(tailer.Options{Path: logPath, FromStart: fromStart})
-	// start tailer
-	go func() {
-		_ = a.t.Start(ctx, lines)
-	}()
-	// start reader + parser
+	opt := tailer.Options{FromStart: fromStart, Mode: tailMode(), Overflow: tailOverflow()}
+	var t *tailer.Tailer
+	var tagged chan tailer.Line // lines of a.multi; nil (never ready) with a single tailer
+	a.t, a.multi = nil, nil
+	if single {
+		opt.Path, opt.Resume = paths[0], resume
+		t = tailer.New(opt)
+		a.t = t
+		go func() {
+			_ = t.Start(ctx, lines)
+		}()
+	} else {
+		tagged = make(chan tailer.Line, 2048)
+		m := tailer.NewMulti(tailer.MultiOptions{Paths: paths, Options: opt, Timestamp: a.p.Timestamp})
+		a.multi = m
+		go func() {
+			_ = m.Start(ctx, tagged)
+		}()
+	}
+	// start reader + parser; it owns the consumed line count, so resume state is
+	// saved from here to keep the checkpoint and tracker snapshot consistent.
+	done := make(chan struct{})
+	a.readerDone = done
+	resumePath := a.resumePath
 	go func() {
+		defer close(done)
+		var consumed uint64
+		save := time.NewTicker(5 * time.Second)
+		defer save.Stop()
 		for {
 			select {
 			case <-ctx.Done():
+				trk := a.tracker()
+				trk.Flush()
+				_ = saveResume(resumePath, t, trk, consumed)
 				return
+			case <-save.C:
+				if err := saveResume(resumePath, t, a.tracker(), consumed); err != nil && a.isWailsContext() {
+					runtime.LogWarningf(a.ctx, "resume save failed: %v", err)
+				}
 			case line, ok := <-lines:
 				if !ok {
 					return
 				}
+				a.lastRead.Store(time.Now().UnixNano())
+				maps := a.mapsDone.Load()
+				trk := a.tracker()
 				if ev := a.p.Parse(line); ev != nil {
-					a.trk.OnEvent(ev)
+					trk.OnEvent(ev)
+				}
+				consumed++
+				// A completed map is already in history and published; checkpoint past it
+				// so a restart does not replay its end and record it twice.
+				if a.mapsDone.Load() != maps {
+					if err := saveResume(resumePath, t, trk, consumed); err != nil && a.isWailsContext() {
+						runtime.LogWarningf(a.ctx, "resume save failed: %v", err)
+					}
+				}
+			case l := <-tagged:
+				a.lastRead.Store(time.Now().UnixNano())
+				if ev := a.p.Parse(l.Text); ev != nil {
+					a.tracker().OnEvent(ev)
 				}
 			}
 		}
@@ -176,6 +266,10 @@ func (a *App) startTrackingInternal(logPath string, fromStart bool) error {
 			case <-emitCtx.Done():
 				return
 			case <-ticker.C:
+				// drops at the end of a quiet log are confirmed without waiting for the next line
+				if at := a.lastRead.Load(); at != 0 {
+					a.tracker().Settle(time.Since(time.Unix(0, at)))
+				}
 				if a.isWailsContext() {
 					runtime.EventsEmit(a.ctx, "state", a.UIState())
 				}
@@ -186,10 +280,38 @@ func (a *App) startTrackingInternal(logPath string, fromStart bool) error {
 	return nil
 }
 
-// Stop tracking and background goroutines.
+// Stop tracking and background goroutines. The session is reported as ended to the webhooks.
 func (a *App) Stop() {
+	a.stop()
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	a.endSession(a.trk)
+}
+
+// stop cancels background goroutines and waits briefly, without holding a.mu, for the reader
+// to save its resume state.
+func (a *App) stop() {
+	a.mu.Lock()
+	done := a.stopLocked()
+	a.mu.Unlock()
+	if done != nil {
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// tracker returns the current tracker; Reset and StartTracking replace it.
+func (a *App) tracker() *tracker.Tracker {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.trk
+}
+
+// stopLocked cancels background goroutines and returns the reader's done channel, closed
+// once it has saved its resume state. a.mu must be held.
+func (a *App) stopLocked() <-chan struct{} {
 	if a.cancel != nil {
 		a.cancel()
 		a.cancel = nil
@@ -201,13 +323,41 @@ func (a *App) Stop() {
 	if a.t != nil {
 		a.t.Stop()
 	}
+	if a.multi != nil {
+		a.multi.Stop()
+	}
+	done := a.readerDone
+	a.readerDone = nil
+	return done
 }
 
-// Reset clears the current tracker state (does not stop tracking).
+// Reset clears the current tracker state (does not stop tracking), ending the session.
+// The saved resume state is discarded so a restart does not bring it back.
 func (a *App) Reset() {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	a.trk = tracker.New()
+	a.endSession(a.trk)
+	a.trk = a.newTracker()
+	a.goals.Restart()
+	a.alerts.Restart()
+	if a.resumePath != "" {
+		_ = os.Remove(a.resumePath)
+	}
+}
+
+// Pause stops counting drops and time without stopping tracking; log lines are still read
+// so the inventory baseline stays correct. It returns false if already paused.
+func (a *App) Pause() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.trk.Pause(time.Now())
+}
+
+// Resume continues counting after Pause. It returns false if not paused.
+func (a *App) Resume() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.trk.Resume(time.Now())
 }
 
 // SelectLogFile opens a file dialog and returns the selected log file path.
@@ -230,65 +380,134 @@ func (a *App) GetState() UIState {
 }
 
 // UIState converts internal tracker state to a JSON-friendly struct for the UI.
+// Goals are checked here; each newly met goal emits a "goal-met" event and is published to the webhooks.
 func (a *App) UIState() UIState {
-	st := a.trk.GetState()
+	st := a.tracker().GetState()
+	now := time.Now()
+	ui := BuildUIState(st, a.items, now)
+	progress, met := a.goals.Update(st, a.priceOf, now)
+	ui.Goals = ToUIGoals(progress)
+	for _, p := range met {
+		a.publish(webhook.Event{Type: webhook.GoalMet, At: p.DoneAt,
+			Goal: &webhook.Goal{ID: p.ID, Label: p.Label, Target: p.Target, Current: p.Current}})
+	}
+	if a.isWailsContext() {
+		for _, g := range ToUIGoals(met) {
+			runtime.EventsEmit(a.ctx, "goal-met", g)
+		}
+	}
+	return ui
+}
+
+// AddGoal adds a session goal from a spec such as "earnings:500", "item:<id or name>:20" or "maps:30".
+func (a *App) AddGoal(spec string) (UIGoal, error) {
+	g, err := goals.Parse(spec, a.items)
+	if err != nil {
+		return UIGoal{}, err
+	}
+	g = a.goals.Add(g)
+	return ToUIGoals([]goals.Progress{{Goal: g}})[0], nil
+}
+
+// RemoveGoal deletes a session goal by id.
+func (a *App) RemoveGoal(id string) bool {
+	return a.goals.Remove(id)
+}
+
+// Goals returns the session goals with their progress.
+func (a *App) Goals() []UIGoal {
+	return a.UIState().Goals
+}
+
+// ToUIGoals converts goal progress to the UI schema.
+func ToUIGoals(ps []goals.Progress) []UIGoal {
+	out := make([]UIGoal, 0, len(ps))
+	for _, p := range ps {
+		g := UIGoal{
+			ID:      p.ID,
+			Kind:    string(p.Kind),
+			Label:   p.Label,
+			Target:  p.Target,
+			Current: p.Current,
+			Percent: p.Percent,
+			Done:    p.Done,
+		}
+		if p.ItemID != 0 {
+			g.ItemID = intToStr(p.ItemID)
+		}
+		if !p.DoneAt.IsZero() {
+			g.DoneAt = p.DoneAt.UnixMilli()
+		}
+		out = append(out, g)
+	}
+	return out
+}
+
+// BuildUIState converts a tracker state snapshot to the UI schema, valuing drops with cat.
+// cat may be nil; now is used for the duration of an active map and session.
+func BuildUIState(st tracker.State, cat *items.Catalog, now time.Time) UIState {
 	// Build UI tally by enriching with metadata; include unknown IDs as placeholders
 	uiTally := make(map[string]UITallyItem)
 	for id, n := range st.Current.Tally {
 		key := intToStr(id)
-		if a.items != nil {
-			if info, ok := a.items[key]; ok {
+		if cat != nil {
+			if info, ok := cat.Get(key); ok {
 				uiTally[key] = UITallyItem{
-					Name:       info.Name,
-					Type:       info.Type,
-					Price:      info.Price,
-					LastUpdate: info.LastUpdate,
-					From:       info.From,
-					Count:      n,
+					Name:        info.Name,
+					Type:        info.Type,
+					Price:       info.Price,
+					LastUpdate:  info.LastUpdate,
+					From:        info.From,
+					Count:       n,
+					PriceStatus: pricing.Staleness(info.LastUpdate, now, pricing.DefaultMaxAge),
 				}
 				continue
 			}
 		}
 		// Fallback: show unknown IDs so the tally is visible even without item table
 		uiTally[key] = UITallyItem{
-			Name:  "#" + key,
-			Type:  "Unknown",
-			Price: 0,
-			Count: n,
+			Name:        "#" + key,
+			Type:        "Unknown",
+			Price:       0,
+			Count:       n,
+			PriceStatus: pricing.StatusUnknown,
 		}
 	}
 	// Compute per-map earnings for completed maps + current
+	price := func(id int) float64 {
+		if cat == nil {
+			return 0
+		}
+		return cat.PriceOf(id)
+	}
 	maps := make([]UIMap, 0, len(st.Completed)+1)
-	var sessionEarnings float64
+	var sessionEarnings, sessionAtDrop, sessionCost, sessionCostAtDrop float64
 	var totalMapDurMs int64
 	for _, m := range st.Completed {
-		var earn float64
-		for id, c := range m.Tally {
-			key := intToStr(id)
-			if info, ok := a.items[key]; ok {
-				earn += float64(c) * info.Price
-			}
-		}
-		durMs := m.EndedAt.Sub(m.StartedAt).Milliseconds()
-		maps = append(maps, UIMap{Start: m.StartedAt.UnixMilli(), End: m.EndedAt.UnixMilli(), DurationMs: durMs, Earnings: earn})
-		sessionEarnings += earn
-		totalMapDurMs += durMs
+		um := uiMap(m, price)
+		um.End = m.EndedAt.UnixMilli()
+		um.DurationMs = m.Duration().Milliseconds()
+		maps = append(maps, um)
+		sessionEarnings += um.Earnings
+		sessionAtDrop += um.EarningsAtDrop
+		sessionCost += um.Cost
+		sessionCostAtDrop += um.CostAtDrop
+		totalMapDurMs += um.DurationMs
 	}
 	// current map earnings
-	var currentEarn float64
-	for id, c := range st.Current.Tally {
-		key := intToStr(id)
-		if info, ok := a.items[key]; ok {
-			currentEarn += float64(c) * info.Price
-		}
-	}
+	current := uiMap(st.Current, price)
 	// include current map as last entry only if active (avoid duplicating a completed current)
 	if st.Current.Active && !st.Current.StartedAt.IsZero() {
-		end := time.Now()
-		durMs := end.Sub(st.Current.StartedAt).Milliseconds()
-		maps = append(maps, UIMap{Start: st.Current.StartedAt.UnixMilli(), End: 0, DurationMs: durMs, Earnings: currentEarn})
+		current.DurationMs = st.CurrentDuration(now).Milliseconds()
+		maps = append(maps, current)
+	}
+	// after MapEnd, Current still holds the completed map, which is already counted above
+	if st.Current.Active {
+		sessionEarnings += current.Earnings
+		sessionAtDrop += current.EarningsAtDrop
+		sessionCost += current.Cost
+		sessionCostAtDrop += current.CostAtDrop
 	}
-	sessionEarnings += currentEarn
 	// compute earnings per hour over session duration
 	var sessionStartMs int64
 	var sessionEndMs int64
@@ -297,16 +516,36 @@ func (a *App) UIState() UIState {
 		if !st.SessionEndedAt.IsZero() && !st.Current.Active {
 			sessionEndMs = st.SessionEndedAt.UnixMilli()
 		} else {
-			sessionEndMs = time.Now().UnixMilli()
+			sessionEndMs = now.UnixMilli()
 		}
 	}
-	var eph float64
+	var eph, ephAtDrop, net, netAtDrop float64
 	if sessionStartMs > 0 && sessionEndMs > sessionStartMs {
-		durH := float64(sessionEndMs-sessionStartMs) / 3600000.0
+		// paused intervals are not part of the session's wall time
+		pausedMs := st.PausedBetween(st.SessionStartedAt, time.UnixMilli(sessionEndMs)).Milliseconds()
+		durH := float64(sessionEndMs-sessionStartMs-pausedMs) / 3600000.0
 		if durH > 0 {
 			eph = sessionEarnings / durH
+			ephAtDrop = sessionAtDrop / durH
+			net = (sessionEarnings - sessionCost) / durH
+			netAtDrop = (sessionAtDrop - sessionCostAtDrop) / durH
 		}
 	}
+	// the same rates over active time only, excluding idle gaps
+	activity := st.Activity(now)
+	var activeEph, activeNet float64
+	if h := activity.Active().Hours(); h > 0 {
+		activeEph = sessionEarnings / h
+		activeNet = (sessionEarnings - sessionCost) / h
+	}
+	pauses := make([]UIInterval, 0, len(st.Pauses))
+	for _, p := range st.Pauses {
+		iv := UIInterval{Start: p.Start.UnixMilli()}
+		if !p.End.IsZero() {
+			iv.End = p.End.UnixMilli()
+		}
+		pauses = append(pauses, iv)
+	}
 	// average time per completed map
 	var avgMapMs int64
 	if len(st.Completed) > 0 {
@@ -327,45 +566,214 @@ func (a *App) UIState() UIState {
 		mapEndMs = st.Current.EndedAt.UnixMilli()
 	}
 	return UIState{
-		InMap:              st.InMap && st.Current.Active,
-		SessionStart:       sessionStartMs,
-		SessionEnd:         sessionEndMs,
-		MapStart:           mapStartMs,
-		MapEnd:             mapEndMs,
-		TotalDrops:         st.TotalDrops,
-		Tally:              uiTally,
-		Recent:             recent,
-		Maps:               maps,
-		EarningsPerSession: sessionEarnings,
-		EarningsPerHour:    eph,
-		AvgMapTimeMs:       avgMapMs,
+		InMap:                    st.InMap && st.Current.Active,
+		SessionStart:             sessionStartMs,
+		SessionEnd:               sessionEndMs,
+		MapStart:                 mapStartMs,
+		MapEnd:                   mapEndMs,
+		TotalDrops:               st.TotalDrops,
+		Tally:                    uiTally,
+		Recent:                   recent,
+		Maps:                     maps,
+		EarningsPerSession:       sessionEarnings,
+		EarningsPerHour:          eph,
+		AvgMapTimeMs:             avgMapMs,
+		EarningsPerSessionAtDrop: sessionAtDrop,
+		EarningsPerHourAtDrop:    ephAtDrop,
+		CostPerSession:           sessionCost,
+		NetPerSession:            sessionEarnings - sessionCost,
+		NetEarningsPerHour:       net,
+		NetEarningsPerHourAtDrop: netAtDrop,
+		InMapTimeMs:              activity.InMap.Milliseconds(),
+		TownTimeMs:               activity.Town.Milliseconds(),
+		IdleTimeMs:               activity.Idle.Milliseconds(),
+		ActiveEarningsPerHour:    activeEph,
+		ActiveNetEarningsPerHour: activeNet,
+		Paused:                   st.Paused,
+		PausedTimeMs:             activity.Paused.Milliseconds(),
+		Pauses:                   pauses,
+		Goals:                    []UIGoal{},
+		PriceStatus:              overallPriceStatus(uiTally),
 	}
 }
 
-// ItemInfo represents an item entry from full_table.json
-type ItemInfo struct {
-	Name       string  `json:"name"`
-	Type       string  `json:"type"`
-	Price      float64 `json:"price"`
-	LastUpdate float64 `json:"last_update"`
-	From       string  `json:"from"`
+// uiMap values a map session's drops and consumed items; End and DurationMs are left to the caller.
+func uiMap(m tracker.MapSession, price stats.PriceFunc) UIMap {
+	um := UIMap{
+		Start:          m.StartedAt.UnixMilli(),
+		Earnings:       stats.Earnings(m.Tally, price),
+		EarningsAtDrop: stats.ValueAtDrop(m, price),
+		Cost:           stats.Cost(m, price),
+		CostAtDrop:     stats.CostAtUse(m, price),
+		MapKey:         m.MapKey,
+		Region:         m.Region,
+	}
+	um.Net = um.Earnings - um.Cost
+	return um
+}
+
+// overallPriceStatus is stale if any tallied price is stale, unknown if any is unknown (or nothing is tallied), else fresh.
+func overallPriceStatus(tally map[string]UITallyItem) string {
+	status := pricing.StatusFresh
+	if len(tally) == 0 {
+		status = pricing.StatusUnknown
+	}
+	for _, it := range tally {
+		switch it.PriceStatus {
+		case pricing.StatusStale:
+			return pricing.StatusStale
+		case pricing.StatusUnknown:
+			status = pricing.StatusUnknown
+		}
+	}
+	return status
 }
 
+// MapStats returns per-map-key profitability statistics over the completed maps of the session.
+func (a *App) MapStats() []UIMapStats {
+	st := a.tracker().GetState()
+	agg := stats.ByMap(st.Completed, a.priceOf)
+	out := make([]UIMapStats, 0, len(agg))
+	for _, m := range agg {
+		rates := make(map[string]float64, len(m.DropRate))
+		for id, r := range m.DropRate {
+			rates[intToStr(id)] = r
+		}
+		out = append(out, UIMapStats{
+			MapKey:                m.MapKey,
+			Region:                m.Region,
+			Runs:                  m.Runs,
+			MeanDurationMs:        m.MeanDuration.Milliseconds(),
+			MedianDurationMs:      m.MedianDuration.Milliseconds(),
+			P90DurationMs:         m.P90Duration.Milliseconds(),
+			MeanEarnings:          m.MeanEarnings,
+			EarningsPerHour:       m.EarningsPerHour,
+			DropRate:              rates,
+			MeanEarningsAtDrop:    m.MeanEarningsAtDrop,
+			EarningsPerHourAtDrop: m.EarningsPerHourAtDrop,
+			MeanCost:              m.MeanCost,
+			NetEarningsPerHour:    m.NetEarningsPerHour,
+		})
+	}
+	return out
+}
+
+// saveMap persists a completed map with the prices locked in when its items dropped.
+func (a *App) saveMap(sessionStartedAt time.Time, m tracker.MapSession) {
+	if a.history == nil {
+		return
+	}
+	if _, err := a.history.Append(history.NewRecord(sessionStartedAt, m, a.priceOf)); err != nil && a.isWailsContext() {
+		runtime.LogWarningf(a.ctx, "history save failed: %v", err)
+	}
+}
+
+// ListHistory returns every saved map run, oldest first.
+func (a *App) ListHistory() ([]UIHistoryRecord, error) {
+	return a.QueryHistory("", 0, 0)
+}
+
+// QueryHistory returns saved map runs filtered by map key and a start-time window
+// in epoch milliseconds. Empty/zero arguments match everything.
+func (a *App) QueryHistory(mapKey string, sinceMs, untilMs int64) ([]UIHistoryRecord, error) {
+	if a.history == nil {
+		return []UIHistoryRecord{}, nil
+	}
+	q := history.Query{MapKey: mapKey}
+	if sinceMs > 0 {
+		q.Since = time.UnixMilli(sinceMs)
+	}
+	if untilMs > 0 {
+		q.Until = time.UnixMilli(untilMs)
+	}
+	recs, err := a.history.Query(q)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]UIHistoryRecord, 0, len(recs))
+	for _, r := range recs {
+		out = append(out, toUIHistoryRecord(r))
+	}
+	return out, nil
+}
+
+// GetHistory loads a single saved map run by id.
+func (a *App) GetHistory(id string) (UIHistoryRecord, error) {
+	if a.history == nil {
+		return UIHistoryRecord{}, history.ErrNotFound
+	}
+	r, err := a.history.Get(id)
+	if err != nil {
+		return UIHistoryRecord{}, err
+	}
+	return toUIHistoryRecord(r), nil
+}
+
+// DeleteHistory removes a saved map run by id.
+func (a *App) DeleteHistory(id string) error {
+	if a.history == nil {
+		return history.ErrNotFound
+	}
+	return a.history.Delete(id)
+}
+
+func toUIHistoryRecord(r history.Record) UIHistoryRecord {
+	tally := make(map[string]int, len(r.Tally))
+	for id, n := range r.Tally {
+		tally[intToStr(id)] = n
+	}
+	prices := make(map[string]float64, len(r.Prices))
+	for id, p := range r.Prices {
+		prices[intToStr(id)] = p
+	}
+	return UIHistoryRecord{
+		ID:           r.ID,
+		SessionStart: r.SessionStartedAt.UnixMilli(),
+		MapKey:       r.MapKey,
+		Region:       r.Region,
+		Start:        r.StartedAt.UnixMilli(),
+		End:          r.EndedAt.UnixMilli(),
+		DurationMs:   r.Duration().Milliseconds(),
+		Earnings:     r.Earnings(),
+		Cost:         r.Cost(),
+		Tally:        tally,
+		Prices:       prices,
+	}
+}
+
+// priceOf returns the current unit price for an item id, or 0 if unknown.
+func (a *App) priceOf(id int) float64 {
+	if a.items == nil {
+		return 0
+	}
+	return a.items.PriceOf(id)
+}
+
+// ItemInfo represents an item entry from full_table.json
+type ItemInfo = items.Item
+
 // UITallyItem is sent to the frontend for each counted item id
 type UITallyItem struct {
-	Name       string  `json:"name"`
-	Type       string  `json:"type"`
-	Price      float64 `json:"price"`
-	LastUpdate float64 `json:"last_update"`
-	From       string  `json:"from"`
-	Count      int     `json:"count"`
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Price       float64 `json:"price"`
+	LastUpdate  float64 `json:"last_update"`
+	From        string  `json:"from"`
+	Count       int     `json:"count"`
+	PriceStatus string  `json:"priceStatus"` // fresh, stale or unknown, from last_update
 }
 
 type UIMap struct {
-	Start      int64   `json:"start"`
-	End        int64   `json:"end"`
-	DurationMs int64   `json:"durationMs"`
-	Earnings   float64 `json:"earnings"`
+	Start          int64   `json:"start"`
+	End            int64   `json:"end"`
+	DurationMs     int64   `json:"durationMs"`
+	Earnings       float64 `json:"earnings"`       // gross, at current prices
+	EarningsAtDrop float64 `json:"earningsAtDrop"` // gross, at the prices locked in when each item dropped
+	Cost           float64 `json:"cost"`           // items consumed by the map (entry costs included), at current prices
+	CostAtDrop     float64 `json:"costAtDrop"`     // the same items at the prices in effect when they were consumed
+	Net            float64 `json:"net"`            // earnings - cost
+	MapKey         string  `json:"mapKey"`
+	Region         string  `json:"region"`
 }
 
 type UIState struct {
@@ -381,6 +789,98 @@ type UIState struct {
 	EarningsPerSession float64                `json:"earningsPerSession"`
 	EarningsPerHour    float64                `json:"earningsPerHour"`
 	AvgMapTimeMs       int64                  `json:"avgMapTimeMs"`
+	PriceStatus        string                 `json:"priceStatus"` // worst price status over the tally
+	// Session earnings valued with the prices locked in when each item dropped
+	EarningsPerSessionAtDrop float64 `json:"earningsPerSessionAtDrop"`
+	EarningsPerHourAtDrop    float64 `json:"earningsPerHourAtDrop"`
+	// Items consumed by the session's maps and the resulting net earnings (gross is EarningsPerSession)
+	CostPerSession           float64 `json:"costPerSession"`
+	NetPerSession            float64 `json:"netPerSession"`
+	NetEarningsPerHour       float64 `json:"netEarningsPerHour"`
+	NetEarningsPerHourAtDrop float64 `json:"netEarningsPerHourAtDrop"`
+	// Session time split by activity; the active rates divide by in-map + town time instead of wall time
+	InMapTimeMs              int64   `json:"inMapTimeMs"`
+	TownTimeMs               int64   `json:"townTimeMs"`
+	IdleTimeMs               int64   `json:"idleTimeMs"`
+	ActiveEarningsPerHour    float64 `json:"activeEarningsPerHour"`
+	ActiveNetEarningsPerHour float64 `json:"activeNetEarningsPerHour"`
+	// Pause state and the paused intervals on the session timeline, excluded from durations and rates
+	Paused       bool         `json:"paused"`
+	PausedTimeMs int64        `json:"pausedTimeMs"`
+	Pauses       []UIInterval `json:"pauses"`
+	// Session goals and their progress (filled by App.UIState)
+	Goals []UIGoal `json:"goals"`
+}
+
+// UIGoal is a session goal with its progress
+type UIGoal struct {
+	ID      string  `json:"id"`
+	Kind    string  `json:"kind"` // earnings, item or maps
+	ItemID  string  `json:"itemId,omitempty"`
+	Label   string  `json:"label"`
+	Target  float64 `json:"target"`
+	Current float64 `json:"current"`
+	Percent float64 `json:"percent"`
+	Done    bool    `json:"done"`
+	DoneAt  int64   `json:"doneAt"` // epoch ms when first met, 0 if not yet
+}
+
+// UIInterval is a span of the session timeline in epoch ms; End is 0 while open
+type UIInterval struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// UIMapStats is sent to the frontend for each map key with completed runs
+type UIMapStats struct {
+	MapKey           string             `json:"mapKey"`
+	Region           string             `json:"region"`
+	Runs             int                `json:"runs"`
+	MeanDurationMs   int64              `json:"meanDurationMs"`
+	MedianDurationMs int64              `json:"medianDurationMs"`
+	P90DurationMs    int64              `json:"p90DurationMs"`
+	MeanEarnings     float64            `json:"meanEarnings"`
+	EarningsPerHour  float64            `json:"earningsPerHour"`
+	DropRate         map[string]float64 `json:"dropRate"` // item id -> average count per run
+	// Earnings valued with the prices locked in when each item dropped
+	MeanEarningsAtDrop    float64 `json:"meanEarningsAtDrop"`
+	EarningsPerHourAtDrop float64 `json:"earningsPerHourAtDrop"`
+	// Items consumed per run and the resulting net earnings, at current prices
+	MeanCost           float64 `json:"meanCost"`
+	NetEarningsPerHour float64 `json:"netEarningsPerHour"`
+}
+
+// UIHistoryRecord is a saved map run sent to the frontend
+type UIHistoryRecord struct {
+	ID           string             `json:"id"`
+	SessionStart int64              `json:"sessionStart"`
+	MapKey       string             `json:"mapKey"`
+	Region       string             `json:"region"`
+	Start        int64              `json:"start"`
+	End          int64              `json:"end"`
+	DurationMs   int64              `json:"durationMs"`
+	Earnings     float64            `json:"earnings"` // valued with the saved prices
+	Cost         float64            `json:"cost"`     // consumed items, valued with the saved prices
+	Tally        map[string]int     `json:"tally"`
+	Prices       map[string]float64 `json:"prices"`
+}
+
+// UIPricePoint is one recorded price of an item sent to the frontend
+type UIPricePoint struct {
+	Time   int64   `json:"time"` // epoch ms of the price's last_update (or fetch time)
+	Price  float64 `json:"price"`
+	Source string  `json:"source"`
+}
+
+// UIPriceChange is an item's price movement over a window
+type UIPriceChange struct {
+	ItemID   string  `json:"itemId"`
+	Name     string  `json:"name"`
+	From     float64 `json:"from"`
+	To       float64 `json:"to"`
+	FromTime int64   `json:"fromTime"`
+	ToTime   int64   `json:"toTime"`
+	Percent  float64 `json:"percent"`
 }
 
 type UIEvent struct {
@@ -392,7 +892,10 @@ type UIEvent struct {
 func (a *App) ItemTableSource() (string, int) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	return a.itemsSource, len(a.items)
+	if a.items == nil {
+		return "none", 0
+	}
+	return a.items.Source(), a.items.Len()
 }
 
 func intToStr(n int) string {
@@ -425,37 +928,50 @@ func strconvItoa(n int) string {
 	return string(buf[i:])
 }
 
-// refreshPrices fetches remote pricing and merges price + last_update into the in-memory items.
+// refreshPrices fetches prices from the configured providers and merges price + last_update into the in-memory items.
 func (a *App) refreshPrices() {
 	if a.ctx == nil || a.items == nil {
 		return
 	}
+	cfg, err := priceConfig()
+	if err != nil {
+		if a.isWailsContext() {
+			runtime.LogWarningf(a.ctx, "price sources: %v", err)
+		}
+		return
+	}
+	cfg.OnError = func(provider string, err error) {
+		if a.isWailsContext() {
+			runtime.LogWarningf(a.ctx, "price provider %s failed: %v", provider, err)
+		}
+	}
+	prov, err := cfg.Build()
+	if err != nil {
+		if a.isWailsContext() {
+			runtime.LogWarningf(a.ctx, "price sources: %v", err)
+		}
+		return
+	}
 	ctx, cancel := context.WithTimeout(a.ctx, 5*time.Second)
 	defer cancel()
-	updates, err := pricing.FetchRemotePrices(ctx, "")
+	updates, err := prov.Prices(ctx)
 	if err != nil {
 		if a.isWailsContext() {
 			runtime.LogWarningf(a.ctx, "price refresh failed: %v", err)
 		}
-		return
+		// cached prices are still better than the ones in the item table
+		if !errors.Is(err, pricing.ErrStale) {
+			return
+		}
 	}
-	var changed int
-	var total int
-	a.mu.Lock()
-	for id, u := range updates {
-		total++
-		if info, ok := a.items[id]; ok {
-			if info.Price != u.Price || info.LastUpdate != u.LastUpdate {
-				info.Price = u.Price
-				info.LastUpdate = u.LastUpdate
-				a.items[id] = info
-				changed++
-			}
+	changed, total := a.items.ApplyPrices(updates)
+	if a.priceHistory != nil {
+		if _, err := a.priceHistory.Record(updates, time.Now(), prov.Name()); err != nil && a.isWailsContext() {
+			runtime.LogWarningf(a.ctx, "price history: %v", err)
 		}
 	}
-	a.mu.Unlock()
 	if a.isWailsContext() {
-		runtime.LogInfof(a.ctx, "price refresh: %d updated (from %d remote items)", changed, total)
+		runtime.LogInfof(a.ctx, "price refresh: %d updated (from %d items via %s)", changed, total, prov.Name())
 	}
 }
 
@@ -2,15 +2,28 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"GoTorch/internal/items"
+	"GoTorch/internal/types"
+	"GoTorch/internal/webhook"
 )
 
 func TestAppStartTrackingFromStartCountsDeltas(t *testing.T) {
 	// Prepare a temp log with a MapStart and a bag delta during the map
 	dir := t.TempDir()
+	t.Setenv("GOTORCH_RESUME", filepath.Join(dir, "resume.json"))
+	t.Setenv("GOTORCH_HISTORY", filepath.Join(dir, "history.jsonl"))
 	p := filepath.Join(dir, "ue.log")
 	lines := "" +
 		"[2025.11.04-19.20.45:474][302]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = /Game/Art/Maps/UI/LoginScene/LoginScene NextSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200'\n" +
@@ -26,9 +39,9 @@ func TestAppStartTrackingFromStartCountsDeltas(t *testing.T) {
 	defer a.Stop()
 
 	// Provide minimal item metadata for item 1001
-	a.items = map[string]ItemInfo{
+	a.items = items.New(map[string]ItemInfo{
 		"1001": {Name: "Test Item", Type: "test", Price: 1.0},
-	}
+	}, "test")
 
 	if err := a.StartTrackingWithOptions(p, true); err != nil {
 		t.Fatalf("StartTrackingWithOptions: %v", err)
@@ -45,6 +58,12 @@ func TestAppStartTrackingFromStartCountsDeltas(t *testing.T) {
 	if st.Tally["1001"].Count != 4 {
 		t.Fatalf("expected tally[1001].Count=4, got %d", st.Tally["1001"].Count)
 	}
+	if d := a.Diagnostics(); !d.Tailing || d.LinesRead != 3 || d.LinesSent != 3 || d.BytesRead != uint64(len(lines)) || d.Overflow != "block" {
+		t.Fatalf("unexpected diagnostics %+v", d)
+	}
+	if s := a.TailerStats(); !s.Tailing || s.Health != "following" || s.Offset != int64(len(lines)) || s.Size != s.Offset || s.LastDataAt == 0 {
+		t.Fatalf("unexpected tailer stats %+v", s)
+	}
 
 	// Reset clears state
 	a.Reset()
@@ -54,6 +73,46 @@ func TestAppStartTrackingFromStartCountsDeltas(t *testing.T) {
 	}
 }
 
+func TestAppStartTrackingPathsMergesLogsByTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GOTORCH_RESUME", filepath.Join(dir, "resume.json"))
+	t.Setenv("GOTORCH_HISTORY", filepath.Join(dir, "history.jsonl"))
+	// the pickup is in the file listed first but happens last
+	pickup := filepath.Join(dir, "steam.log")
+	game := filepath.Join(dir, "standalone.log")
+	logs := map[string]string{
+		pickup: "[2025.11.04-19.20.47:000][302]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = 4\n",
+		game: "[2025.11.04-19.20.45:474][302]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = /Game/Art/Maps/UI/LoginScene/LoginScene NextSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200'\n" +
+			"[2025.11.04-19.20.46:000][302]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = 0\n",
+	}
+	for p, data := range logs {
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatalf("write log: %v", err)
+		}
+	}
+
+	a := New()
+	a.Startup(context.Background())
+	defer a.Stop()
+	if err := a.StartTrackingPaths([]string{pickup, game}, true); err != nil {
+		t.Fatalf("StartTrackingPaths: %v", err)
+	}
+	time.Sleep(900 * time.Millisecond)
+	if st := a.UIState(); !st.InMap || st.TotalDrops != 4 {
+		t.Fatalf("expected 4 drops in the map, got in map %v, drops %d", st.InMap, st.TotalDrops)
+	}
+	if d := a.Diagnostics(); !d.Tailing || d.LinesSent != 3 {
+		t.Fatalf("unexpected diagnostics %+v", d)
+	}
+	s := a.TailerStats()
+	if len(s.Sources) != 2 || s.Path != pickup || s.Health != "following" {
+		t.Fatalf("unexpected tailer stats %+v", s)
+	}
+	if err := a.StartTrackingPaths(nil, false); err == nil {
+		t.Fatal("expected an error without paths")
+	}
+}
+
 func TestAppStopResetIdempotent(t *testing.T) {
 	a := New()
 	a.Startup(context.Background())
@@ -63,3 +122,244 @@ func TestAppStopResetIdempotent(t *testing.T) {
 	a.Reset()
 	a.Reset()
 }
+
+func TestAppResetWhileReading(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GOTORCH_RESUME", filepath.Join(dir, "resume.json"))
+	t.Setenv("GOTORCH_HISTORY", filepath.Join(dir, "history.jsonl"))
+	p := filepath.Join(dir, "ue.log")
+	if err := os.WriteFile(p, nil, 0o644); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+	a := New()
+	a.Startup(context.Background())
+	defer a.Stop()
+	if err := a.StartTracking(p); err != nil {
+		t.Fatalf("StartTracking: %v", err)
+	}
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			a.Reset()
+			_ = a.UIState()
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		_, _ = f.WriteString("[2025.11.04-19.20.47:000][302]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = " + strconv.Itoa(i+1) + "\n")
+		time.Sleep(10 * time.Millisecond)
+	}
+	wg.Wait()
+}
+
+func TestAppPauseResume(t *testing.T) {
+	a := New()
+	start := time.Now().Add(-time.Minute)
+	a.trk.OnEvent(&types.Event{Kind: types.EventBagInit, Time: start, Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 1001, Num: 0}})
+	a.trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start})
+	if !a.Pause() || a.Pause() {
+		t.Fatalf("expected only the first Pause to succeed")
+	}
+	a.trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: time.Now(), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 1001, Num: 3}})
+	st := a.UIState()
+	if !st.Paused || st.TotalDrops != 0 || len(st.Pauses) != 1 || st.Pauses[0].End != 0 {
+		t.Fatalf("unexpected paused state: paused=%v drops=%d pauses=%+v", st.Paused, st.TotalDrops, st.Pauses)
+	}
+	if !a.Resume() || a.Resume() {
+		t.Fatalf("expected only the first Resume to succeed")
+	}
+	a.trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: time.Now(), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 1001, Num: 4}})
+	st = a.UIState()
+	if st.Paused || st.TotalDrops != 1 || st.Pauses[0].End == 0 {
+		t.Fatalf("unexpected resumed state: paused=%v drops=%d pauses=%+v", st.Paused, st.TotalDrops, st.Pauses)
+	}
+}
+
+func TestAppGoals(t *testing.T) {
+	a := New()
+	a.items = items.New(map[string]ItemInfo{"1001": {Name: "Test Item", Price: 5}}, "test")
+	if _, err := a.AddGoal("maps:-2"); err == nil {
+		t.Fatalf("expected an invalid goal to be rejected")
+	}
+	g, err := a.AddGoal("item:Test Item:2")
+	if err != nil || g.ItemID != "1001" || g.Label != "Collect 2 Test Item" {
+		t.Fatalf("AddGoal = %+v, %v", g, err)
+	}
+	start := time.Now().Add(-time.Minute)
+	a.trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start})
+	a.trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start, Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 1001, Num: 1}})
+	if got := a.Goals(); len(got) != 1 || got[0].Current != 1 || got[0].Percent != 50 || got[0].Done {
+		t.Fatalf("unexpected progress %+v", got)
+	}
+	a.trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start.Add(time.Second), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 1001, Num: 2}})
+	if got := a.UIState().Goals; !got[0].Done || got[0].DoneAt == 0 {
+		t.Fatalf("expected goal met, got %+v", got)
+	}
+	a.Reset()
+	if got := a.Goals(); len(got) != 1 || got[0].Done {
+		t.Fatalf("expected goal kept but not met after Reset, got %+v", got)
+	}
+	if !a.RemoveGoal(g.ID) || len(a.Goals()) != 0 {
+		t.Fatalf("expected goal removed")
+	}
+}
+
+func TestAppDropAlertWebhook(t *testing.T) {
+	posted := make(chan webhook.Event, 1)
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first attempt fails and is retried
+		if calls++; calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var ev webhook.Event
+		json.NewDecoder(r.Body).Decode(&ev)
+		posted <- ev
+	}))
+	defer srv.Close()
+	rules := `[{"name":"valuable","min_unit_value":100,"cooldown_seconds":60,"webhook":"` + srv.URL + `"}]`
+	path := filepath.Join(t.TempDir(), "alert_rules.json")
+	if err := os.WriteFile(path, []byte(rules), 0o644); err != nil {
+		t.Fatalf("write rules: %v", err)
+	}
+	t.Setenv("GOTORCH_ALERT_RULES", path)
+
+	a := New()
+	a.loadAlertRules()
+	defer a.closeWebhooks()
+	if got := a.AlertRules(); len(got) != 1 || got[0].Name != "valuable" {
+		t.Fatalf("AlertRules = %+v", got)
+	}
+	a.items = items.New(map[string]ItemInfo{"1001": {Name: "Cheap"}, "2002": {Name: "Slate", Price: 150}}, "test")
+	start := time.Now().Add(-time.Minute)
+	bag := func(d time.Duration, id, num int) *types.Event {
+		return &types.Event{Kind: types.EventBagMod, Time: start.Add(d), Bag: &types.BagEvent{PageID: 1, SlotID: id, ConfigBaseID: id, Num: num}}
+	}
+	a.trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start})
+	a.trk.OnEvent(bag(time.Second, 1001, 5))
+	a.trk.OnEvent(bag(2*time.Second, 2002, 1))
+	a.trk.OnEvent(bag(3*time.Second, 2002, 2)) // same rule within its cooldown
+	a.trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(10 * time.Second)})
+
+	select {
+	case ev := <-posted:
+		al := ev.Alert
+		if ev.Type != webhook.DropAlert || al == nil || al.Rule != "valuable" || al.ItemID != 2002 || al.Name != "Slate" || al.Total != 150 || ev.Text != "Slate x1 worth 150.00" {
+			t.Fatalf("unexpected webhook alert %+v", ev)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("webhook not called")
+	}
+	select {
+	case ev := <-posted:
+		t.Fatalf("expected the second drop to be held by the cooldown, got %+v", ev)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestAppDropAlertWithoutFurtherLines(t *testing.T) {
+	posted := make(chan webhook.Event, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev webhook.Event
+		json.NewDecoder(r.Body).Decode(&ev)
+		posted <- ev
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+	rules := `[{"name":"valuable","min_unit_value":100,"webhook":"` + srv.URL + `"}]`
+	if err := os.WriteFile(filepath.Join(dir, "alert_rules.json"), []byte(rules), 0o644); err != nil {
+		t.Fatalf("write rules: %v", err)
+	}
+	t.Setenv("GOTORCH_ALERT_RULES", filepath.Join(dir, "alert_rules.json"))
+	t.Setenv("GOTORCH_RESUME", filepath.Join(dir, "resume.json"))
+	t.Setenv("GOTORCH_HISTORY", filepath.Join(dir, "history.jsonl"))
+	p := filepath.Join(dir, "ue.log")
+	// the drop is the last line of the log
+	lines := "" +
+		"[2025.11.04-19.20.45:474][302]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = /Game/Art/Maps/UI/LoginScene/LoginScene NextSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200'\n" +
+		"[2025.11.04-19.20.46:000][302]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 1 SlotId = 1 ConfigBaseId = 2002 Num = 0\n" +
+		"[2025.11.04-19.20.47:000][302]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 1 SlotId = 1 ConfigBaseId = 2002 Num = 1\n"
+	if err := os.WriteFile(p, []byte(lines), 0o644); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+
+	a := New()
+	a.Startup(context.Background())
+	defer a.Stop()
+	a.items = items.New(map[string]ItemInfo{"2002": {Name: "Slate", Price: 150}}, "test")
+	if err := a.StartTrackingWithOptions(p, true); err != nil {
+		t.Fatalf("StartTrackingWithOptions: %v", err)
+	}
+	select {
+	case ev := <-posted:
+		if ev.Type != webhook.DropAlert || ev.Alert == nil || ev.Alert.ItemID != 2002 {
+			t.Fatalf("unexpected webhook alert %+v", ev)
+		}
+	case <-time.After(6 * time.Second):
+		t.Fatal("drop alert not sent while the log stayed quiet")
+	}
+}
+
+func TestAppWebhooks(t *testing.T) {
+	var mu sync.Mutex
+	bodies := map[string][]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies[r.URL.Path] = append(bodies[r.URL.Path], string(b))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	cfg := `[{"name":"chat","url":"` + srv.URL + `/discord","preset":"discord","events":["map_end"]},
+		{"name":"all","url":"` + srv.URL + `/all"}]`
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("GOTORCH_WEBHOOKS", path)
+
+	a := New()
+	a.loadWebhooks()
+	a.items = items.New(map[string]ItemInfo{"1001": {Name: "Ember", Price: 10}}, "test")
+	a.trk = a.newTracker()
+	if _, err := a.AddGoal("maps:1"); err != nil {
+		t.Fatalf("AddGoal: %v", err)
+	}
+	start := time.Now().Add(-10 * time.Minute)
+	a.trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start, Scene: &types.SceneEvent{MapKey: "YJ_A"}})
+	a.trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start.Add(time.Minute), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 1001, Num: 3}})
+	a.trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(4 * time.Minute)})
+	a.UIState()
+	a.Stop()
+	a.Stop() // the session is reported once
+	a.closeWebhooks()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := `{"username":"GoTorch","content":"Map YJ_A finished in 4m0s: 3 drops, earnings 30.00"}`
+	if got := bodies["/discord"]; len(got) != 1 || got[0] != want {
+		t.Fatalf("discord bodies = %q", got)
+	}
+	var kinds []string
+	for _, b := range bodies["/all"] {
+		var ev webhook.Event
+		if err := json.Unmarshal([]byte(b), &ev); err != nil {
+			t.Fatalf("decode %s: %v", b, err)
+		}
+		kinds = append(kinds, ev.Type)
+		if ev.Type == webhook.SessionEnd && (ev.Session.Maps != 1 || ev.Session.Earnings != 30) {
+			t.Fatalf("unexpected session summary %+v", ev.Session)
+		}
+	}
+	if strings.Join(kinds, ",") != "map_start,map_end,goal_met,session_end" {
+		t.Fatalf("unexpected events %v", kinds)
+	}
+}
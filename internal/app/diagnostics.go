@@ -0,0 +1,147 @@
+package app
+
+import (
+	"os"
+
+	"GoTorch/internal/tailer"
+)
+
+// UIDiagnostics shows what the log tailer is doing, for troubleshooting missing or late data.
+type UIDiagnostics struct {
+	Tailing      bool   `json:"tailing"`
+	Watching     bool   `json:"watching"` // woken by file change notifications rather than polling
+	Overflow     string `json:"overflow"` // block, drop-oldest or spill
+	LinesRead    uint64 `json:"linesRead"`
+	BytesRead    uint64 `json:"bytesRead"`
+	LinesSent    uint64 `json:"linesSent"`
+	LinesDropped uint64 `json:"linesDropped"`
+	LinesSpilled uint64 `json:"linesSpilled"`
+	Queued       int    `json:"queued"`
+}
+
+// UITailerStats shows where the tailer is in the log and whether the log is still growing, so a
+// game that stopped logging can be told apart from a quiet session.
+type UITailerStats struct {
+	Tailing    bool   `json:"tailing"`
+	Path       string `json:"path"`
+	Health     string `json:"health"` // waiting, following, rotated or errored
+	Error      string `json:"error,omitempty"`
+	Waiting    bool   `json:"waiting"` // the log file does not exist (yet)
+	Offset     int64  `json:"offset"`
+	Size       int64  `json:"size"`
+	LastDataAt int64  `json:"lastDataAt"` // unix ms of the last data read, 0 before any
+	Rotations  int    `json:"rotations"`
+	// Sources lists every file when several are followed; the fields above then describe
+	// the one written last, except Rotations, which is the total.
+	Sources []UITailerStats `json:"sources,omitempty"`
+}
+
+// tailSource is a tailer.Tailer or a tailer.Multi.
+type tailSource interface {
+	Counters() tailer.Counters
+	Watching() bool
+	Options() tailer.Options
+}
+
+// tailOverflow returns the tailer overflow policy from GOTORCH_TAIL_OVERFLOW (block, drop-oldest
+// or spill), or tailer.OverflowBlock when unset or invalid.
+func tailOverflow() tailer.Overflow {
+	o, err := tailer.ParseOverflow(os.Getenv("GOTORCH_TAIL_OVERFLOW"))
+	if err != nil {
+		return tailer.OverflowBlock
+	}
+	return o
+}
+
+// Diagnostics returns the tailer counters of the current tracking run.
+func (a *App) Diagnostics() UIDiagnostics {
+	a.mu.Lock()
+	var src tailSource
+	if a.t != nil {
+		src = a.t
+	} else if a.multi != nil {
+		src = a.multi
+	}
+	tailing := a.cancel != nil
+	a.mu.Unlock()
+	if src == nil {
+		return UIDiagnostics{Overflow: tailOverflow().String()}
+	}
+	d := ToUIDiagnostics(src)
+	d.Tailing = tailing
+	return d
+}
+
+// TailerStats returns the position and health of the log tailer.
+func (a *App) TailerStats() UITailerStats {
+	a.mu.Lock()
+	t, m, tailing := a.t, a.multi, a.cancel != nil
+	a.mu.Unlock()
+	var s UITailerStats
+	switch {
+	case t != nil:
+		s = ToUITailerStats(t)
+	case m != nil:
+		s = ToUIMultiStats(m)
+	default:
+		return UITailerStats{Health: tailer.HealthWaiting.String(), Waiting: true}
+	}
+	s.Tailing = tailing
+	return s
+}
+
+// ToUITailerStats converts a tailer's stats to the UI schema.
+func ToUITailerStats(t *tailer.Tailer) UITailerStats {
+	return toUITailerStats(t.Stats())
+}
+
+// ToUIMultiStats summarizes the files of a multi-file tailer.
+func ToUIMultiStats(m *tailer.Multi) UITailerStats {
+	s := UITailerStats{Tailing: true, Health: tailer.HealthWaiting.String(), Waiting: true}
+	var rotations int
+	for i, st := range m.Stats() {
+		u := toUITailerStats(st)
+		s.Sources = append(s.Sources, u)
+		rotations += u.Rotations
+		if i == 0 || u.LastDataAt > s.LastDataAt {
+			sources := s.Sources
+			s = u
+			s.Sources = sources
+		}
+	}
+	s.Rotations = rotations
+	return s
+}
+
+func toUITailerStats(st tailer.Stats) UITailerStats {
+	s := UITailerStats{
+		Tailing:   true,
+		Path:      st.Path,
+		Health:    st.Health.String(),
+		Error:     st.Err,
+		Waiting:   st.Waiting,
+		Offset:    st.Offset,
+		Size:      st.Size,
+		Rotations: st.Rotations,
+	}
+	if !st.LastData.IsZero() {
+		s.LastDataAt = st.LastData.UnixMilli()
+	}
+	return s
+}
+
+// ToUIDiagnostics converts the counters of a tailer.Tailer or tailer.Multi to the UI schema.
+func ToUIDiagnostics(t tailSource) UIDiagnostics {
+	c := t.Counters()
+	return UIDiagnostics{
+		Tailing:      true,
+		Watching:     t.Watching(),
+		Overflow:     t.Options().Overflow.String(),
+		LinesRead:    c.LinesRead,
+		BytesRead:    c.BytesRead,
+		LinesSent:    c.LinesSent,
+		LinesDropped: c.LinesDropped,
+		LinesSpilled: c.LinesSpilled,
+		Queued:       c.Queued,
+	}
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"GoTorch/internal/items"
+	"GoTorch/internal/types"
+)
+
+func TestCompletedMapsPersistAcrossApps(t *testing.T) {
+	t.Setenv("GOTORCH_HISTORY", filepath.Join(t.TempDir(), "history.jsonl"))
+	a := New()
+	a.Startup(context.Background())
+	a.items = items.New(map[string]ItemInfo{"5210": {Name: "Test Item", Price: 2.0}}, "test")
+
+	start := time.Now().Add(-time.Hour)
+	a.trk.OnEvent(&types.Event{Kind: types.EventBagInit, Time: start, Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 5210, Num: 0}})
+	a.trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start, Scene: &types.SceneEvent{MapKey: "YJ_A", Region: "07YJ"}})
+	a.trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start.Add(time.Minute), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 5210, Num: 4}})
+	a.trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(5 * time.Minute)})
+	a.Reset()
+
+	// A new app (e.g. after restart) sees the saved run with the old prices
+	b := New()
+	b.Startup(context.Background())
+	b.items = items.New(map[string]ItemInfo{"5210": {Name: "Test Item", Price: 100.0}}, "test")
+	recs, err := b.ListHistory()
+	if err != nil || len(recs) != 1 {
+		t.Fatalf("expected one saved run, got %d err=%v", len(recs), err)
+	}
+	r := recs[0]
+	if r.MapKey != "YJ_A" || r.Tally["5210"] != 4 || r.Earnings != 8 || r.DurationMs != (5*time.Minute).Milliseconds() {
+		t.Fatalf("unexpected saved run: %+v", r)
+	}
+	if got, err := b.GetHistory(r.ID); err != nil || got.ID != r.ID {
+		t.Fatalf("GetHistory: %+v %v", got, err)
+	}
+	if q, _ := b.QueryHistory("YJ_B", 0, 0); len(q) != 0 {
+		t.Fatalf("expected no YJ_B runs, got %+v", q)
+	}
+	if err := b.DeleteHistory(r.ID); err != nil {
+		t.Fatalf("DeleteHistory: %v", err)
+	}
+	if recs, _ := b.ListHistory(); len(recs) != 0 {
+		t.Fatalf("expected empty history after delete, got %+v", recs)
+	}
+}
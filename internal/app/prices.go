@@ -0,0 +1,140 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"GoTorch/internal/items"
+	"GoTorch/internal/pricehistory"
+	"GoTorch/internal/pricing"
+)
+
+// defaultPriceSources is the provider priority used when GOTORCH_PRICE_SOURCES is unset:
+// user overrides first, then the remote endpoint.
+const defaultPriceSources = "overrides,remote"
+
+// priceConfig describes the price providers from the environment:
+// GOTORCH_PRICE_SOURCES (comma-separated priority list), GOTORCH_PRICE_POLICY,
+// GOTORCH_PRICE_OVERRIDES, GOTORCH_PRICE_FILE, GOTORCH_PRICE_NAMES, GOTORCH_PRICE_CACHE
+// and GOTORCH_OFFLINE (serve remote prices from the cache without touching the network).
+func priceConfig() (pricing.Config, error) {
+	sources := os.Getenv("GOTORCH_PRICE_SOURCES")
+	if sources == "" {
+		sources = defaultPriceSources
+	}
+	policy, err := pricing.ParsePolicy(os.Getenv("GOTORCH_PRICE_POLICY"))
+	if err != nil {
+		return pricing.Config{}, err
+	}
+	return pricing.Config{
+		Sources:       pricing.ParseSources(sources),
+		Policy:        policy,
+		LocalPath:     os.Getenv("GOTORCH_PRICE_FILE"),
+		NamesPath:     os.Getenv("GOTORCH_PRICE_NAMES"),
+		OverridesPath: defaultOverridesPath(),
+		CachePath:     defaultPriceCachePath(),
+		Offline:       offline(),
+		Embedded:      items.EmbeddedPrices(),
+	}, nil
+}
+
+// defaultOverridesPath returns the user price overrides file, honoring GOTORCH_PRICE_OVERRIDES.
+func defaultOverridesPath() string {
+	if p := os.Getenv("GOTORCH_PRICE_OVERRIDES"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "gotorch_price_overrides.json"
+	}
+	return filepath.Join(dir, "GoTorch", "price_overrides.json")
+}
+
+// defaultPriceCachePath returns the remote price cache file, honoring GOTORCH_PRICE_CACHE.
+func defaultPriceCachePath() string {
+	if p := os.Getenv("GOTORCH_PRICE_CACHE"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "gotorch_price_cache.json"
+	}
+	return filepath.Join(dir, "GoTorch", "price_cache.json")
+}
+
+// offline reports whether GOTORCH_OFFLINE is set to a true value.
+func offline() bool {
+	v, _ := strconv.ParseBool(os.Getenv("GOTORCH_OFFLINE"))
+	return v
+}
+
+// PriceSeries returns the recorded prices of an item since sinceMs (epoch ms, 0 = all), oldest first.
+func (a *App) PriceSeries(id string, sinceMs int64) ([]UIPricePoint, error) {
+	if a.priceHistory == nil {
+		return []UIPricePoint{}, nil
+	}
+	var since time.Time
+	if sinceMs > 0 {
+		since = time.UnixMilli(sinceMs)
+	}
+	points, err := a.priceHistory.Series(id, since, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]UIPricePoint, 0, len(points))
+	for _, p := range points {
+		out = append(out, UIPricePoint{Time: p.At.UnixMilli(), Price: p.Price, Source: p.Source})
+	}
+	return out, nil
+}
+
+// PriceChange returns an item's price movement over the last windowHours.
+func (a *App) PriceChange(id string, windowHours float64) (UIPriceChange, error) {
+	if a.priceHistory == nil {
+		return UIPriceChange{}, pricehistory.ErrNoData
+	}
+	c, err := a.priceHistory.Change(id, hours(windowHours), time.Now())
+	if err != nil {
+		return UIPriceChange{}, err
+	}
+	return a.toUIPriceChange(c), nil
+}
+
+// PriceMovers returns the top items by absolute price change over the last windowHours (top 0 = all).
+func (a *App) PriceMovers(windowHours float64, top int) ([]UIPriceChange, error) {
+	if a.priceHistory == nil {
+		return []UIPriceChange{}, nil
+	}
+	movers, err := a.priceHistory.Movers(hours(windowHours), time.Now(), top)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]UIPriceChange, 0, len(movers))
+	for _, c := range movers {
+		out = append(out, a.toUIPriceChange(c))
+	}
+	return out, nil
+}
+
+func (a *App) toUIPriceChange(c pricehistory.Change) UIPriceChange {
+	ui := UIPriceChange{
+		ItemID:   c.ItemID,
+		From:     c.From,
+		To:       c.To,
+		FromTime: c.FromAt.UnixMilli(),
+		ToTime:   c.ToAt.UnixMilli(),
+		Percent:  c.Percent,
+	}
+	if a.items != nil {
+		if it, ok := a.items.Get(c.ItemID); ok {
+			ui.Name = it.Name
+		}
+	}
+	return ui
+}
+
+func hours(h float64) time.Duration {
+	return time.Duration(h * float64(time.Hour))
+}
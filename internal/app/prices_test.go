@@ -0,0 +1,68 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"GoTorch/internal/items"
+	"GoTorch/internal/pricehistory"
+	"GoTorch/internal/pricing"
+)
+
+func TestMain(m *testing.M) {
+	// Startup refreshes prices; keep tests off the network and out of the user's config directory.
+	dir, err := os.MkdirTemp("", "gotorch-app")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("GOTORCH_OFFLINE", "1")
+	os.Setenv("GOTORCH_PRICE_CACHE", filepath.Join(dir, "price_cache.json"))
+	os.Setenv("GOTORCH_PRICE_HISTORY", filepath.Join(dir, "price_history.jsonl"))
+	os.Setenv("GOTORCH_PRICE_OVERRIDES", filepath.Join(dir, "price_overrides.json"))
+	os.Setenv("GOTORCH_ALERT_RULES", filepath.Join(dir, "alert_rules.json"))
+	os.Setenv("GOTORCH_WEBHOOKS", filepath.Join(dir, "webhooks.json"))
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestPriceHistoryBindings(t *testing.T) {
+	a := New()
+	a.items = items.New(map[string]ItemInfo{"10": {Name: "Ember", Price: 3}}, "test")
+	a.priceHistory = pricehistory.Open(filepath.Join(t.TempDir(), "prices.jsonl"))
+	now := time.Now()
+	for i, p := range []float64{2, 3} {
+		at := now.Add(time.Duration(i-2) * time.Hour)
+		if _, err := a.priceHistory.Record(map[string]pricing.PriceUpdate{"10": {Price: p, LastUpdate: float64(at.Unix())}}, at, "remote"); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	series, err := a.PriceSeries("10", 0)
+	if err != nil || len(series) != 2 || series[1].Price != 3 || series[0].Source != "remote" {
+		t.Fatalf("unexpected series: %+v, %v", series, err)
+	}
+	c, err := a.PriceChange("10", 24)
+	if err != nil || c.Name != "Ember" || c.Percent != 50 {
+		t.Fatalf("unexpected change: %+v, %v", c, err)
+	}
+	movers, err := a.PriceMovers(24, 5)
+	if err != nil || len(movers) != 1 || movers[0].ItemID != "10" {
+		t.Fatalf("unexpected movers: %+v, %v", movers, err)
+	}
+}
+
+func TestPriceConfigFromEnv(t *testing.T) {
+	t.Setenv("GOTORCH_PRICE_SOURCES", "embedded, remote")
+	t.Setenv("GOTORCH_PRICE_POLICY", "median")
+	cfg, err := priceConfig()
+	if err != nil || len(cfg.Sources) != 2 || cfg.Sources[0] != "embedded" || cfg.Policy != pricing.Median || !cfg.Offline {
+		t.Fatalf("unexpected config: %+v, %v", cfg, err)
+	}
+	t.Setenv("GOTORCH_PRICE_POLICY", "mode")
+	if _, err := priceConfig(); err == nil {
+		t.Fatal("expected error for unknown policy")
+	}
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"GoTorch/internal/tailer"
+	"GoTorch/internal/tracker"
+)
+
+// resumeState is saved periodically while tracking so a restart can continue
+// exactly where the previous run stopped reading the log.
+type resumeState struct {
+	Checkpoint tailer.Checkpoint `json:"checkpoint"`
+	Tracker    json.RawMessage   `json:"tracker"` // tracker.Snapshot matching Checkpoint
+	SavedAt    time.Time         `json:"saved_at"`
+}
+
+// defaultResumePath returns the resume file location, honoring GOTORCH_RESUME.
+func defaultResumePath() string {
+	if p := os.Getenv("GOTORCH_RESUME"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "gotorch_resume.json"
+	}
+	return filepath.Join(dir, "GoTorch", "resume.json")
+}
+
+// loadResume returns the saved tracker and checkpoint for logPath if the checkpoint
+// still matches the file. A rotated or replaced log yields nil.
+func loadResume(path, logPath string) (*tracker.Tracker, *tailer.Checkpoint) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil
+	}
+	var rs resumeState
+	if err := json.Unmarshal(b, &rs); err != nil {
+		return nil, nil
+	}
+	if rs.Checkpoint.Path != logPath || !tailer.ValidCheckpoint(rs.Checkpoint) {
+		return nil, nil
+	}
+	trk, err := tracker.Restore(rs.Tracker)
+	if err != nil {
+		return nil, nil
+	}
+	return trk, &rs.Checkpoint
+}
+
+// saveResume writes the tracker state together with the tailer position after consumed lines.
+func saveResume(path string, t *tailer.Tailer, trk *tracker.Tracker, consumed uint64) error {
+	if path == "" || t == nil || trk == nil {
+		return nil
+	}
+	cp, ok := t.Checkpoint(consumed)
+	if !ok {
+		return nil
+	}
+	snap, err := trk.Snapshot()
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(resumeState{Checkpoint: cp, Tracker: snap, SavedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
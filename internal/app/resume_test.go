@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for condition")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestRestartMidMapResumesFromCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GOTORCH_RESUME", filepath.Join(dir, "resume.json"))
+	t.Setenv("GOTORCH_HISTORY", filepath.Join(dir, "history.jsonl"))
+	p := filepath.Join(dir, "ue.log")
+	lines := "" +
+		"[2025.11.04-19.20.45:474][302]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = /Game/Art/Maps/UI/LoginScene/LoginScene NextSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200'\n" +
+		"[2025.11.04-19.20.46:000][302]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = 0\n" +
+		"[2025.11.04-19.20.47:000][302]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = 4\n"
+	if err := os.WriteFile(p, []byte(lines), 0o644); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+
+	a := New()
+	a.Startup(context.Background())
+	if err := a.StartTrackingWithOptions(p, true); err != nil {
+		t.Fatalf("StartTrackingWithOptions: %v", err)
+	}
+	waitFor(t, func() bool { return a.UIState().TotalDrops == 4 })
+	a.Stop()
+
+	// Drops logged while the app was closed
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	_, _ = f.WriteString("[2025.11.04-19.21.00:000][302]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = 6\n")
+	_ = f.Close()
+
+	b := New()
+	b.Startup(context.Background())
+	defer b.Stop()
+	if err := b.StartTracking(p); err != nil {
+		t.Fatalf("StartTracking: %v", err)
+	}
+	waitFor(t, func() bool { return b.UIState().TotalDrops == 6 })
+	st := b.UIState()
+	if !st.InMap || st.Tally["1001"].Count != 6 {
+		t.Fatalf("expected resumed map with 6 drops, got %+v", st)
+	}
+	// Nothing before the checkpoint is replayed
+	time.Sleep(500 * time.Millisecond)
+	if got := b.UIState().TotalDrops; got != 6 {
+		t.Fatalf("expected no double counting after resume, got %d", got)
+	}
+}
+
+func TestResetDiscardsResumeState(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "resume.json")
+	t.Setenv("GOTORCH_RESUME", path)
+	if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	a := New()
+	a.Startup(context.Background())
+	a.Reset()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected resume file removed, stat err=%v", err)
+	}
+}
+
+func TestRestartAfterMapEndDoesNotRecordItAgain(t *testing.T) {
+	dir := t.TempDir()
+	resume := filepath.Join(dir, "resume.json")
+	t.Setenv("GOTORCH_RESUME", resume)
+	t.Setenv("GOTORCH_HISTORY", filepath.Join(dir, "history.jsonl"))
+	p := filepath.Join(dir, "ue.log")
+	lines := "" +
+		"[2025.11.04-19.20.45:474][302]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = /Game/Art/Maps/UI/LoginScene/LoginScene NextSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200'\n" +
+		"[2025.11.04-19.20.46:000][302]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = 0\n" +
+		"[2025.11.04-19.20.47:000][302]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 1 SlotId = 1 ConfigBaseId = 1001 Num = 4\n" +
+		"[2025.11.04-19.21.45:474][302]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200' NextSceneName = World'/Game/Art/Maps/01SD/XZ_YuJinZhiXiBiNanSuo200/XZ_YuJinZhiXiBiNanSuo200.XZ_YuJinZhiXiBiNanSuo200'\n"
+	if err := os.WriteFile(p, []byte(lines), 0o644); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+
+	a := New()
+	a.Startup(context.Background())
+	if err := a.StartTrackingWithOptions(p, true); err != nil {
+		t.Fatalf("StartTrackingWithOptions: %v", err)
+	}
+	// The checkpoint is written as soon as the map ends, well before the periodic save.
+	var saved []byte
+	waitFor(t, func() bool {
+		saved, _ = os.ReadFile(resume)
+		return len(saved) > 0
+	})
+	a.Stop()
+	// Simulate a crash right after the map ended: only that checkpoint survives.
+	if err := os.WriteFile(resume, saved, 0o644); err != nil {
+		t.Fatalf("write resume: %v", err)
+	}
+
+	b := New()
+	b.Startup(context.Background())
+	defer b.Stop()
+	if err := b.StartTracking(p); err != nil {
+		t.Fatalf("StartTracking: %v", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	recs, err := b.ListHistory()
+	if err != nil {
+		t.Fatalf("ListHistory: %v", err)
+	}
+	if len(recs) != 1 {
+		t.Fatalf("expected the map recorded once, got %d records", len(recs))
+	}
+}
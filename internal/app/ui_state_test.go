@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 
+	"GoTorch/internal/items"
+	"GoTorch/internal/pricing"
 	"GoTorch/internal/tracker"
 	"GoTorch/internal/types"
 )
@@ -11,12 +13,12 @@ import (
 func TestUIStateConversion(t *testing.T) {
 	a := New()
 	// Provide minimal item metadata for item 5210
-	a.items = map[string]ItemInfo{
+	a.items = items.New(map[string]ItemInfo{
 		"5210": {Name: "Test Item", Type: "test", Price: 1.0},
-	}
+	}, "test")
 	// build some state via tracker events
 	start := time.Now().Add(-5 * time.Second)
-	a.trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start})
+	a.trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start, Scene: &types.SceneEvent{MapKey: "YJ_A", Region: "07YJ"}})
 	a.trk.OnEvent(&types.Event{Kind: types.EventBagInit, Time: start, Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 5210, Num: 1}})
 	// +2 during map
 	a.trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start.Add(time.Second), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 5210, Num: 3}})
@@ -33,6 +35,9 @@ func TestUIStateConversion(t *testing.T) {
 	if st.SessionStart == 0 {
 		t.Fatalf("expected non-zero session start")
 	}
+	if len(st.Maps) != 1 || st.Maps[0].MapKey != "YJ_A" || st.Maps[0].Region != "07YJ" {
+		t.Fatalf("expected current map identity in maps, got %+v", st.Maps)
+	}
 	if len(st.Recent) == 0 {
 		t.Fatalf("expected some recent events")
 	}
@@ -54,3 +59,110 @@ func TestUIStateConversion(t *testing.T) {
 		t.Fatalf("GetState and UIState should agree")
 	}
 }
+
+func TestMapStatsGroupsCompletedMaps(t *testing.T) {
+	a := New()
+	a.items = items.New(map[string]ItemInfo{"5210": {Name: "Test Item", Price: 2.0}}, "test")
+	start := time.Now().Add(-time.Hour)
+	a.trk.OnEvent(&types.Event{Kind: types.EventBagInit, Time: start, Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 5210, Num: 0}})
+	for i := 0; i < 2; i++ {
+		s := start.Add(time.Duration(i) * 10 * time.Minute)
+		a.trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: s, Scene: &types.SceneEvent{MapKey: "YJ_A", Region: "07YJ"}})
+		a.trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: s.Add(time.Minute), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 5210, Num: (i + 1) * 3}})
+		a.trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: s.Add(5 * time.Minute)})
+	}
+	got := a.MapStats()
+	if len(got) != 1 {
+		t.Fatalf("expected one map group, got %+v", got)
+	}
+	m := got[0]
+	if m.MapKey != "YJ_A" || m.Runs != 2 || m.MeanDurationMs != (5*time.Minute).Milliseconds() {
+		t.Fatalf("unexpected map stats: %+v", m)
+	}
+	if m.MeanEarnings != 6 || m.DropRate["5210"] != 3 {
+		t.Fatalf("unexpected earnings/drop rate: %+v", m)
+	}
+}
+
+func TestUIStatePriceStatus(t *testing.T) {
+	now := time.Now()
+	cat := items.New(map[string]ItemInfo{
+		"1": {Name: "Fresh", Price: 1, LastUpdate: float64(now.Add(-time.Hour).Unix())},
+		"2": {Name: "Old", Price: 1, LastUpdate: float64(now.Add(-72 * time.Hour).Unix())},
+	}, "test")
+	trk := tracker.New()
+	trk.OnEvent(&types.Event{Kind: types.EventBagInit, Time: now, Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 1, Num: 0}})
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: now})
+	trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: now, Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 1, Num: 2}})
+
+	ui := BuildUIState(trk.GetState(), cat, now)
+	if ui.Tally["1"].PriceStatus != "fresh" || ui.PriceStatus != "fresh" {
+		t.Fatalf("expected fresh prices, got %+v / %q", ui.Tally["1"], ui.PriceStatus)
+	}
+	trk.OnEvent(&types.Event{Kind: types.EventBagInit, Time: now, Bag: &types.BagEvent{PageID: 1, SlotID: 2, ConfigBaseID: 2, Num: 0}})
+	trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: now, Bag: &types.BagEvent{PageID: 1, SlotID: 2, ConfigBaseID: 2, Num: 1}})
+	trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: now, Bag: &types.BagEvent{PageID: 1, SlotID: 3, ConfigBaseID: 99, Num: 1}})
+	ui = BuildUIState(trk.GetState(), cat, now)
+	if ui.Tally["2"].PriceStatus != "stale" || ui.Tally["99"].PriceStatus != "unknown" || ui.PriceStatus != "stale" {
+		t.Fatalf("expected stale overall, got %+v / %q", ui.Tally, ui.PriceStatus)
+	}
+}
+
+func TestEarningsAtDropSurvivePriceRefresh(t *testing.T) {
+	a := New()
+	a.items = items.New(map[string]ItemInfo{"5210": {Name: "Test Item", Price: 2.0}}, "test")
+	start := time.Now().Add(-time.Hour)
+	a.trk.OnEvent(&types.Event{Kind: types.EventBagInit, Time: start, Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 5210, Num: 0}})
+	a.trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start})
+	a.trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start.Add(time.Minute), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 5210, Num: 3}})
+	a.trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(5 * time.Minute)})
+
+	a.items.ApplyPrices(map[string]pricing.PriceUpdate{"5210": {Price: 10}})
+	ui := a.UIState()
+	if ui.Maps[0].Earnings != 30 || ui.Maps[0].EarningsAtDrop != 6 {
+		t.Fatalf("expected current 30 and at-drop 6, got %+v", ui.Maps[0])
+	}
+	if ui.EarningsPerSessionAtDrop >= ui.EarningsPerSession {
+		t.Fatalf("session at-drop %.2f should be below current %.2f", ui.EarningsPerSessionAtDrop, ui.EarningsPerSession)
+	}
+}
+
+func TestUIStateCostAndNet(t *testing.T) {
+	cat := items.New(map[string]ItemInfo{"5210": {Name: "Drop", Price: 5}, "900": {Name: "Compass", Price: 3}}, "test")
+	trk := tracker.New()
+	start := time.Now().Add(-2 * time.Hour)
+	trk.OnEvent(&types.Event{Kind: types.EventBagInit, Time: start, Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 900, Num: 4}})
+	trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start.Add(-5 * time.Second), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 900, Num: 2}})
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start})
+	trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start.Add(time.Minute), Bag: &types.BagEvent{PageID: 1, SlotID: 2, ConfigBaseID: 5210, Num: 4}})
+	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(time.Hour)})
+
+	ui := BuildUIState(trk.GetState(), cat, start.Add(2*time.Hour))
+	if m := ui.Maps[0]; m.Earnings != 20 || m.Cost != 6 || m.Net != 14 {
+		t.Fatalf("unexpected map values: %+v", m)
+	}
+	if ui.CostPerSession != 6 || ui.NetPerSession != 14 || ui.NetEarningsPerHour != 14 {
+		t.Fatalf("unexpected session values: cost %v net %v net/h %v", ui.CostPerSession, ui.NetPerSession, ui.NetEarningsPerHour)
+	}
+}
+
+func TestUIStateActiveTimeRates(t *testing.T) {
+	cat := items.New(map[string]ItemInfo{"5210": {Name: "Drop", Price: 10}}, "test")
+	trk := tracker.New()
+	trk.SetIdleThreshold(5 * time.Minute)
+	start := time.Now().Add(-2 * time.Hour)
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start})
+	trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start.Add(time.Minute), Bag: &types.BagEvent{PageID: 1, SlotID: 2, ConfigBaseID: 5210, Num: 6}})
+	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(2 * time.Minute)})
+	// an hour AFK in town before the next map
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start.Add(62 * time.Minute)})
+	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(64 * time.Minute)})
+
+	ui := BuildUIState(trk.GetState(), cat, start.Add(64*time.Minute))
+	if ui.InMapTimeMs != (4*time.Minute).Milliseconds() || ui.IdleTimeMs != time.Hour.Milliseconds() || ui.TownTimeMs != 0 {
+		t.Fatalf("unexpected time split: map %d town %d idle %d", ui.InMapTimeMs, ui.TownTimeMs, ui.IdleTimeMs)
+	}
+	if ui.ActiveEarningsPerHour != 900 || ui.ActiveEarningsPerHour <= ui.EarningsPerHour {
+		t.Fatalf("active rate %.1f should be 900 and above wall-clock %.1f", ui.ActiveEarningsPerHour, ui.EarningsPerHour)
+	}
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"sort"
+	"time"
+
+	"GoTorch/internal/history"
+	"GoTorch/internal/tracker"
+	"GoTorch/internal/webhook"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// loadWebhooks starts delivering lifecycle events to the sinks configured in GOTORCH_WEBHOOKS
+// or the default webhooks file. Invalid sinks are logged and skipped.
+func (a *App) loadWebhooks() {
+	cfgs, err := webhook.Load(webhook.DefaultPath())
+	if err != nil && a.isWailsContext() {
+		runtime.LogWarningf(a.ctx, "webhooks not loaded: %v", err)
+	}
+	var sinks []*webhook.Sink
+	for _, cfg := range cfgs {
+		s, err := webhook.NewSink(cfg, nil)
+		if err != nil {
+			if a.isWailsContext() {
+				runtime.LogWarningf(a.ctx, "webhook skipped: %v", err)
+			}
+			continue
+		}
+		sinks = append(sinks, s)
+	}
+	if len(sinks) == 0 {
+		return
+	}
+	d := webhook.NewDispatcher(sinks)
+	d.OnError = func(err error) {
+		if a.isWailsContext() {
+			runtime.LogWarningf(a.ctx, "webhook delivery failed: %v", err)
+		}
+	}
+	a.webhooks = d
+}
+
+// closeWebhooks delivers the queued events and drop alerts, waiting at most a few seconds per
+// dispatcher. Later events are dropped.
+func (a *App) closeWebhooks() {
+	if a.webhooks != nil {
+		a.webhooks.Close(5 * time.Second)
+	}
+	a.closeAlertHooks()
+}
+
+// publish queues ev for the webhook sinks, if any.
+func (a *App) publish(ev webhook.Event) {
+	if a.webhooks != nil {
+		a.webhooks.Publish(ev)
+	}
+}
+
+// onMapStart publishes a map_start event.
+func (a *App) onMapStart(_ time.Time, m tracker.MapSession) {
+	a.publish(webhook.Event{Type: webhook.MapStart, At: m.StartedAt,
+		Map: &webhook.Map{MapKey: m.MapKey, Region: m.Region, StartedAt: m.StartedAt}})
+}
+
+// onMapComplete saves a finished map to history and publishes a map_end event with its
+// tally, valued at the prices locked in when the items dropped.
+func (a *App) onMapComplete(sessionStartedAt time.Time, m tracker.MapSession) {
+	a.saveMap(sessionStartedAt, m)
+	r := history.NewRecord(sessionStartedAt, m, a.priceOf)
+	wm := &webhook.Map{
+		MapKey:          r.MapKey,
+		Region:          r.Region,
+		StartedAt:       r.StartedAt,
+		EndedAt:         r.EndedAt,
+		DurationSeconds: r.Duration().Seconds(),
+		Earnings:        r.Earnings(),
+		Cost:            r.Cost(),
+		Net:             r.Net(),
+	}
+	for id, n := range r.Tally {
+		it := webhook.Item{ID: id, Name: "#" + intToStr(id), Count: n, Value: float64(n) * r.Prices[id]}
+		if info, ok := a.itemInfo(id); ok && info.Name != "" {
+			it.Name = info.Name
+		}
+		wm.Drops += n
+		wm.Items = append(wm.Items, it)
+	}
+	sort.Slice(wm.Items, func(i, j int) bool {
+		if wm.Items[i].Value != wm.Items[j].Value {
+			return wm.Items[i].Value > wm.Items[j].Value
+		}
+		return wm.Items[i].ID < wm.Items[j].ID
+	})
+	a.publish(webhook.Event{Type: webhook.MapEnd, At: m.EndedAt, Map: wm})
+}
+
+// endSession publishes a session_end event for trk's session, once per session, valued
+// like map_end. Sessions without a map are not reported.
+func (a *App) endSession(trk *tracker.Tracker) {
+	st := trk.GetState()
+	if st.SessionStartedAt.IsZero() || st.SessionStartedAt.Equal(a.endedSession) {
+		return
+	}
+	a.endedSession = st.SessionStartedAt
+	now := time.Now()
+	maps := st.Completed
+	if st.Current.Active {
+		maps = append(maps, st.Current)
+	}
+	ws := &webhook.Session{
+		StartedAt:       st.SessionStartedAt,
+		EndedAt:         now,
+		DurationSeconds: (now.Sub(st.SessionStartedAt) - st.PausedBetween(st.SessionStartedAt, now)).Seconds(),
+		Maps:            len(st.Completed),
+		Drops:           st.TotalDrops,
+	}
+	for _, m := range maps {
+		r := history.NewRecord(st.SessionStartedAt, m, a.priceOf)
+		ws.Earnings += r.Earnings()
+		ws.Cost += r.Cost()
+	}
+	ws.Net = ws.Earnings - ws.Cost
+	if ws.DurationSeconds > 0 {
+		ws.EarningsPerHour = ws.Earnings / (ws.DurationSeconds / 3600)
+	}
+	a.publish(webhook.Event{Type: webhook.SessionEnd, At: now, Session: ws})
+}
+
+// itemInfo returns the item table entry for id, if loaded.
+func (a *App) itemInfo(id int) (ItemInfo, bool) {
+	if a.items == nil {
+		return ItemInfo{}, false
+	}
+	return a.items.GetInt(id)
+}
@@ -0,0 +1,213 @@
+package goals
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"GoTorch/internal/items"
+	"GoTorch/internal/stats"
+	"GoTorch/internal/tracker"
+)
+
+// Kind is what a goal measures over the session.
+type Kind string
+
+const (
+	KindEarnings Kind = "earnings" // session earnings at current prices
+	KindItem     Kind = "item"     // count of one item picked up
+	KindMaps     Kind = "maps"     // completed maps
+)
+
+// ErrInvalid is returned by Parse for malformed goal specs.
+var ErrInvalid = errors.New("goals: invalid goal")
+
+// Goal is a session target.
+type Goal struct {
+	ID     string
+	Kind   Kind
+	Target float64
+	ItemID int // KindItem only
+	Label  string
+}
+
+// Progress is a goal evaluated against a tracker state.
+type Progress struct {
+	Goal
+	Current float64
+	Percent float64 // 0..100
+	Done    bool
+	DoneAt  time.Time // when the goal was first seen met
+}
+
+// Parse reads a goal spec:
+//
+//	earnings:500          earn 500 (in item table price units)
+//	maps:30               run 30 maps
+//	item:<id|name>:20     collect 20 of an item; names are resolved through cat
+func Parse(spec string, cat *items.Catalog) (Goal, error) {
+	kind, rest, ok := strings.Cut(strings.TrimSpace(spec), ":")
+	if !ok {
+		return Goal{}, fmt.Errorf("%w %q: want kind:target", ErrInvalid, spec)
+	}
+	var g Goal
+	switch strings.ToLower(kind) {
+	case "earnings", "earn":
+		g.Kind = KindEarnings
+	case "maps", "runs":
+		g.Kind = KindMaps
+	case "item":
+		g.Kind = KindItem
+		i := strings.LastIndex(rest, ":")
+		if i < 0 {
+			return Goal{}, fmt.Errorf("%w %q: want item:<id or name>:<count>", ErrInvalid, spec)
+		}
+		id, err := resolveItem(strings.TrimSpace(rest[:i]), cat)
+		if err != nil {
+			return Goal{}, fmt.Errorf("%w %q: %v", ErrInvalid, spec, err)
+		}
+		g.ItemID = id
+		rest = rest[i+1:]
+	default:
+		return Goal{}, fmt.Errorf("%w %q: unknown kind %q", ErrInvalid, spec, kind)
+	}
+	target, err := strconv.ParseFloat(strings.TrimSpace(rest), 64)
+	if err != nil || target <= 0 {
+		return Goal{}, fmt.Errorf("%w %q: target must be a positive number", ErrInvalid, spec)
+	}
+	g.Target = target
+	g.Label = label(g, cat)
+	return g, nil
+}
+
+func resolveItem(s string, cat *items.Catalog) (int, error) {
+	if id, err := strconv.Atoi(s); err == nil {
+		return id, nil
+	}
+	if cat != nil {
+		if its := cat.ByName(s); len(its) > 0 {
+			return strconv.Atoi(its[0].ID)
+		}
+		if its := cat.ByChineseName(s); len(its) > 0 {
+			return strconv.Atoi(its[0].ID)
+		}
+	}
+	return 0, fmt.Errorf("unknown item %q", s)
+}
+
+func label(g Goal, cat *items.Catalog) string {
+	target := strconv.FormatFloat(g.Target, 'f', -1, 64)
+	switch g.Kind {
+	case KindEarnings:
+		return "Earn " + target
+	case KindMaps:
+		return "Run " + target + " maps"
+	}
+	name := "#" + strconv.Itoa(g.ItemID)
+	if cat != nil {
+		if it, ok := cat.GetInt(g.ItemID); ok && it.Name != "" {
+			name = it.Name
+		}
+	}
+	return "Collect " + target + " " + name
+}
+
+// Evaluate returns the current value of g over the session in st.
+func Evaluate(g Goal, st tracker.State, price stats.PriceFunc) float64 {
+	sessions := st.Completed
+	// after MapEnd, Current is the last completed map and already counted
+	if st.Current.Active {
+		sessions = append(sessions[:len(sessions):len(sessions)], st.Current)
+	}
+	var v float64
+	switch g.Kind {
+	case KindMaps:
+		v = float64(len(st.Completed))
+	case KindEarnings:
+		for _, m := range sessions {
+			v += stats.Earnings(m.Tally, price)
+		}
+	case KindItem:
+		for _, m := range sessions {
+			v += float64(m.Tally[g.ItemID])
+		}
+	}
+	return v
+}
+
+// Set is the goals of a session with the time each was met. It is safe for concurrent use.
+type Set struct {
+	mu     sync.Mutex
+	goals  []Goal
+	doneAt map[string]time.Time
+	nextID int
+}
+
+// NewSet creates an empty goal set.
+func NewSet() *Set {
+	return &Set{doneAt: make(map[string]time.Time)}
+}
+
+// Add appends g, assigning an id when it has none, and returns it.
+func (s *Set) Add(g Goal) Goal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if g.ID == "" {
+		s.nextID++
+		g.ID = "g" + strconv.Itoa(s.nextID)
+	}
+	s.goals = append(s.goals, g)
+	return g
+}
+
+// Remove deletes the goal with the given id and reports whether it existed.
+func (s *Set) Remove(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, g := range s.goals {
+		if g.ID == id {
+			s.goals = append(s.goals[:i], s.goals[i+1:]...)
+			delete(s.doneAt, id)
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the goals in the order they were added.
+func (s *Set) List() []Goal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Goal(nil), s.goals...)
+}
+
+// Restart forgets which goals were met, for a new session with the same goals.
+func (s *Set) Restart() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.doneAt = make(map[string]time.Time)
+}
+
+// Update evaluates every goal against st. A goal stays done once met, even if prices drop later.
+// met holds the goals first met by this call, stamped with now.
+func (s *Set) Update(st tracker.State, price stats.PriceFunc, now time.Time) (all, met []Progress) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all = make([]Progress, 0, len(s.goals))
+	for _, g := range s.goals {
+		p := Progress{Goal: g, Current: Evaluate(g, st, price)}
+		p.Percent = min(100, 100*p.Current/g.Target)
+		if at, ok := s.doneAt[g.ID]; ok {
+			p.Done, p.DoneAt = true, at
+		} else if p.Current >= g.Target {
+			s.doneAt[g.ID] = now
+			p.Done, p.DoneAt = true, now
+			met = append(met, p)
+		}
+		all = append(all, p)
+	}
+	return all, met
+}
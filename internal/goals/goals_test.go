@@ -0,0 +1,86 @@
+package goals
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"GoTorch/internal/items"
+	"GoTorch/internal/tracker"
+	"GoTorch/internal/types"
+)
+
+func testCatalog() *items.Catalog {
+	return items.New(map[string]items.Item{
+		"100300": {Name: "Flame Elementium", Price: 1},
+		"5210":   {Name: "Ember", Price: 10},
+	}, "test")
+}
+
+func TestParse(t *testing.T) {
+	cat := testCatalog()
+	cases := []struct {
+		spec string
+		want Goal
+	}{
+		{"earnings:500", Goal{Kind: KindEarnings, Target: 500, Label: "Earn 500"}},
+		{"maps:30", Goal{Kind: KindMaps, Target: 30, Label: "Run 30 maps"}},
+		{"item:5210:20", Goal{Kind: KindItem, ItemID: 5210, Target: 20, Label: "Collect 20 Ember"}},
+		{"item:flame elementium:3", Goal{Kind: KindItem, ItemID: 100300, Target: 3, Label: "Collect 3 Flame Elementium"}},
+	}
+	for _, tc := range cases {
+		got, err := Parse(tc.spec, cat)
+		if err != nil || got != tc.want {
+			t.Fatalf("Parse(%q) = %+v, %v; want %+v", tc.spec, got, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "earnings", "maps:-1", "gold:5", "item:20", "item:Nope:2"} {
+		if _, err := Parse(bad, cat); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("Parse(%q) error = %v; want ErrInvalid", bad, err)
+		}
+	}
+}
+
+func TestSetUpdateLatchesMetGoals(t *testing.T) {
+	cat := testCatalog()
+	s := NewSet()
+	for _, spec := range []string{"earnings:50", "item:Ember:5", "maps:2"} {
+		g, err := Parse(spec, cat)
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		s.Add(g)
+	}
+	trk := tracker.New()
+	start := time.Now()
+	bag := func(d time.Duration, num int) *types.Event {
+		return &types.Event{Kind: types.EventBagMod, Time: start.Add(d), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 5210, Num: num}}
+	}
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start})
+	trk.OnEvent(bag(time.Minute, 6))
+	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(2 * time.Minute)})
+
+	all, met := s.Update(trk.GetState(), cat.PriceOf, start.Add(3*time.Minute))
+	if len(all) != 3 || len(met) != 2 || met[0].Kind != KindEarnings || met[1].Kind != KindItem {
+		t.Fatalf("unexpected progress %+v met %+v", all, met)
+	}
+	if all[0].Current != 60 || all[0].Percent != 100 || all[2].Current != 1 || all[2].Percent != 50 || all[2].Done {
+		t.Fatalf("unexpected progress values %+v", all)
+	}
+
+	// met goals stay done and are reported once
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start.Add(4 * time.Minute)})
+	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(5 * time.Minute)})
+	all, met = s.Update(trk.GetState(), nil, start.Add(6*time.Minute))
+	if len(met) != 1 || met[0].Kind != KindMaps || !all[0].Done || !all[0].DoneAt.Equal(start.Add(3*time.Minute)) {
+		t.Fatalf("unexpected second update: all %+v met %+v", all, met)
+	}
+
+	s.Restart()
+	if _, met = s.Update(trk.GetState(), cat.PriceOf, start); len(met) != 3 {
+		t.Fatalf("expected all goals met again after Restart, got %+v", met)
+	}
+	if !s.Remove("g2") || s.Remove("g2") || len(s.List()) != 2 {
+		t.Fatalf("unexpected Remove behaviour: %+v", s.List())
+	}
+}
@@ -0,0 +1,321 @@
+package history
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"GoTorch/internal/tracker"
+)
+
+// ErrNotFound is returned when a record id is not present in the store.
+var ErrNotFound = errors.New("history: record not found")
+
+// Record is a completed map run persisted to disk together with the prices in effect when it was saved.
+type Record struct {
+	ID               string          `json:"id"`
+	SessionStartedAt time.Time       `json:"session_started_at"`
+	MapKey           string          `json:"map_key"`
+	Region           string          `json:"region"`
+	ScenePath        string          `json:"scene_path"`
+	PrevScene        string          `json:"prev_scene"`
+	StartedAt        time.Time       `json:"started_at"`
+	EndedAt          time.Time       `json:"ended_at"`
+	Tally            map[int]int     `json:"tally"`
+	Prices           map[int]float64 `json:"prices"`                 // unit price per item id at save time
+	Spent            map[int]int     `json:"spent,omitempty"`        // items consumed by the run
+	SpentPrices      map[int]float64 `json:"spent_prices,omitempty"` // unit price per consumed item id
+	Paused           time.Duration   `json:"paused,omitempty"`       // time tracking was paused during the run
+	SavedAt          time.Time       `json:"saved_at"`
+}
+
+// NewRecord builds a record from a finalized map session. Unit prices are the ones locked in
+// when the items dropped or were consumed (MapSession.Value, SpentValue), falling back to price, which may be nil.
+func NewRecord(sessionStartedAt time.Time, m tracker.MapSession, price func(id int) float64) Record {
+	r := Record{
+		SessionStartedAt: sessionStartedAt,
+		MapKey:           m.MapKey,
+		Region:           m.Region,
+		ScenePath:        m.ScenePath,
+		PrevScene:        m.PrevScene,
+		StartedAt:        m.StartedAt,
+		EndedAt:          m.EndedAt,
+		Paused:           m.PausedTime,
+		Tally:            make(map[int]int, len(m.Tally)),
+		Prices:           make(map[int]float64, len(m.Tally)),
+	}
+	lockPrices(r.Tally, r.Prices, m.Tally, m.Value, price)
+	if len(m.Spent) > 0 {
+		r.Spent = make(map[int]int, len(m.Spent))
+		r.SpentPrices = make(map[int]float64, len(m.Spent))
+		lockPrices(r.Spent, r.SpentPrices, m.Spent, m.SpentValue, price)
+	}
+	return r
+}
+
+// lockPrices copies tally into counts and stores each id's locked unit price in prices.
+func lockPrices(counts map[int]int, prices map[int]float64, tally map[int]int, value map[int]float64, price func(id int) float64) {
+	for id, n := range tally {
+		counts[id] = n
+		if v, ok := value[id]; ok && n > 0 {
+			prices[id] = v / float64(n)
+		} else if price != nil {
+			prices[id] = price(id)
+		}
+	}
+}
+
+// Duration returns the run time of the map, excluding paused time.
+func (r Record) Duration() time.Duration {
+	return tracker.MapSession{StartedAt: r.StartedAt, EndedAt: r.EndedAt, PausedTime: r.Paused}.Duration()
+}
+
+// Earnings values the tally with the prices stored on the record (gross earnings).
+func (r Record) Earnings() float64 {
+	var sum float64
+	for id, n := range r.Tally {
+		sum += float64(n) * r.Prices[id]
+	}
+	return sum
+}
+
+// Cost values the consumed items with the prices stored on the record.
+func (r Record) Cost() float64 {
+	var sum float64
+	for id, n := range r.Spent {
+		sum += float64(n) * r.SpentPrices[id]
+	}
+	return sum
+}
+
+// Net returns earnings minus cost.
+func (r Record) Net() float64 {
+	return r.Earnings() - r.Cost()
+}
+
+// Session returns the tracker view of the record.
+func (r Record) Session() tracker.MapSession {
+	m := tracker.MapSession{
+		StartedAt:  r.StartedAt,
+		EndedAt:    r.EndedAt,
+		MapKey:     r.MapKey,
+		Region:     r.Region,
+		ScenePath:  r.ScenePath,
+		PrevScene:  r.PrevScene,
+		PausedTime: r.Paused,
+		Tally:      make(map[int]int, len(r.Tally)),
+		Spent:      make(map[int]int, len(r.Spent)),
+	}
+	for id, n := range r.Tally {
+		m.Tally[id] = n
+	}
+	for id, n := range r.Spent {
+		m.Spent[id] = n
+	}
+	return m
+}
+
+// Query filters records. Zero-valued fields match everything.
+type Query struct {
+	MapKey string
+	Region string
+	Since  time.Time // records started at or after
+	Until  time.Time // records started before
+	Limit  int       // keep only the most recent Limit records
+}
+
+func (q Query) match(r Record) bool {
+	if q.MapKey != "" && r.MapKey != q.MapKey {
+		return false
+	}
+	if q.Region != "" && r.Region != q.Region {
+		return false
+	}
+	if !q.Since.IsZero() && r.StartedAt.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !r.StartedAt.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+// Store is a JSON-lines file of records. It is safe for concurrent use.
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// DefaultPath returns the history file location, honoring GOTORCH_HISTORY.
+func DefaultPath() string {
+	if p := os.Getenv("GOTORCH_HISTORY"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "gotorch_history.jsonl"
+	}
+	return filepath.Join(dir, "GoTorch", "history.jsonl")
+}
+
+// Open returns a store backed by path. The file is created on first write.
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the file backing the store.
+func (s *Store) Path() string { return s.path }
+
+// Append assigns an id (if empty) and saves r.
+func (s *Store) Append(r Record) (Record, error) {
+	if r.ID == "" {
+		r.ID = newID()
+	}
+	if r.SavedAt.IsZero() {
+		r.SavedAt = time.Now()
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return r, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return r, err
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return r, err
+	}
+	defer f.Close()
+	_, err = f.Write(append(b, '\n'))
+	return r, err
+}
+
+// List returns every record ordered by start time.
+func (s *Store) List() ([]Record, error) {
+	return s.Query(Query{})
+}
+
+// Query returns the records matching q ordered by start time.
+func (s *Store) Query(q Query) ([]Record, error) {
+	s.mu.Lock()
+	all, err := s.readAll()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Record, 0, len(all))
+	for _, r := range all {
+		if q.match(r) {
+			out = append(out, r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out, nil
+}
+
+// Get loads a single record by id.
+func (s *Store) Get(id string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.readAll()
+	if err != nil {
+		return Record{}, err
+	}
+	for _, r := range all {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return Record{}, ErrNotFound
+}
+
+// Delete removes the record with id, rewriting the file.
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.readAll()
+	if err != nil {
+		return err
+	}
+	keep := all[:0]
+	for _, r := range all {
+		if r.ID != id {
+			keep = append(keep, r)
+		}
+	}
+	if len(keep) == len(all) {
+		return ErrNotFound
+	}
+	return s.rewrite(keep)
+}
+
+// readAll reads the file with s.mu held. Malformed lines (e.g. a torn write) are skipped.
+func (s *Store) readAll() ([]Record, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []Record
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var r Record
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil || r.ID == "" {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out, sc.Err()
+}
+
+// rewrite atomically replaces the file contents with records, with s.mu held.
+func (s *Store) rewrite(records []Record) error {
+	tmp := s.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func newID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,126 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"GoTorch/internal/tracker"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	return Open(filepath.Join(t.TempDir(), "sub", "history.jsonl"))
+}
+
+func TestAppendListGetDelete(t *testing.T) {
+	s := newTestStore(t)
+	if recs, err := s.List(); err != nil || len(recs) != 0 {
+		t.Fatalf("expected empty store, got %v %v", recs, err)
+	}
+	base := time.Date(2025, 11, 4, 19, 0, 0, 0, time.UTC)
+	m := tracker.MapSession{MapKey: "YJ_A", Region: "07YJ", StartedAt: base, EndedAt: base.Add(5 * time.Minute), Tally: map[int]int{1001: 3}}
+	r1, err := s.Append(NewRecord(base, m, func(int) float64 { return 2 }))
+	if err != nil || r1.ID == "" {
+		t.Fatalf("append: %v id=%q", err, r1.ID)
+	}
+	m.StartedAt = base.Add(-time.Hour)
+	m.EndedAt = m.StartedAt.Add(time.Minute)
+	m.MapKey = "YJ_B"
+	r2, err := s.Append(NewRecord(base, m, nil))
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	recs, err := s.List()
+	if err != nil || len(recs) != 2 {
+		t.Fatalf("list: %v %d", err, len(recs))
+	}
+	if recs[0].ID != r2.ID || recs[1].ID != r1.ID {
+		t.Fatalf("expected records ordered by start time")
+	}
+
+	got, err := s.Get(r1.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.MapKey != "YJ_A" || got.Tally[1001] != 3 || got.Prices[1001] != 2 || got.Earnings() != 6 || got.Duration() != 5*time.Minute {
+		t.Fatalf("unexpected record round trip: %+v", got)
+	}
+	if !got.StartedAt.Equal(base) || got.Session().Tally[1001] != 3 {
+		t.Fatalf("unexpected session view: %+v", got.Session())
+	}
+
+	if err := s.Delete(r1.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := s.Get(r1.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := s.Delete(r1.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound deleting twice, got %v", err)
+	}
+	if recs, _ := s.List(); len(recs) != 1 || recs[0].ID != r2.ID {
+		t.Fatalf("unexpected records after delete: %+v", recs)
+	}
+}
+
+func TestQueryFiltersAndLimit(t *testing.T) {
+	s := newTestStore(t)
+	base := time.Date(2025, 11, 4, 19, 0, 0, 0, time.UTC)
+	for i, key := range []string{"A", "B", "A", "A"} {
+		start := base.Add(time.Duration(i) * time.Hour)
+		_, err := s.Append(NewRecord(base, tracker.MapSession{MapKey: key, StartedAt: start, EndedAt: start.Add(time.Minute)}, nil))
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	if recs, _ := s.Query(Query{MapKey: "A"}); len(recs) != 3 {
+		t.Fatalf("map filter: got %d", len(recs))
+	}
+	if recs, _ := s.Query(Query{Since: base.Add(time.Hour), Until: base.Add(3 * time.Hour)}); len(recs) != 2 {
+		t.Fatalf("time filter: got %d", len(recs))
+	}
+	recs, _ := s.Query(Query{MapKey: "A", Limit: 1})
+	if len(recs) != 1 || !recs[0].StartedAt.Equal(base.Add(3*time.Hour)) {
+		t.Fatalf("limit should keep most recent: %+v", recs)
+	}
+}
+
+func TestListSkipsMalformedLines(t *testing.T) {
+	s := newTestStore(t)
+	if _, err := s.Append(NewRecord(time.Now(), tracker.MapSession{MapKey: "A"}, nil)); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	f, err := os.OpenFile(s.Path(), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	_, _ = f.WriteString("{\"id\":\"torn")
+	_ = f.Close()
+	if recs, err := s.List(); err != nil || len(recs) != 1 {
+		t.Fatalf("expected torn line to be skipped, got %d records err=%v", len(recs), err)
+	}
+}
+
+func TestNewRecordUsesLockedPrices(t *testing.T) {
+	start := time.Now()
+	m := tracker.MapSession{StartedAt: start, EndedAt: start.Add(time.Minute), Tally: map[int]int{1: 4, 2: 1}, Value: map[int]float64{1: 6}}
+	r := NewRecord(start, m, func(id int) float64 { return 100 })
+	if r.Prices[1] != 1.5 || r.Prices[2] != 100 || r.Earnings() != 106 {
+		t.Fatalf("unexpected prices: %+v earnings %v", r.Prices, r.Earnings())
+	}
+}
+
+func TestNewRecordKeepsSpentItems(t *testing.T) {
+	start := time.Now()
+	m := tracker.MapSession{StartedAt: start, EndedAt: start.Add(time.Minute),
+		Tally: map[int]int{1: 2}, Value: map[int]float64{1: 10},
+		Spent: map[int]int{9: 1}, SpentValue: map[int]float64{9: 4}}
+	r := NewRecord(start, m, nil)
+	if r.Cost() != 4 || r.Net() != 6 || r.Session().Spent[9] != 1 {
+		t.Fatalf("unexpected cost %v net %v spent %+v", r.Cost(), r.Net(), r.Spent)
+	}
+}
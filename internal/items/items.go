@@ -0,0 +1,350 @@
+package items
+
+import (
+	"embed"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"GoTorch/internal/pricing"
+)
+
+// embeddedFS contains the fallback item table bundled into the binary.
+//
+//go:embed data/full_table.json
+var embeddedFS embed.FS
+
+// Item is an entry of the item table (full_table.json), keyed by ConfigBaseID.
+// Fields the catalog does not know about are kept so the table can be written back unchanged.
+type Item struct {
+	ID         string  `json:"-"`
+	Name       string  `json:"name"`
+	NameCN     string  `json:"name_cn,omitempty"`
+	Type       string  `json:"type"`
+	Price      float64 `json:"price"`
+	LastUpdate float64 `json:"last_update"`
+	From       string  `json:"from"`
+
+	extra map[string]json.RawMessage
+}
+
+// knownFields are the keys decoded into Item fields.
+var knownFields = []string{"name", "name_cn", "type", "price", "last_update", "from"}
+
+// UnmarshalJSON decodes the known fields and keeps any others.
+func (it *Item) UnmarshalJSON(b []byte) error {
+	type plain Item
+	var p plain
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	for _, k := range knownFields {
+		delete(raw, k)
+	}
+	*it = Item(p)
+	if len(raw) > 0 {
+		it.extra = raw
+	}
+	return nil
+}
+
+// MarshalJSON encodes the known fields together with any preserved unknown ones.
+func (it Item) MarshalJSON() ([]byte, error) {
+	type plain Item
+	b, err := json.Marshal(plain(it))
+	if err != nil || len(it.extra) == 0 {
+		return b, err
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	for k, v := range it.extra {
+		if _, ok := m[k]; !ok {
+			m[k] = v
+		}
+	}
+	return json.Marshal(m)
+}
+
+// Catalog is the item table with lookup indexes. It is safe for concurrent use.
+type Catalog struct {
+	mu     sync.RWMutex
+	items  map[string]Item
+	byName map[string][]string // lower-case English name -> ids
+	byCN   map[string][]string // Chinese name -> ids
+	source string
+}
+
+// New builds a catalog from items keyed by id. source describes where they came from.
+func New(items map[string]Item, source string) *Catalog {
+	c := &Catalog{items: make(map[string]Item, len(items)), source: source}
+	for id, it := range items {
+		it.ID = id
+		c.items[id] = it
+	}
+	c.reindex()
+	return c
+}
+
+// Parse decodes an item table JSON document.
+func Parse(b []byte) (map[string]Item, error) {
+	var m map[string]Item
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadFile reads an item table from path.
+func LoadFile(path string) (*Catalog, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := Parse(b)
+	if err != nil {
+		return nil, err
+	}
+	return New(m, "file:"+path), nil
+}
+
+// Load attempts to load full_table.json from common locations, with env override and embedded fallback:
+// GOTORCH_ITEM_TABLE, the working directory, the executable directory, then the embedded table.
+// It never fails; an empty catalog has source "none".
+func Load() *Catalog {
+	readItemFile := func(path string) (map[string]Item, bool) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, false
+		}
+		m, err := Parse(b)
+		if err != nil || len(m) == 0 {
+			return nil, false
+		}
+		return m, true
+	}
+
+	// 1) Environment variable override
+	if p := os.Getenv("GOTORCH_ITEM_TABLE"); p != "" {
+		if m, ok := readItemFile(p); ok {
+			return New(m, "env:"+p)
+		}
+	}
+	// 2) Working directory
+	if m, ok := readItemFile("full_table.json"); ok {
+		return New(m, "file:./full_table.json")
+	}
+	// 3) Executable directory
+	if exe, err := os.Executable(); err == nil {
+		if m, ok := readItemFile(filepath.Join(filepath.Dir(exe), "full_table.json")); ok {
+			return New(m, "exe_dir:full_table.json")
+		}
+	}
+	// 4) Embedded fallback
+	if b, err := embeddedFS.ReadFile("data/full_table.json"); err == nil {
+		if m, err := Parse(b); err == nil && len(m) > 0 {
+			return New(m, "embedded")
+		}
+	}
+	return New(nil, "none")
+}
+
+// EmbeddedPrices returns the prices of the item table bundled into the binary,
+// for use as a static fallback price source.
+func EmbeddedPrices() map[string]pricing.PriceUpdate {
+	out := make(map[string]pricing.PriceUpdate)
+	b, err := embeddedFS.ReadFile("data/full_table.json")
+	if err != nil {
+		return out
+	}
+	m, err := Parse(b)
+	if err != nil {
+		return out
+	}
+	for id, it := range m {
+		out[id] = pricing.PriceUpdate{Price: it.Price, LastUpdate: it.LastUpdate}
+	}
+	return out
+}
+
+// reindex rebuilds the name indexes; c.mu must be held for writing (or c not yet shared).
+func (c *Catalog) reindex() {
+	c.byName = make(map[string][]string)
+	c.byCN = make(map[string][]string)
+	for id, it := range c.items {
+		if it.Name != "" {
+			k := strings.ToLower(it.Name)
+			c.byName[k] = append(c.byName[k], id)
+		}
+		if it.NameCN != "" {
+			c.byCN[it.NameCN] = append(c.byCN[it.NameCN], id)
+		}
+	}
+	for _, ids := range c.byName {
+		sortIDs(ids)
+	}
+	for _, ids := range c.byCN {
+		sortIDs(ids)
+	}
+}
+
+// Source describes where the catalog was loaded from.
+func (c *Catalog) Source() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.source
+}
+
+// Len returns the number of items.
+func (c *Catalog) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
+// Get returns the item with the given id.
+func (c *Catalog) Get(id string) (Item, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	it, ok := c.items[id]
+	return it, ok
+}
+
+// GetInt returns the item with the given ConfigBaseID.
+func (c *Catalog) GetInt(id int) (Item, bool) {
+	return c.Get(strconv.Itoa(id))
+}
+
+// PriceOf returns the unit price of a ConfigBaseID, or 0 if unknown.
+func (c *Catalog) PriceOf(id int) float64 {
+	it, _ := c.GetInt(id)
+	return it.Price
+}
+
+// ByName returns the items with the given English name (case-insensitive), ordered by id.
+// Names are not unique; e.g. relic variants share a name.
+func (c *Catalog) ByName(name string) []Item {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lookup(c.byName[strings.ToLower(name)])
+}
+
+// ByChineseName returns the items with the given Chinese name, ordered by id.
+func (c *Catalog) ByChineseName(name string) []Item {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lookup(c.byCN[name])
+}
+
+// OfType returns the items of the given type (case-insensitive), ordered by id.
+func (c *Catalog) OfType(typ string) []Item {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var ids []string
+	for id, it := range c.items {
+		if strings.EqualFold(it.Type, typ) {
+			ids = append(ids, id)
+		}
+	}
+	sortIDs(ids)
+	return c.lookup(ids)
+}
+
+// IDs returns every item id, ordered numerically.
+func (c *Catalog) IDs() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ids := make([]string, 0, len(c.items))
+	for id := range c.items {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+	return ids
+}
+
+// All returns a copy of every item keyed by id.
+func (c *Catalog) All() map[string]Item {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make(map[string]Item, len(c.items))
+	for id, it := range c.items {
+		out[id] = it
+	}
+	return out
+}
+
+// SetChineseNames assigns Chinese names from a name -> id mapping, for tables that do not carry name_cn.
+func (c *Catalog) SetChineseNames(names map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for cn, id := range names {
+		if it, ok := c.items[id]; ok {
+			it.NameCN = cn
+			c.items[id] = it
+		}
+	}
+	c.reindex()
+}
+
+// ApplyPrices merges price and last_update from updates into existing items only.
+// It returns how many items changed and how many updates were offered.
+func (c *Catalog) ApplyPrices(updates map[string]pricing.PriceUpdate) (changed int, total int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, u := range updates {
+		total++
+		if it, ok := c.items[id]; ok {
+			if it.Price != u.Price || it.LastUpdate != u.LastUpdate {
+				it.Price = u.Price
+				it.LastUpdate = u.LastUpdate
+				c.items[id] = it
+				changed++
+			}
+		}
+	}
+	return changed, total
+}
+
+// WriteFile writes the catalog back to path as indented JSON, preserving unknown fields.
+func (c *Catalog) WriteFile(path string) error {
+	b, err := json.MarshalIndent(c.All(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+// lookup resolves ids to items; c.mu must be held.
+func (c *Catalog) lookup(ids []string) []Item {
+	out := make([]Item, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, c.items[id])
+	}
+	return out
+}
+
+// sortIDs orders numeric ids numerically and others lexically after them.
+func sortIDs(ids []string) {
+	sort.Slice(ids, func(i, j int) bool {
+		a, errA := strconv.Atoi(ids[i])
+		b, errB := strconv.Atoi(ids[j])
+		switch {
+		case errA == nil && errB == nil:
+			return a < b
+		case errA == nil:
+			return true
+		case errB == nil:
+			return false
+		}
+		return ids[i] < ids[j]
+	})
+}
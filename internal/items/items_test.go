@@ -0,0 +1,127 @@
+package items
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"GoTorch/internal/pricing"
+)
+
+func testCatalog() *Catalog {
+	return New(map[string]Item{
+		"100":   {Name: "Foo", Type: "Ember", Price: 1, LastUpdate: 1000},
+		"200":   {Name: "Bar", Type: "ember", Price: 2, LastUpdate: 2000, NameCN: "酒吧"},
+		"10001": {Name: "Foo", Type: "Compass", Price: 3},
+	}, "test")
+}
+
+func TestLookups(t *testing.T) {
+	c := testCatalog()
+	if it, ok := c.GetInt(100); !ok || it.ID != "100" || it.Name != "Foo" {
+		t.Fatalf("GetInt(100) = %+v, %v", it, ok)
+	}
+	if c.PriceOf(10001) != 3 || c.PriceOf(999) != 0 {
+		t.Fatalf("unexpected PriceOf results")
+	}
+	foo := c.ByName("foo")
+	if len(foo) != 2 || foo[0].ID != "100" || foo[1].ID != "10001" {
+		t.Fatalf("ByName(foo) = %+v", foo)
+	}
+	if cn := c.ByChineseName("酒吧"); len(cn) != 1 || cn[0].ID != "200" {
+		t.Fatalf("ByChineseName = %+v", cn)
+	}
+	c.SetChineseNames(map[string]string{"指南针": "10001", "无": "999"})
+	if cn := c.ByChineseName("指南针"); len(cn) != 1 || cn[0].ID != "10001" {
+		t.Fatalf("ByChineseName after SetChineseNames = %+v", cn)
+	}
+	embers := c.OfType("EMBER")
+	if len(embers) != 2 || embers[0].ID != "100" || embers[1].ID != "200" {
+		t.Fatalf("OfType(EMBER) = %+v", embers)
+	}
+	if c.Source() != "test" || c.Len() != 3 {
+		t.Fatalf("Source/Len = %q/%d", c.Source(), c.Len())
+	}
+}
+
+func TestApplyPrices(t *testing.T) {
+	c := testCatalog()
+	changed, total := c.ApplyPrices(map[string]pricing.PriceUpdate{
+		"100": {Price: 1.5, LastUpdate: 1500}, // change both
+		"200": {Price: 2, LastUpdate: 2000},   // unchanged
+		"300": {Price: 3.0, LastUpdate: 3000}, // not present in the catalog
+	})
+	if total != 3 || changed != 1 {
+		t.Fatalf("changed=%d total=%d want 1/3", changed, total)
+	}
+	if it, _ := c.Get("100"); it.Price != 1.5 || it.LastUpdate != 1500 {
+		t.Fatalf("item 100 not updated: %+v", it)
+	}
+	if _, ok := c.Get("300"); ok {
+		t.Fatalf("unexpected creation of new id 300")
+	}
+}
+
+func TestConcurrentPriceUpdates(t *testing.T) {
+	c := testCatalog()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			c.ApplyPrices(map[string]pricing.PriceUpdate{"100": {Price: float64(i)}})
+		}(i)
+		go func() {
+			defer wg.Done()
+			_ = c.PriceOf(100)
+			_ = c.ByName("Foo")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLoadOrder(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "table.json")
+	if err := os.WriteFile(path, []byte(`{"1":{"name":"a","price":1}}`), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	t.Setenv("GOTORCH_ITEM_TABLE", path)
+	if c := Load(); c.Source() != "env:"+path || c.Len() != 1 {
+		t.Fatalf("env override: source=%q len=%d", c.Source(), c.Len())
+	}
+
+	t.Setenv("GOTORCH_ITEM_TABLE", filepath.Join(dir, "missing.json"))
+	wd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	// the embedded table is a stub unless CI copied the real one in
+	if c := Load(); (c.Source() != "embedded" || c.Len() == 0) && c.Source() != "none" {
+		t.Fatalf("embedded fallback: source=%q len=%d", c.Source(), c.Len())
+	}
+}
+
+func TestLoadFileKeepsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "table.json")
+	if err := os.WriteFile(path, []byte(`{"1":{"name":"a","price":1,"last_time":5}}`), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	c, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if err := c.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	c2, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	it, _ := c2.Get("1")
+	if string(it.extra["last_time"]) != "5" || it.Name != "a" || it.Price != 1 {
+		t.Fatalf("round trip lost data: %+v", it)
+	}
+}
@@ -0,0 +1,196 @@
+// Package logfiles finds and opens game logs for offline reading: plain logs, gzip-compressed
+// logs and logs inside zip archives. Unreal rotates UE_game.log into
+// UE_game-backup-YYYY.MM.DD-HH.MM.SS.log; such backups are ordered by the time in their name.
+package logfiles
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Log is one log to read: a file on disk, or a member of a zip archive.
+type Log struct {
+	Path   string    // file on disk
+	Member string    // name inside the zip archive at Path; empty for a plain or gzip file
+	Time   time.Time // from a backup name; zero for other logs
+}
+
+// String names the log, as archive.zip:member for archive members.
+func (l Log) String() string {
+	if l.Member != "" {
+		return l.Path + ":" + l.Member
+	}
+	return l.Path
+}
+
+// Open returns the log's content, decompressed when its name ends in .gz.
+func (l Log) Open() (io.ReadCloser, error) {
+	if l.Member == "" {
+		f, err := os.Open(l.Path)
+		if err != nil {
+			return nil, err
+		}
+		return decompress(l.Path, f)
+	}
+	zr, err := zip.OpenReader(l.Path)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := openMember(zr, l)
+	if err != nil {
+		zr.Close()
+		return nil, err
+	}
+	return readCloser{rc, closeAll{rc, zr}}, nil
+}
+
+func openMember(zr *zip.ReadCloser, l Log) (io.ReadCloser, error) {
+	for _, zf := range zr.File {
+		if zf.Name != l.Member {
+			continue
+		}
+		r, err := zf.Open()
+		if err != nil {
+			return nil, err
+		}
+		return decompress(l.Member, r)
+	}
+	return nil, fmt.Errorf("%s: no member %q", l.Path, l.Member)
+}
+
+// decompress wraps rc in a gzip reader for .gz names; closing the result closes rc.
+func decompress(name string, rc io.ReadCloser) (io.ReadCloser, error) {
+	if !strings.EqualFold(filepath.Ext(name), ".gz") {
+		return rc, nil
+	}
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return readCloser{gz, closeAll{gz, rc}}, nil
+}
+
+// readCloser reads from Reader and closes all of closeAll.
+type readCloser struct {
+	io.Reader
+	closeAll
+}
+
+// closeAll closes each closer in order and returns the first error.
+type closeAll []io.Closer
+
+func (c closeAll) Close() error {
+	var first error
+	for _, cl := range c {
+		if err := cl.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+var backupName = regexp.MustCompile(`-backup-(\d{4})\.(\d{2})\.(\d{2})-(\d{2})\.(\d{2})\.(\d{2})\.log(?:\.gz)?$`)
+
+// BackupTime reads the time from an Unreal backup log name such as
+// UE_game-backup-2025.11.04-19.20.45.log (or .log.gz), in local time.
+func BackupTime(name string) (time.Time, bool) {
+	m := backupName.FindStringSubmatch(filepath.Base(name))
+	if m == nil {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation("2006.01.02-15.04.05", fmt.Sprintf("%s.%s.%s-%s.%s.%s", m[1], m[2], m[3], m[4], m[5], m[6]), time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// isLog reports whether a name inside a directory or archive is a log to read.
+func isLog(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".log.gz")
+}
+
+// List expands paths into logs. A directory contributes its .log, .log.gz and .zip files, a zip
+// archive its .log and .log.gz members, and any other file is read as a log (gzip if named
+// .gz). Backups come first, oldest first, followed by the other logs in the order given, so
+// the live UE_game.log is read after the backups it rotated into.
+func List(paths []string) ([]Log, error) {
+	var out []Log
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			logs, err := listFile(p)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, logs...)
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() || !(isLog(name) || strings.EqualFold(filepath.Ext(name), ".zip")) {
+				continue
+			}
+			logs, err := listFile(filepath.Join(p, name))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, logs...)
+		}
+	}
+	Sort(out)
+	return out, nil
+}
+
+// listFile returns the log in a plain or gzip file, or the logs in a zip archive.
+func listFile(path string) ([]Log, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		l := Log{Path: path}
+		l.Time, _ = BackupTime(path)
+		return []Log{l}, nil
+	}
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	var out []Log
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() || !isLog(zf.Name) {
+			continue
+		}
+		l := Log{Path: path, Member: zf.Name}
+		l.Time, _ = BackupTime(zf.Name)
+		out = append(out, l)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Member < out[j].Member })
+	return out, nil
+}
+
+// Sort orders backups by the time in their name, before the other logs, which keep their order.
+func Sort(logs []Log) {
+	sort.SliceStable(logs, func(i, j int) bool {
+		a, b := logs[i].Time, logs[j].Time
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+		return a.Before(b)
+	})
+}
@@ -0,0 +1,113 @@
+package logfiles
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeGzip(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	defer f.Close()
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write([]byte(data)); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+}
+
+func writeZip(t *testing.T, path string, members map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, data := range members {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip: %v", err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatalf("zip: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+}
+
+func readAll(t *testing.T, l Log) string {
+	t.Helper()
+	rc, err := l.Open()
+	if err != nil {
+		t.Fatalf("open %s: %v", l, err)
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("read %s: %v", l, err)
+	}
+	return string(b)
+}
+
+func TestBackupTime(t *testing.T) {
+	got, ok := BackupTime("/logs/UE_game-backup-2025.11.04-19.20.45.log.gz")
+	if want := time.Date(2025, 11, 4, 19, 20, 45, 0, time.Local); !ok || !got.Equal(want) {
+		t.Fatalf("BackupTime = %v, %v; want %v", got, ok, want)
+	}
+	for _, name := range []string{"UE_game.log", "UE_game-backup-2025.13.04-19.20.45.log", "notes-backup-2025.11.04.log"} {
+		if _, ok := BackupTime(name); ok {
+			t.Fatalf("BackupTime(%q) should not match", name)
+		}
+	}
+}
+
+func TestListOrdersBackupsAndOpensArchives(t *testing.T) {
+	dir := t.TempDir()
+	live := filepath.Join(dir, "UE_game.log")
+	if err := os.WriteFile(live, []byte("live\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("skip\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	writeGzip(t, filepath.Join(dir, "UE_game-backup-2025.11.05-08.00.00.log.gz"), "third\n")
+	writeZip(t, filepath.Join(dir, "season.zip"), map[string]string{
+		"logs/UE_game-backup-2025.11.04-19.20.45.log": "second\n",
+		"logs/UE_game-backup-2025.11.01-10.00.00.log": "first\n",
+		"readme.md": "skip\n",
+	})
+
+	logs, err := List([]string{dir})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	want := []string{"first\n", "second\n", "third\n", "live\n"}
+	if len(logs) != len(want) {
+		t.Fatalf("got %d logs %v, want %d", len(logs), logs, len(want))
+	}
+	for i, l := range logs {
+		if got := readAll(t, l); got != want[i] {
+			t.Fatalf("log %d (%s) = %q, want %q", i, l, got, want[i])
+		}
+	}
+	if logs[0].String() != filepath.Join(dir, "season.zip")+":logs/UE_game-backup-2025.11.01-10.00.00.log" {
+		t.Fatalf("unexpected name %s", logs[0])
+	}
+
+	if _, err := List([]string{filepath.Join(dir, "missing.log")}); err == nil {
+		t.Fatal("expected an error for a missing path")
+	}
+}
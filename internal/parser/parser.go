@@ -15,6 +15,7 @@ type Parser struct {
 	bagInit    *regexp.Regexp
 	bagMod     *regexp.Regexp
 	transition *regexp.Regexp // captures NextSceneName path
+	lastScene  *regexp.Regexp // captures LastSceneName path
 	tsPrefix   *regexp.Regexp // captures timestamp components
 }
 
@@ -27,14 +28,19 @@ func New() *Parser {
 
 	// Transition with NextSceneName = World'/Game/Art/Maps...'
 	transition := regexp.MustCompile(`PageApplyBase@ _UpdateGameEnd: .*?NextSceneName = World'(/Game/Art/Maps[^']*)'`)
+	// LastSceneName is either a bare path or wrapped as World'...'
+	lastScene := regexp.MustCompile(`LastSceneName = (?:World')?([^'\s]+)`)
 
 	// Timestamp prefix: [YYYY.MM.DD-HH.MM.SS:ms][...]
 	tsPrefix := regexp.MustCompile(`^\[(\d{4})\.(\d{2})\.(\d{2})-(\d{2})\.(\d{2})\.(\d{2}):(\d{3})\]`)
 
-	return &Parser{bagInit: bagInit, bagMod: bagMod, transition: transition, tsPrefix: tsPrefix}
+	return &Parser{bagInit: bagInit, bagMod: bagMod, transition: transition, lastScene: lastScene, tsPrefix: tsPrefix}
 }
 
-const refugePath = "/Game/Art/Maps/01SD/XZ_YuJinZhiXiBiNanSuo200/XZ_YuJinZhiXiBiNanSuo200.XZ_YuJinZhiXiBiNanSuo200"
+const (
+	mapsRoot   = "/Game/Art/Maps/"
+	refugePath = "/Game/Art/Maps/01SD/XZ_YuJinZhiXiBiNanSuo200/XZ_YuJinZhiXiBiNanSuo200.XZ_YuJinZhiXiBiNanSuo200"
+)
 
 // Parse attempts to parse a line into an Event. Returns nil if unrecognized.
 func (p *Parser) Parse(line string) *types.Event {
@@ -48,20 +54,53 @@ func (p *Parser) Parse(line string) *types.Event {
 	}
 	if m := p.transition.FindStringSubmatch(line); m != nil {
 		path := m[1]
-		if strings.HasPrefix(path, "/Game/Art/Maps/") {
+		if strings.HasPrefix(path, mapsRoot) {
+			scene := ParseScenePath(path)
+			if lm := p.lastScene.FindStringSubmatch(line); lm != nil {
+				scene.PrevPath = lm[1]
+			}
 			if path == refugePath {
-				return &types.Event{Kind: types.EventMapEnd, Time: ts, Line: line}
+				return &types.Event{Kind: types.EventMapEnd, Time: ts, Line: line, Scene: scene}
 			}
-			return &types.Event{Kind: types.EventMapStart, Time: ts, Line: line}
+			return &types.Event{Kind: types.EventMapStart, Time: ts, Line: line, Scene: scene}
 		}
 	}
 	return nil
 }
 
+// ParseScenePath derives the map key and region folder from a scene path such as
+// /Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200.
+func ParseScenePath(path string) *types.SceneEvent {
+	scene := &types.SceneEvent{Path: path}
+	rest := strings.TrimPrefix(path, mapsRoot)
+	if rest == path {
+		return scene
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) > 1 {
+		scene.Region = parts[0]
+	}
+	last := parts[len(parts)-1]
+	if i := strings.IndexByte(last, '.'); i >= 0 {
+		last = last[:i]
+	}
+	scene.MapKey = last
+	return scene
+}
+
 func (p *Parser) parseTimestamp(line string) time.Time {
+	if ts, ok := p.Timestamp(line); ok {
+		return ts
+	}
+	return time.Now()
+}
+
+// Timestamp reads the [YYYY.MM.DD-HH.MM.SS:ms] prefix of a log line. It reports false for
+// lines without one, such as continuation lines.
+func (p *Parser) Timestamp(line string) (time.Time, bool) {
 	m := p.tsPrefix.FindStringSubmatch(line)
 	if m == nil {
-		return time.Now()
+		return time.Time{}, false
 	}
 	y := atoi(m[1])
 	mon := atoi(m[2])
@@ -70,7 +109,7 @@ func (p *Parser) parseTimestamp(line string) time.Time {
 	min := atoi(m[5])
 	s := atoi(m[6])
 	ms := atoi(m[7])
-	return time.Date(y, time.Month(mon), d, h, min, s, ms*1e6, time.Local)
+	return time.Date(y, time.Month(mon), d, h, min, s, ms*1e6, time.Local), true
 }
 
 func parseBag(matches []string) *types.BagEvent {
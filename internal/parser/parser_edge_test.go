@@ -44,3 +44,19 @@ func TestPrefixWithMultipleBrackets(t *testing.T) {
 		t.Fatalf("expected BagInit with multiple bracket prefix, got %#v", ev)
 	}
 }
+
+func TestParseScenePathShapes(t *testing.T) {
+	cases := []struct {
+		path, key, region string
+	}{
+		{"/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200", "YJ_YongZhouHuiLang200", "07YJ"},
+		{"/Game/Art/Maps/Solo", "Solo", ""},
+		{"/NotMaps/Somewhere", "", ""},
+	}
+	for _, c := range cases {
+		s := ParseScenePath(c.path)
+		if s.MapKey != c.key || s.Region != c.region || s.Path != c.path {
+			t.Fatalf("ParseScenePath(%q) = %#v; want key=%q region=%q", c.path, s, c.key, c.region)
+		}
+	}
+}
@@ -57,3 +57,30 @@ func TestTimestampParsing(t *testing.T) {
 		t.Fatalf("unexpected parsed timestamp: %v", got)
 	}
 }
+
+func TestParseMapStartCapturesScene(t *testing.T) {
+	p := New()
+	start := "[2025.11.04-19.20.45:474][302]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = /Game/Art/Maps/UI/LoginScene/LoginScene NextSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200'"
+	ev := p.Parse(start)
+	if ev == nil || ev.Scene == nil {
+		t.Fatalf("expected MapStart with scene, got %#v", ev)
+	}
+	if ev.Scene.MapKey != "YJ_YongZhouHuiLang200" || ev.Scene.Region != "07YJ" {
+		t.Fatalf("unexpected map identity: %#v", ev.Scene)
+	}
+	if ev.Scene.Path != "/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200" {
+		t.Fatalf("unexpected scene path: %q", ev.Scene.Path)
+	}
+	if ev.Scene.PrevPath != "/Game/Art/Maps/UI/LoginScene/LoginScene" {
+		t.Fatalf("unexpected previous scene: %q", ev.Scene.PrevPath)
+	}
+
+	end := "[2025.11.04-19.26.24:480][420]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200' NextSceneName = World'" + refugePath + "'"
+	ev = p.Parse(end)
+	if ev == nil || ev.Scene == nil || ev.Scene.MapKey != "XZ_YuJinZhiXiBiNanSuo200" {
+		t.Fatalf("expected MapEnd with refuge scene, got %#v", ev)
+	}
+	if ev.Scene.PrevPath != "/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200" {
+		t.Fatalf("unexpected previous scene on MapEnd: %q", ev.Scene.PrevPath)
+	}
+}
@@ -0,0 +1,289 @@
+package pricehistory
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"GoTorch/internal/pricing"
+)
+
+// ErrNoData is returned when an item has too few (nonzero) price points for a query.
+var ErrNoData = errors.New("pricehistory: not enough price points")
+
+// Point is one observed price of an item.
+type Point struct {
+	ItemID    string    `json:"item_id"`
+	At        time.Time `json:"at"` // the price's last_update, or the fetch time when the source has none
+	Price     float64   `json:"price"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Source    string    `json:"source,omitempty"`
+}
+
+// Change is the price movement of an item over a window.
+type Change struct {
+	ItemID  string    `json:"item_id"`
+	From    float64   `json:"from"`
+	To      float64   `json:"to"`
+	FromAt  time.Time `json:"from_at"`
+	ToAt    time.Time `json:"to_at"`
+	Percent float64   `json:"percent"` // (To-From)/From*100
+}
+
+// Store is a JSON-lines file of price points. It is safe for concurrent use.
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// DefaultPath returns the price history file location, honoring GOTORCH_PRICE_HISTORY.
+func DefaultPath() string {
+	if p := os.Getenv("GOTORCH_PRICE_HISTORY"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "gotorch_price_history.jsonl"
+	}
+	return filepath.Join(dir, "GoTorch", "price_history.jsonl")
+}
+
+// Open returns a store backed by path. The file is created on first write.
+func Open(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the file backing the store.
+func (s *Store) Path() string { return s.path }
+
+// Record appends the fetched prices. A point identical to the item's latest one (same time and price)
+// is skipped, so repeated fetches of unchanged prices do not grow the file. It returns how many points were added.
+func (s *Store) Record(updates map[string]pricing.PriceUpdate, fetchedAt time.Time, source string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.readAll()
+	if err != nil {
+		return 0, err
+	}
+	latest := make(map[string]Point)
+	for _, p := range all {
+		if l, ok := latest[p.ItemID]; !ok || !p.At.Before(l.At) {
+			latest[p.ItemID] = p
+		}
+	}
+	ids := make([]string, 0, len(updates))
+	for id := range updates {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var buf []byte
+	var added int
+	for _, id := range ids {
+		u := updates[id]
+		p := Point{ItemID: id, At: fetchedAt, Price: u.Price, FetchedAt: fetchedAt, Source: source}
+		if u.LastUpdate > 0 {
+			p.At = time.Unix(int64(u.LastUpdate), 0)
+		}
+		if l, ok := latest[id]; ok && l.At.Equal(p.At) && l.Price == p.Price {
+			continue
+		}
+		b, err := json.Marshal(p)
+		if err != nil {
+			return 0, err
+		}
+		buf = append(append(buf, b...), '\n')
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if _, err := f.Write(buf); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// Series returns the points of an item ordered by time. Zero since/until are unbounded.
+func (s *Store) Series(id string, since, until time.Time) ([]Point, error) {
+	s.mu.Lock()
+	all, err := s.readAll()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var out []Point
+	for _, p := range all {
+		if p.ItemID != id {
+			continue
+		}
+		if !since.IsZero() && p.At.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !p.At.Before(until) {
+			continue
+		}
+		out = append(out, p)
+	}
+	sortPoints(out)
+	return out, nil
+}
+
+// Change returns the movement of an item from its price at now-window to its latest price at or before now.
+// The starting price is the last point at or before the window start, or the first point inside the window.
+func (s *Store) Change(id string, window time.Duration, now time.Time) (Change, error) {
+	series, err := s.Series(id, time.Time{}, now.Add(time.Nanosecond))
+	if err != nil {
+		return Change{}, err
+	}
+	c, ok := change(id, series, now.Add(-window))
+	if !ok {
+		return Change{}, ErrNoData
+	}
+	return c, nil
+}
+
+// Movers returns the items with the largest absolute percent change over the window, at most top (0 = all).
+func (s *Store) Movers(window time.Duration, now time.Time, top int) ([]Change, error) {
+	s.mu.Lock()
+	all, err := s.readAll()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	byItem := make(map[string][]Point)
+	for _, p := range all {
+		if p.At.After(now) {
+			continue
+		}
+		byItem[p.ItemID] = append(byItem[p.ItemID], p)
+	}
+	start := now.Add(-window)
+	var out []Change
+	for id, series := range byItem {
+		sortPoints(series)
+		if c, ok := change(id, series, start); ok && c.Percent != 0 {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := math.Abs(out[i].Percent), math.Abs(out[j].Percent)
+		if a != b {
+			return a > b
+		}
+		return out[i].ItemID < out[j].ItemID
+	})
+	if top > 0 && len(out) > top {
+		out = out[:top]
+	}
+	return out, nil
+}
+
+// Index is an in-memory view of the store for fast price-at-time lookups, e.g. when replaying old logs.
+type Index struct {
+	series map[string][]Point
+}
+
+// Index loads every point into an Index.
+func (s *Store) Index() (*Index, error) {
+	s.mu.Lock()
+	all, err := s.readAll()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	ix := &Index{series: make(map[string][]Point)}
+	for _, p := range all {
+		ix.series[p.ItemID] = append(ix.series[p.ItemID], p)
+	}
+	for _, ps := range ix.series {
+		sortPoints(ps)
+	}
+	return ix, nil
+}
+
+// PriceAt returns the item's latest recorded price at or before at.
+func (ix *Index) PriceAt(id string, at time.Time) (float64, bool) {
+	ps := ix.series[id]
+	i := sort.Search(len(ps), func(i int) bool { return ps[i].At.After(at) })
+	if i == 0 {
+		return 0, false
+	}
+	return ps[i-1].Price, true
+}
+
+// change computes the movement over a time-ordered series from start to its last point.
+// It fails when there is no movement to measure or the starting price is zero.
+func change(id string, series []Point, start time.Time) (Change, bool) {
+	if len(series) < 2 {
+		return Change{}, false
+	}
+	from := -1
+	for i, p := range series {
+		if p.At.After(start) {
+			if from < 0 {
+				from = i
+			}
+			break
+		}
+		from = i
+	}
+	last := series[len(series)-1]
+	if from < 0 || from == len(series)-1 {
+		return Change{}, false
+	}
+	first := series[from]
+	if first.Price == 0 {
+		// no meaningful percent change from a zero price
+		return Change{}, false
+	}
+	return Change{
+		ItemID:  id,
+		From:    first.Price,
+		To:      last.Price,
+		FromAt:  first.At,
+		ToAt:    last.At,
+		Percent: (last.Price - first.Price) / first.Price * 100,
+	}, true
+}
+
+func sortPoints(ps []Point) {
+	sort.SliceStable(ps, func(i, j int) bool { return ps[i].At.Before(ps[j].At) })
+}
+
+// readAll reads the file with s.mu held. Malformed lines (e.g. a torn write) are skipped.
+func (s *Store) readAll() ([]Point, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []Point
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var p Point
+		if err := json.Unmarshal(sc.Bytes(), &p); err != nil || p.ItemID == "" {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out, sc.Err()
+}
@@ -0,0 +1,99 @@
+package pricehistory
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"GoTorch/internal/pricing"
+)
+
+func day(n int) time.Time { return time.Date(2025, 11, n, 12, 0, 0, 0, time.UTC) }
+
+func lu(n int) float64 { return float64(day(n).Unix()) }
+
+func TestRecordSkipsUnchangedPoints(t *testing.T) {
+	s := Open(filepath.Join(t.TempDir(), "prices.jsonl"))
+	n, err := s.Record(map[string]pricing.PriceUpdate{"1": {Price: 10, LastUpdate: lu(1)}, "2": {Price: 5}}, day(1), "remote")
+	if err != nil || n != 2 {
+		t.Fatalf("first record: n=%d err=%v", n, err)
+	}
+	// same price and last_update for 1 is skipped; 2 has no last_update so it is keyed by fetch time
+	n, err = s.Record(map[string]pricing.PriceUpdate{"1": {Price: 10, LastUpdate: lu(1)}, "2": {Price: 5}}, day(2), "remote")
+	if err != nil || n != 1 {
+		t.Fatalf("second record: n=%d err=%v", n, err)
+	}
+	series, err := s.Series("2", time.Time{}, time.Time{})
+	if err != nil || len(series) != 2 || !series[1].At.Equal(day(2)) || series[0].Source != "remote" {
+		t.Fatalf("unexpected series: %+v, %v", series, err)
+	}
+}
+
+func TestChangeAndMovers(t *testing.T) {
+	s := Open(filepath.Join(t.TempDir(), "prices.jsonl"))
+	for d, prices := range map[int]map[string]float64{
+		1: {"a": 10, "b": 100, "c": 1},
+		5: {"a": 12, "b": 50},
+		8: {"a": 15, "b": 60, "c": 1},
+	} {
+		updates := make(map[string]pricing.PriceUpdate)
+		for id, p := range prices {
+			updates[id] = pricing.PriceUpdate{Price: p, LastUpdate: lu(d)}
+		}
+		if _, err := s.Record(updates, day(d), "test"); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	// window starting day 4: a goes 10 (day 1, last before the window) -> 15
+	c, err := s.Change("a", 4*24*time.Hour, day(8))
+	if err != nil || c.From != 10 || c.To != 15 || c.Percent != 50 {
+		t.Fatalf("Change(a) = %+v, %v", c, err)
+	}
+	// window starting day 6: a goes 12 -> 15
+	if c, _ := s.Change("a", 2*24*time.Hour, day(8)); c.From != 12 || c.Percent != 25 {
+		t.Fatalf("Change(a, 2d) = %+v", c)
+	}
+	// as of day 5, b fell 50%
+	if c, _ := s.Change("b", 10*24*time.Hour, day(5)); c.Percent != -50 {
+		t.Fatalf("Change(b) as of day 5 = %+v", c)
+	}
+	if _, err := s.Change("zzz", time.Hour, day(8)); !errors.Is(err, ErrNoData) {
+		t.Fatalf("expected ErrNoData, got %v", err)
+	}
+
+	movers, err := s.Movers(10*24*time.Hour, day(8), 0)
+	if err != nil || len(movers) != 2 || movers[0].ItemID != "a" || movers[1].ItemID != "b" || math.Abs(movers[1].Percent+40) > 1e-9 {
+		t.Fatalf("unexpected movers (c is flat and omitted): %+v, %v", movers, err)
+	}
+	if top, _ := s.Movers(10*24*time.Hour, day(8), 1); len(top) != 1 {
+		t.Fatalf("expected top 1, got %+v", top)
+	}
+}
+
+func TestIndexPriceAt(t *testing.T) {
+	s := Open(filepath.Join(t.TempDir(), "prices.jsonl"))
+	for d, p := range map[int]float64{1: 10, 5: 20} {
+		if _, err := s.Record(map[string]pricing.PriceUpdate{"a": {Price: p, LastUpdate: lu(d)}}, day(d), "test"); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	ix, err := s.Index()
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if _, ok := ix.PriceAt("a", day(1).Add(-time.Second)); ok {
+		t.Fatal("expected no price before the first point")
+	}
+	if p, ok := ix.PriceAt("a", day(3)); !ok || p != 10 {
+		t.Fatalf("PriceAt(day 3) = %v, %v", p, ok)
+	}
+	if p, _ := ix.PriceAt("a", day(5)); p != 20 {
+		t.Fatalf("PriceAt(day 5) = %v", p)
+	}
+	if _, ok := ix.PriceAt("b", day(5)); ok {
+		t.Fatal("expected no price for unknown item")
+	}
+}
@@ -0,0 +1,127 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultMaxAge is how old a price's last_update may be before it is reported as stale.
+const DefaultMaxAge = 24 * time.Hour
+
+// Price age statuses reported to the UI and CLI.
+const (
+	StatusFresh   = "fresh"
+	StatusStale   = "stale"
+	StatusUnknown = "unknown"
+)
+
+// ErrOffline is returned when offline mode is on and no cached prices exist.
+var ErrOffline = errors.New("offline: no cached prices")
+
+// ErrStale is returned together with the cached prices when the endpoint could not be reached.
+var ErrStale = errors.New("using cached prices")
+
+// Staleness classifies a price by its last_update (Unix seconds): unknown when unset, stale when older than maxAge.
+func Staleness(lastUpdate float64, now time.Time, maxAge time.Duration) string {
+	if lastUpdate <= 0 {
+		return StatusUnknown
+	}
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	if now.Sub(time.Unix(int64(lastUpdate), 0)) > maxAge {
+		return StatusStale
+	}
+	return StatusFresh
+}
+
+// Cache is the last successful remote fetch, kept on disk for conditional requests and offline use.
+type Cache struct {
+	Endpoint     string                 `json:"endpoint"`
+	FetchedAt    time.Time              `json:"fetched_at"`
+	ETag         string                 `json:"etag,omitempty"`
+	LastModified string                 `json:"last_modified,omitempty"`
+	Prices       map[string]PriceUpdate `json:"prices"`
+}
+
+// LoadCache reads a cache file written by SaveCache.
+func LoadCache(path string) (*Cache, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cache
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// SaveCache writes c to path atomically.
+func SaveCache(path string, c *Cache) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// fetchConditional performs a GET with If-None-Match/If-Modified-Since taken from prev.
+// On 304 Not Modified it returns prev's prices with a refreshed fetch time.
+func fetchConditional(ctx context.Context, endpoint string, prev *Cache) (*Cache, error) {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "GoTorch/price-updater")
+	if prev != nil && prev.Endpoint == endpoint {
+		if prev.ETag != "" {
+			req.Header.Set("If-None-Match", prev.ETag)
+		}
+		if prev.LastModified != "" {
+			req.Header.Set("If-Modified-Since", prev.LastModified)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified && prev != nil && prev.Endpoint == endpoint {
+		c := *prev
+		c.FetchedAt = time.Now()
+		return &c, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("pricing endpoint non-200: " + resp.Status)
+	}
+	var out map[string]PriceUpdate
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return &Cache{
+		Endpoint:     endpoint,
+		FetchedAt:    time.Now(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Prices:       out,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStaleness(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	cases := []struct {
+		lu   float64
+		want string
+	}{
+		{0, StatusUnknown},
+		{float64(now.Add(-time.Hour).Unix()), StatusFresh},
+		{float64(now.Add(-48 * time.Hour).Unix()), StatusStale},
+	}
+	for _, c := range cases {
+		if got := Staleness(c.lu, now, DefaultMaxAge); got != c.want {
+			t.Fatalf("Staleness(%v) = %q want %q", c.lu, got, c.want)
+		}
+	}
+}
+
+func TestRemoteCacheConditionalAndOffline(t *testing.T) {
+	var requests, notModified int
+	up := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if !up {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_ = json.NewEncoder(w).Encode(map[string]PriceUpdate{"1": {Price: 2, LastUpdate: 10}})
+	}))
+	defer ts.Close()
+	cache := filepath.Join(t.TempDir(), "price_cache.json")
+	ctx := context.Background()
+
+	// offline with no cache fails without a request
+	if _, err := (Remote{Endpoint: ts.URL, CachePath: cache, Offline: true}).Prices(ctx); !errors.Is(err, ErrOffline) || requests != 0 {
+		t.Fatalf("offline without cache: err=%v requests=%d", err, requests)
+	}
+
+	r := Remote{Endpoint: ts.URL, CachePath: cache}
+	if got, err := r.Prices(ctx); err != nil || got["1"].Price != 2 {
+		t.Fatalf("first fetch: %#v, %v", got, err)
+	}
+	c, err := LoadCache(cache)
+	if err != nil || c.ETag != `"v1"` || c.FetchedAt.IsZero() {
+		t.Fatalf("cache not saved: %#v, %v", c, err)
+	}
+
+	// second fetch is conditional and served from the cache
+	if got, err := r.Prices(ctx); err != nil || got["1"].Price != 2 || notModified != 1 {
+		t.Fatalf("conditional fetch: %#v, %v, 304s=%d", got, err, notModified)
+	}
+
+	// server down: fall back to the cache, reporting the failure
+	up = false
+	if got, err := r.Prices(ctx); !errors.Is(err, ErrStale) || got["1"].Price != 2 {
+		t.Fatalf("fallback: %#v, %v", got, err)
+	}
+
+	// offline never hits the network
+	before := requests
+	if got, err := (Remote{Endpoint: ts.URL, CachePath: cache, Offline: true}).Prices(ctx); err != nil || got["1"].Price != 2 || requests != before {
+		t.Fatalf("offline: %#v, %v, requests %d->%d", got, err, before, requests)
+	}
+}
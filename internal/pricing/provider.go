@@ -0,0 +1,343 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Provider is a source of item prices keyed by item id (ConfigBaseID).
+type Provider interface {
+	Name() string
+	Prices(ctx context.Context) (map[string]PriceUpdate, error)
+}
+
+// Remote fetches prices from an HTTP endpoint returning map[string]PriceUpdate.
+// With a CachePath, the last successful fetch is kept on disk, later requests are conditional,
+// and the cached prices are served with an ErrStale error when the endpoint is unreachable.
+// Offline never touches the network.
+type Remote struct {
+	Endpoint  string // empty means DefaultEndpoint
+	CachePath string
+	Offline   bool
+}
+
+func (r Remote) Name() string { return "remote" }
+
+func (r Remote) Prices(ctx context.Context) (map[string]PriceUpdate, error) {
+	var cached *Cache
+	if r.CachePath != "" {
+		cached, _ = LoadCache(r.CachePath)
+	}
+	if r.Offline {
+		if cached == nil {
+			return nil, ErrOffline
+		}
+		return cached.Prices, nil
+	}
+	c, err := fetchConditional(ctx, r.Endpoint, cached)
+	if err != nil {
+		if cached != nil {
+			return cached.Prices, fmt.Errorf("%w: %w", ErrStale, err)
+		}
+		return nil, err
+	}
+	if r.CachePath != "" {
+		_ = SaveCache(r.CachePath, c)
+	}
+	return c.Prices, nil
+}
+
+// File reads prices from a local JSON file. Values may be plain numbers or {"price","last_update"} objects.
+// Keys are item ids, unless Names is set, in which case keys are item names mapped to ids through it.
+// Entries without a last_update get the file's modification time.
+type File struct {
+	Label    string            // provider name, e.g. "local" or "overrides"
+	Path     string
+	Names    map[string]string // optional name -> id mapping
+	Optional bool              // a missing file yields no prices instead of an error
+}
+
+func (f File) Name() string { return f.Label }
+
+func (f File) Prices(ctx context.Context) (map[string]PriceUpdate, error) {
+	b, err := os.ReadFile(f.Path)
+	if err != nil {
+		if f.Optional && errors.Is(err, os.ErrNotExist) {
+			return map[string]PriceUpdate{}, nil
+		}
+		return nil, err
+	}
+	var mtime float64
+	if fi, err := os.Stat(f.Path); err == nil {
+		mtime = float64(fi.ModTime().Unix())
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Path, err)
+	}
+	out := make(map[string]PriceUpdate, len(raw))
+	for key, v := range raw {
+		id := key
+		if f.Names != nil {
+			var ok bool
+			if id, ok = f.Names[key]; !ok {
+				continue
+			}
+		}
+		u, err := decodePrice(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q: %w", f.Path, key, err)
+		}
+		if u.LastUpdate == 0 {
+			u.LastUpdate = mtime
+		}
+		out[id] = u
+	}
+	return out, nil
+}
+
+// decodePrice accepts either a bare number or a PriceUpdate object.
+func decodePrice(v json.RawMessage) (PriceUpdate, error) {
+	var n float64
+	if err := json.Unmarshal(v, &n); err == nil {
+		return PriceUpdate{Price: n}, nil
+	}
+	var u PriceUpdate
+	err := json.Unmarshal(v, &u)
+	return u, err
+}
+
+// LoadNames reads an id table such as en_id_table.json (id -> {"name", "name_cn"})
+// and returns a name -> id mapping covering both English and Chinese names.
+// When a name is shared by several ids the lowest id wins.
+func LoadNames(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rows map[string]struct {
+		Name   string `json:"name"`
+		NameCN string `json:"name_cn"`
+	}
+	if err := json.Unmarshal(b, &rows); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(rows))
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if len(ids[i]) != len(ids[j]) {
+			return len(ids[i]) < len(ids[j])
+		}
+		return ids[i] < ids[j]
+	})
+	names := make(map[string]string, 2*len(rows))
+	for _, id := range ids {
+		for _, n := range []string{rows[id].Name, rows[id].NameCN} {
+			if _, taken := names[n]; n != "" && !taken {
+				names[n] = id
+			}
+		}
+	}
+	return names, nil
+}
+
+// Static serves a fixed price table, e.g. the one embedded in the binary.
+type Static struct {
+	Label  string
+	Values map[string]PriceUpdate
+}
+
+func (s Static) Name() string { return s.Label }
+
+func (s Static) Prices(ctx context.Context) (map[string]PriceUpdate, error) {
+	out := make(map[string]PriceUpdate, len(s.Values))
+	for id, u := range s.Values {
+		out[id] = u
+	}
+	return out, nil
+}
+
+// Policy decides how prices offered by several providers are combined.
+type Policy string
+
+const (
+	FirstWins Policy = "first-wins" // the highest-priority provider with a price wins
+	Freshest  Policy = "freshest"   // the price with the newest last_update wins; ties go to priority
+	Median    Policy = "median"     // the median price across providers, with the newest last_update
+)
+
+// ParsePolicy validates a policy name; empty means FirstWins.
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return FirstWins, nil
+	case FirstWins, Freshest, Median:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown price merge policy %q (want first-wins, freshest or median)", s)
+}
+
+// Multi combines providers listed in priority order using Policy.
+// Failing providers are reported to OnError and skipped, except that cached prices returned with
+// ErrStale are still used. Prices fails only if every provider fails; when no provider had fresh
+// prices it returns the cached ones with the errors, which then match ErrStale.
+type Multi struct {
+	Providers []Provider
+	Policy    Policy
+	OnError   func(provider string, err error)
+}
+
+func (m Multi) Name() string {
+	names := make([]string, len(m.Providers))
+	for i, p := range m.Providers {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, "+")
+}
+
+func (m Multi) Prices(ctx context.Context) (map[string]PriceUpdate, error) {
+	var results []map[string]PriceUpdate
+	var errs []error
+	fresh := false
+	for _, p := range m.Providers {
+		r, err := p.Prices(ctx)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", p.Name(), err)
+			errs = append(errs, err)
+			if m.OnError != nil {
+				m.OnError(p.Name(), err)
+			}
+			if r == nil || !errors.Is(err, ErrStale) {
+				continue
+			}
+		} else {
+			fresh = true
+		}
+		results = append(results, r)
+	}
+	if len(results) == 0 {
+		if len(errs) == 0 {
+			return nil, errors.New("no price providers configured")
+		}
+		return nil, errors.Join(errs...)
+	}
+	if !fresh {
+		return Merge(m.Policy, results...), errors.Join(errs...)
+	}
+	return Merge(m.Policy, results...), nil
+}
+
+// Merge combines price maps given in priority order.
+func Merge(policy Policy, results ...map[string]PriceUpdate) map[string]PriceUpdate {
+	offers := make(map[string][]PriceUpdate)
+	for _, r := range results {
+		for id, u := range r {
+			offers[id] = append(offers[id], u)
+		}
+	}
+	out := make(map[string]PriceUpdate, len(offers))
+	for id, us := range offers {
+		switch policy {
+		case Freshest:
+			best := us[0]
+			for _, u := range us[1:] {
+				if u.LastUpdate > best.LastUpdate {
+					best = u
+				}
+			}
+			out[id] = best
+		case Median:
+			prices := make([]float64, len(us))
+			var lu float64
+			for i, u := range us {
+				prices[i] = u.Price
+				if u.LastUpdate > lu {
+					lu = u.LastUpdate
+				}
+			}
+			sort.Float64s(prices)
+			mid := len(prices) / 2
+			med := prices[mid]
+			if len(prices)%2 == 0 {
+				med = (prices[mid-1] + prices[mid]) / 2
+			}
+			out[id] = PriceUpdate{Price: med, LastUpdate: lu}
+		default:
+			out[id] = us[0]
+		}
+	}
+	return out
+}
+
+// Config describes a set of price sources in priority order.
+type Config struct {
+	Sources       []string // any of "remote", "local", "overrides", "embedded"; empty means just remote
+	Policy        Policy
+	Endpoint      string                 // remote endpoint; empty means DefaultEndpoint
+	CachePath     string                 // optional on-disk cache of the last remote fetch
+	Offline       bool                   // serve remote prices from the cache only
+	LocalPath     string                 // local price file, e.g. price.json
+	NamesPath     string                 // optional id table mapping names in LocalPath to ids, e.g. en_id_table.json
+	OverridesPath string                 // user overrides keyed by id; may be missing
+	Embedded      map[string]PriceUpdate // static table bundled into the binary
+	OnError       func(provider string, err error)
+}
+
+// ParseSources splits a comma-separated source list.
+func ParseSources(s string) []string {
+	var out []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.ToLower(strings.TrimSpace(f)); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Build creates the provider described by c.
+func (c Config) Build() (Provider, error) {
+	sources := c.Sources
+	if len(sources) == 0 {
+		sources = []string{"remote"}
+	}
+	m := Multi{Policy: c.Policy, OnError: c.OnError}
+	if m.Policy == "" {
+		m.Policy = FirstWins
+	}
+	for _, s := range sources {
+		switch s {
+		case "remote":
+			m.Providers = append(m.Providers, Remote{Endpoint: c.Endpoint, CachePath: c.CachePath, Offline: c.Offline})
+		case "local":
+			if c.LocalPath == "" {
+				return nil, errors.New("local price source needs a price file")
+			}
+			f := File{Label: "local", Path: c.LocalPath}
+			if c.NamesPath != "" {
+				names, err := LoadNames(c.NamesPath)
+				if err != nil {
+					return nil, fmt.Errorf("load names: %w", err)
+				}
+				f.Names = names
+			}
+			m.Providers = append(m.Providers, f)
+		case "overrides":
+			if c.OverridesPath == "" {
+				return nil, errors.New("overrides price source needs a file")
+			}
+			m.Providers = append(m.Providers, File{Label: "overrides", Path: c.OverridesPath, Optional: true})
+		case "embedded":
+			m.Providers = append(m.Providers, Static{Label: "embedded", Values: c.Embedded})
+		default:
+			return nil, fmt.Errorf("unknown price source %q", s)
+		}
+	}
+	return m, nil
+}
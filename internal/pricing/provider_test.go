@@ -0,0 +1,151 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergePolicies(t *testing.T) {
+	a := map[string]PriceUpdate{"1": {Price: 1, LastUpdate: 100}, "2": {Price: 5, LastUpdate: 100}}
+	b := map[string]PriceUpdate{"1": {Price: 3, LastUpdate: 300}}
+	c := map[string]PriceUpdate{"1": {Price: 10, LastUpdate: 200}, "3": {Price: 7}}
+
+	first := Merge(FirstWins, a, b, c)
+	if first["1"].Price != 1 || first["2"].Price != 5 || first["3"].Price != 7 {
+		t.Fatalf("first-wins: %#v", first)
+	}
+	fresh := Merge(Freshest, a, b, c)
+	if fresh["1"] != (PriceUpdate{Price: 3, LastUpdate: 300}) {
+		t.Fatalf("freshest: %#v", fresh["1"])
+	}
+	med := Merge(Median, a, b, c)
+	if med["1"] != (PriceUpdate{Price: 3, LastUpdate: 300}) || med["2"].Price != 5 {
+		t.Fatalf("median: %#v", med)
+	}
+	if even := Merge(Median, a, b); even["1"].Price != 2 {
+		t.Fatalf("median of two: %#v", even["1"])
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	if p, err := ParsePolicy(""); err != nil || p != FirstWins {
+		t.Fatalf("default policy = %q, %v", p, err)
+	}
+	if p, err := ParsePolicy("Median"); err != nil || p != Median {
+		t.Fatalf("median policy = %q, %v", p, err)
+	}
+	if _, err := ParsePolicy("average"); err == nil {
+		t.Fatal("expected error for unknown policy")
+	}
+}
+
+func TestFileProviderWithNames(t *testing.T) {
+	dir := t.TempDir()
+	names := filepath.Join(dir, "en_id_table.json")
+	prices := filepath.Join(dir, "price.json")
+	_ = os.WriteFile(names, []byte(`{"100":{"name":"Ember","name_cn":"灰烬"},"20":{"name":"Ember"},"300":{"name":"Sand"}}`), 0644)
+	_ = os.WriteFile(prices, []byte(`{"灰烬":1.5,"Sand":{"price":2,"last_update":42},"未知":9}`), 0644)
+
+	cfg := Config{Sources: []string{"local"}, LocalPath: prices, NamesPath: names}
+	p, err := cfg.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	got, err := p.Prices(context.Background())
+	if err != nil {
+		t.Fatalf("Prices: %v", err)
+	}
+	if len(got) != 2 || got["100"].Price != 1.5 || got["100"].LastUpdate == 0 || got["300"] != (PriceUpdate{Price: 2, LastUpdate: 42}) {
+		t.Fatalf("unexpected prices: %#v", got)
+	}
+	m, _ := LoadNames(names)
+	if m["Ember"] != "20" {
+		t.Fatalf("shared name should map to lowest id, got %q", m["Ember"])
+	}
+}
+
+func TestMultiFallsBackWhenRemoteDown(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	overrides := filepath.Join(t.TempDir(), "overrides.json")
+	b, _ := json.Marshal(map[string]float64{"1": 4})
+	_ = os.WriteFile(overrides, b, 0644)
+
+	var failed []string
+	p, err := Config{
+		Sources:       []string{"overrides", "remote", "embedded"},
+		Endpoint:      ts.URL,
+		OverridesPath: overrides,
+		Embedded:      map[string]PriceUpdate{"1": {Price: 1}, "2": {Price: 2}},
+		OnError:       func(name string, err error) { failed = append(failed, name) },
+	}.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	got, err := p.Prices(context.Background())
+	if err != nil {
+		t.Fatalf("Prices: %v", err)
+	}
+	if got["1"].Price != 4 || got["2"].Price != 2 {
+		t.Fatalf("unexpected prices: %#v", got)
+	}
+	if len(failed) != 1 || failed[0] != "remote" {
+		t.Fatalf("expected remote failure to be reported, got %v", failed)
+	}
+	if p.Name() != "overrides+remote+embedded" {
+		t.Fatalf("Name = %q", p.Name())
+	}
+}
+
+func TestMultiAllFailAndMissingOverrides(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "none.json")
+	got, err := File{Label: "overrides", Path: missing, Optional: true}.Prices(context.Background())
+	if err != nil || len(got) != 0 {
+		t.Fatalf("optional missing file: %#v, %v", got, err)
+	}
+	_, err = Multi{Providers: []Provider{File{Label: "local", Path: missing}}}.Prices(context.Background())
+	if err == nil || !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not-exist error, got %v", err)
+	}
+	if _, err := (Config{Sources: []string{"bogus"}}).Build(); err == nil {
+		t.Fatal("expected error for unknown source")
+	}
+}
+
+func TestMultiUsesStaleCacheAndReportsIt(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	cache := filepath.Join(t.TempDir(), "cache.json")
+	if err := SaveCache(cache, &Cache{Prices: map[string]PriceUpdate{"1": {Price: 3}}}); err != nil {
+		t.Fatalf("SaveCache: %v", err)
+	}
+
+	var failed []string
+	onError := func(name string, err error) { failed = append(failed, name) }
+	remote := Remote{Endpoint: ts.URL, CachePath: cache}
+	got, err := Multi{Providers: []Provider{remote}, OnError: onError}.Prices(context.Background())
+	if !errors.Is(err, ErrStale) || got["1"].Price != 3 {
+		t.Fatalf("expected cached prices with ErrStale, got %#v, %v", got, err)
+	}
+	if len(failed) != 1 || failed[0] != "remote" {
+		t.Fatalf("expected remote failure to be reported, got %v", failed)
+	}
+
+	// fresh prices from another provider clear the error; the cache still fills in
+	failed = nil
+	embedded := Static{Label: "embedded", Values: map[string]PriceUpdate{"2": {Price: 2}}}
+	got, err = Multi{Providers: []Provider{remote, embedded}, OnError: onError}.Prices(context.Background())
+	if err != nil || got["1"].Price != 3 || got["2"].Price != 2 || len(failed) != 1 {
+		t.Fatalf("unexpected merge with stale cache: %#v, %v, failed %v", got, err, failed)
+	}
+}
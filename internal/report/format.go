@@ -0,0 +1,166 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Formats lists the output formats accepted by Write.
+var Formats = []string{"text", "json", "csv", "md"}
+
+// Write renders r to w in the named format.
+func Write(w io.Writer, r Report, format string) error {
+	switch format {
+	case "text", "":
+		return WriteText(w, r)
+	case "json":
+		return WriteJSON(w, r)
+	case "csv":
+		return WriteCSV(w, r)
+	case "md", "markdown":
+		return WriteMarkdown(w, r)
+	default:
+		return fmt.Errorf("report: unknown format %q (want one of %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// WriteJSON writes the report as indented JSON.
+func WriteJSON(w io.Writer, r Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes aligned plain-text tables.
+func WriteText(w io.Writer, r Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	t := r.Totals
+	fmt.Fprintf(tw, "Sessions: %d\nRuns: %d\nTime in maps: %s\nDrops: %d\nEarnings: %.2f\nEarnings/hour: %.1f\n",
+		t.Sessions, t.Runs, fmtMs(t.DurationMs), t.Drops, t.Earnings, t.EarningsPerHour)
+	fmt.Fprintf(tw, "Earnings at drop: %.2f\nEarnings/hour at drop: %.1f\n", t.AtDrop, t.PerHourAtDrop)
+
+	fmt.Fprintln(tw, "\nPer map")
+	fmt.Fprintln(tw, "Map\tRegion\tRuns\tMean\tMedian\tP90\tAvg earnings\tEarnings/hour\tAt drop/hour")
+	for _, m := range r.Maps {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%.2f\t%.1f\t%.1f\n", mapName(m.MapKey), m.Region, m.Runs,
+			fmtMs(m.MeanDurationMs), fmtMs(m.MedianDurationMs), fmtMs(m.P90DurationMs), m.MeanEarnings, m.EarningsPerHour, m.PerHourAtDrop)
+	}
+
+	fmt.Fprintln(tw, "\nTop items")
+	fmt.Fprintln(tw, "ID\tName\tCount\tUnit price\tValue")
+	for _, it := range r.TopItems {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%.4f\t%.2f\n", it.ID, itemName(it), it.Count, it.UnitPrice, it.Value)
+	}
+
+	fmt.Fprintln(tw, "\nRuns")
+	fmt.Fprintln(tw, "#\tSession\tStarted\tMap\tDuration\tDrops\tEarnings\tAt drop")
+	for i, run := range r.Runs {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%d\t%.2f\t%.2f\n", i+1, run.Session, run.StartedAt.Format("2006-01-02 15:04:05"),
+			mapName(run.MapKey), fmtMs(run.DurationMs), run.Drops, run.Earnings, run.AtDrop)
+	}
+	return tw.Flush()
+}
+
+// WriteCSV writes the runs, per-map, top item and total tables, separated by blank lines.
+func WriteCSV(w io.Writer, r Report) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{{"source", "session", "map_key", "region", "started_at", "ended_at", "duration_ms", "drops", "earnings", "earnings_at_drop"}}
+	for _, run := range r.Runs {
+		rows = append(rows, []string{run.Source, strconv.Itoa(run.Session), run.MapKey, run.Region,
+			run.StartedAt.Format(time.RFC3339), run.EndedAt.Format(time.RFC3339), ms(run.DurationMs), strconv.Itoa(run.Drops), money(run.Earnings), money(run.AtDrop)})
+	}
+	if err := writeCSVTable(w, cw, rows, false); err != nil {
+		return err
+	}
+	rows = [][]string{{"map_key", "region", "runs", "mean_duration_ms", "median_duration_ms", "p90_duration_ms", "mean_earnings", "earnings_per_hour", "mean_earnings_at_drop", "earnings_per_hour_at_drop"}}
+	for _, m := range r.Maps {
+		rows = append(rows, []string{m.MapKey, m.Region, strconv.Itoa(m.Runs), ms(m.MeanDurationMs), ms(m.MedianDurationMs),
+			ms(m.P90DurationMs), money(m.MeanEarnings), money(m.EarningsPerHour), money(m.MeanAtDrop), money(m.PerHourAtDrop)})
+	}
+	if err := writeCSVTable(w, cw, rows, true); err != nil {
+		return err
+	}
+	rows = [][]string{{"item_id", "name", "count", "unit_price", "value"}}
+	for _, it := range r.TopItems {
+		rows = append(rows, []string{strconv.Itoa(it.ID), it.Name, strconv.Itoa(it.Count), money(it.UnitPrice), money(it.Value)})
+	}
+	if err := writeCSVTable(w, cw, rows, true); err != nil {
+		return err
+	}
+	t := r.Totals
+	rows = [][]string{
+		{"sessions", "runs", "drops", "duration_ms", "earnings", "earnings_per_hour", "earnings_at_drop", "earnings_per_hour_at_drop"},
+		{strconv.Itoa(t.Sessions), strconv.Itoa(t.Runs), strconv.Itoa(t.Drops), ms(t.DurationMs), money(t.Earnings), money(t.EarningsPerHour),
+			money(t.AtDrop), money(t.PerHourAtDrop)},
+	}
+	return writeCSVTable(w, cw, rows, true)
+}
+
+func writeCSVTable(w io.Writer, cw *csv.Writer, rows [][]string, gap bool) error {
+	if gap {
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteMarkdown writes the report as Markdown tables.
+func WriteMarkdown(w io.Writer, r Report) error {
+	var b strings.Builder
+	t := r.Totals
+	b.WriteString("# Farming report\n\n")
+	b.WriteString("| Sessions | Runs | Time in maps | Drops | Earnings | Earnings/hour | At drop | At drop/hour |\n|---:|---:|---:|---:|---:|---:|---:|---:|\n")
+	fmt.Fprintf(&b, "| %d | %d | %s | %d | %.2f | %.1f | %.2f | %.1f |\n", t.Sessions, t.Runs, fmtMs(t.DurationMs), t.Drops,
+		t.Earnings, t.EarningsPerHour, t.AtDrop, t.PerHourAtDrop)
+
+	b.WriteString("\n## Per map\n\n| Map | Region | Runs | Mean | Median | P90 | Avg earnings | Earnings/hour | At drop/hour |\n|---|---|---:|---:|---:|---:|---:|---:|---:|\n")
+	for _, m := range r.Maps {
+		fmt.Fprintf(&b, "| %s | %s | %d | %s | %s | %s | %.2f | %.1f | %.1f |\n", mdEscape(mapName(m.MapKey)), mdEscape(m.Region), m.Runs,
+			fmtMs(m.MeanDurationMs), fmtMs(m.MedianDurationMs), fmtMs(m.P90DurationMs), m.MeanEarnings, m.EarningsPerHour, m.PerHourAtDrop)
+	}
+
+	b.WriteString("\n## Top items\n\n| ID | Name | Count | Unit price | Value |\n|---:|---|---:|---:|---:|\n")
+	for _, it := range r.TopItems {
+		fmt.Fprintf(&b, "| %d | %s | %d | %.4f | %.2f |\n", it.ID, mdEscape(itemName(it)), it.Count, it.UnitPrice, it.Value)
+	}
+
+	b.WriteString("\n## Runs\n\n| # | Session | Started | Map | Duration | Drops | Earnings | At drop |\n|---:|---:|---|---|---:|---:|---:|---:|\n")
+	for i, run := range r.Runs {
+		fmt.Fprintf(&b, "| %d | %d | %s | %s | %s | %d | %.2f | %.2f |\n", i+1, run.Session, run.StartedAt.Format("2006-01-02 15:04:05"),
+			mdEscape(mapName(run.MapKey)), fmtMs(run.DurationMs), run.Drops, run.Earnings, run.AtDrop)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func fmtMs(n int64) string { return (time.Duration(n) * time.Millisecond).Truncate(time.Second).String() }
+func ms(n int64) string    { return strconv.FormatInt(n, 10) }
+func money(f float64) string {
+	return strconv.FormatFloat(f, 'f', 4, 64)
+}
+
+func mapName(key string) string {
+	if key == "" {
+		return "(unknown map)"
+	}
+	return key
+}
+
+func itemName(it ItemTotal) string {
+	if it.Name == "" {
+		return "#" + strconv.Itoa(it.ID)
+	}
+	return it.Name
+}
+
+func mdEscape(s string) string { return strings.ReplaceAll(s, "|", "\\|") }
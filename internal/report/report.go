@@ -0,0 +1,162 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"GoTorch/internal/stats"
+	"GoTorch/internal/tracker"
+)
+
+// ItemLookup resolves an item ConfigBaseID to its display name and unit price.
+type ItemLookup func(id int) (name string, price float64)
+
+// Session is the map runs rebuilt from one log source.
+type Session struct {
+	Source string
+	Maps   []tracker.MapSession
+}
+
+// Run is a single completed map in the report.
+type Run struct {
+	Source     string      `json:"source"`
+	Session    int         `json:"session"` // 1-based index of the source session
+	MapKey     string      `json:"map_key"`
+	Region     string      `json:"region"`
+	StartedAt  time.Time   `json:"started_at"`
+	EndedAt    time.Time   `json:"ended_at"`
+	DurationMs int64       `json:"duration_ms"`
+	Drops      int         `json:"drops"`
+	Earnings   float64     `json:"earnings"`         // at the lookup (current) prices
+	AtDrop     float64     `json:"earnings_at_drop"` // at the prices locked in when each item dropped
+	Tally      map[int]int `json:"tally"`
+}
+
+// MapSummary aggregates runs of one map key.
+type MapSummary struct {
+	MapKey           string  `json:"map_key"`
+	Region           string  `json:"region"`
+	Runs             int     `json:"runs"`
+	MeanDurationMs   int64   `json:"mean_duration_ms"`
+	MedianDurationMs int64   `json:"median_duration_ms"`
+	P90DurationMs    int64   `json:"p90_duration_ms"`
+	TotalEarnings    float64 `json:"total_earnings"`
+	MeanEarnings     float64 `json:"mean_earnings"`
+	EarningsPerHour  float64 `json:"earnings_per_hour"`
+	MeanAtDrop       float64 `json:"mean_earnings_at_drop"`
+	PerHourAtDrop    float64 `json:"earnings_per_hour_at_drop"`
+}
+
+// ItemTotal is the total count and value of one item across all runs.
+type ItemTotal struct {
+	ID        int     `json:"id"`
+	Name      string  `json:"name"`
+	Count     int     `json:"count"`
+	UnitPrice float64 `json:"unit_price"`
+	Value     float64 `json:"value"`
+}
+
+// Totals sums every run in the report.
+type Totals struct {
+	Sessions        int     `json:"sessions"`
+	Runs            int     `json:"runs"`
+	Drops           int     `json:"drops"`
+	DurationMs      int64   `json:"duration_ms"` // time spent in maps
+	Earnings        float64 `json:"earnings"`
+	EarningsPerHour float64 `json:"earnings_per_hour"`
+	AtDrop          float64 `json:"earnings_at_drop"`
+	PerHourAtDrop   float64 `json:"earnings_per_hour_at_drop"`
+}
+
+// Report is the full offline replay result.
+type Report struct {
+	Runs     []Run        `json:"runs"`
+	Maps     []MapSummary `json:"maps"`
+	TopItems []ItemTotal  `json:"top_items"`
+	Totals   Totals       `json:"totals"`
+}
+
+// Build values every completed map in sessions and aggregates them, both at the lookup prices
+// and at the prices locked in when each item dropped. top limits TopItems to the most valuable
+// items (0 keeps all).
+func Build(sessions []Session, lookup ItemLookup, top int) Report {
+	price := func(id int) float64 {
+		if lookup == nil {
+			return 0
+		}
+		_, p := lookup(id)
+		return p
+	}
+	r := Report{Runs: []Run{}, Maps: []MapSummary{}, TopItems: []ItemTotal{}}
+	var all []tracker.MapSession
+	items := make(map[int]int)
+	for i, s := range sessions {
+		r.Totals.Sessions++
+		for _, m := range s.Maps {
+			if m.Active || m.EndedAt.IsZero() {
+				continue
+			}
+			run := Run{
+				Source:     s.Source,
+				Session:    i + 1,
+				MapKey:     m.MapKey,
+				Region:     m.Region,
+				StartedAt:  m.StartedAt,
+				EndedAt:    m.EndedAt,
+				DurationMs: m.Duration().Milliseconds(),
+				Earnings:   stats.Earnings(m.Tally, price),
+				AtDrop:     stats.ValueAtDrop(m, price),
+				Tally:      m.Tally,
+			}
+			for id, n := range m.Tally {
+				run.Drops += n
+				items[id] += n
+			}
+			r.Runs = append(r.Runs, run)
+			all = append(all, m)
+			r.Totals.Runs++
+			r.Totals.Drops += run.Drops
+			r.Totals.DurationMs += run.DurationMs
+			r.Totals.Earnings += run.Earnings
+			r.Totals.AtDrop += run.AtDrop
+		}
+	}
+	if r.Totals.DurationMs > 0 {
+		h := float64(r.Totals.DurationMs) / 3600000.0
+		r.Totals.EarningsPerHour = r.Totals.Earnings / h
+		r.Totals.PerHourAtDrop = r.Totals.AtDrop / h
+	}
+	for _, m := range stats.ByMap(all, price) {
+		r.Maps = append(r.Maps, MapSummary{
+			MapKey:           m.MapKey,
+			Region:           m.Region,
+			Runs:             m.Runs,
+			MeanDurationMs:   m.MeanDuration.Milliseconds(),
+			MedianDurationMs: m.MedianDuration.Milliseconds(),
+			P90DurationMs:    m.P90Duration.Milliseconds(),
+			TotalEarnings:    m.TotalEarnings,
+			MeanEarnings:     m.MeanEarnings,
+			EarningsPerHour:  m.EarningsPerHour,
+			MeanAtDrop:       m.MeanEarningsAtDrop,
+			PerHourAtDrop:    m.EarningsPerHourAtDrop,
+		})
+	}
+	for id, n := range items {
+		it := ItemTotal{ID: id, Count: n}
+		if lookup != nil {
+			it.Name, it.UnitPrice = lookup(id)
+		}
+		it.Value = float64(n) * it.UnitPrice
+		r.TopItems = append(r.TopItems, it)
+	}
+	sort.Slice(r.TopItems, func(i, j int) bool {
+		if r.TopItems[i].Value != r.TopItems[j].Value {
+			return r.TopItems[i].Value > r.TopItems[j].Value
+		}
+		return r.TopItems[i].ID < r.TopItems[j].ID
+	})
+	if top > 0 && len(r.TopItems) > top {
+		r.TopItems = r.TopItems[:top]
+	}
+	return r
+}
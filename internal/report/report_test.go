@@ -0,0 +1,103 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"GoTorch/internal/tracker"
+)
+
+func sampleReport() Report {
+	base := time.Date(2025, 11, 4, 19, 0, 0, 0, time.UTC)
+	sessions := []Session{
+		{Source: "a.log", Maps: []tracker.MapSession{
+			{MapKey: "YJ_A", Region: "07YJ", StartedAt: base, EndedAt: base.Add(5 * time.Minute), Tally: map[int]int{1: 2, 2: 10}},
+			{MapKey: "YJ_B", Region: "07YJ", StartedAt: base.Add(10 * time.Minute), EndedAt: base.Add(20 * time.Minute), Tally: map[int]int{2: 5}},
+		}},
+		{Source: "b.log", Maps: []tracker.MapSession{
+			{MapKey: "YJ_A", Region: "07YJ", StartedAt: base.Add(time.Hour), EndedAt: base.Add(time.Hour + 5*time.Minute), Tally: map[int]int{1: 1, 3: 1}},
+			{MapKey: "YJ_A", StartedAt: base.Add(2 * time.Hour), Active: true, Tally: map[int]int{1: 50}},
+		}},
+	}
+	lookup := func(id int) (string, float64) {
+		switch id {
+		case 1:
+			return "Flame Elementium", 10
+		case 2:
+			return "Ember | Dust", 0.5
+		}
+		return "", 0
+	}
+	return Build(sessions, lookup, 2)
+}
+
+func TestBuildTotalsMapsAndTopItems(t *testing.T) {
+	r := sampleReport()
+	if r.Totals.Sessions != 2 || r.Totals.Runs != 3 || r.Totals.Drops != 19 {
+		t.Fatalf("unexpected totals: %+v", r.Totals)
+	}
+	// 25 + 2.5 + 10 = 37.5 over 20 minutes
+	if r.Totals.Earnings != 37.5 || r.Totals.EarningsPerHour != 112.5 || r.Totals.DurationMs != (20*time.Minute).Milliseconds() {
+		t.Fatalf("unexpected earnings totals: %+v", r.Totals)
+	}
+	if len(r.Runs) != 3 || r.Runs[2].Source != "b.log" || r.Runs[2].Session != 2 || r.Runs[0].Earnings != 25 {
+		t.Fatalf("unexpected runs: %+v", r.Runs)
+	}
+	if len(r.Maps) != 2 || r.Maps[0].MapKey != "YJ_A" || r.Maps[0].Runs != 2 {
+		t.Fatalf("unexpected per-map summary: %+v", r.Maps)
+	}
+	if len(r.TopItems) != 2 || r.TopItems[0].ID != 1 || r.TopItems[0].Value != 30 || r.TopItems[1].ID != 2 || r.TopItems[1].Count != 15 {
+		t.Fatalf("unexpected top items: %+v", r.TopItems)
+	}
+}
+
+func TestWriteFormats(t *testing.T) {
+	r := sampleReport()
+
+	var buf bytes.Buffer
+	if err := Write(&buf, r, "json"); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	var back Report
+	if err := json.Unmarshal(buf.Bytes(), &back); err != nil || back.Totals.Runs != 3 || len(back.Runs) != 3 {
+		t.Fatalf("json round trip failed: %v %+v", err, back.Totals)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, r, "csv"); err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	tables := strings.Split(strings.TrimSpace(buf.String()), "\n\n")
+	if len(tables) != 4 {
+		t.Fatalf("expected 4 csv tables, got %d\n%s", len(tables), buf.String())
+	}
+	runs, err := csv.NewReader(strings.NewReader(tables[0])).ReadAll()
+	if err != nil || len(runs) != 4 || runs[1][2] != "YJ_A" || runs[1][8] != "25.0000" {
+		t.Fatalf("unexpected runs csv: %v %v", runs, err)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, r, "md"); err != nil {
+		t.Fatalf("md: %v", err)
+	}
+	md := buf.String()
+	if !strings.Contains(md, "## Per map") || !strings.Contains(md, "| YJ_A | 07YJ | 2 |") || !strings.Contains(md, `Ember \| Dust`) {
+		t.Fatalf("unexpected markdown\n%s", md)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, r, "text"); err != nil {
+		t.Fatalf("text: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Earnings/hour: 112.5") || !strings.Contains(buf.String(), "Flame Elementium") {
+		t.Fatalf("unexpected text\n%s", buf.String())
+	}
+
+	if err := Write(&buf, r, "xml"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
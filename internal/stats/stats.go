@@ -0,0 +1,179 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"GoTorch/internal/tracker"
+)
+
+// PriceFunc returns the unit price for an item ConfigBaseID (0 when unknown).
+type PriceFunc func(id int) float64
+
+// MapStats aggregates completed runs of a single map key.
+type MapStats struct {
+	MapKey          string
+	Region          string
+	Runs            int
+	MeanDuration    time.Duration
+	MedianDuration  time.Duration
+	P90Duration     time.Duration
+	TotalEarnings   float64
+	MeanEarnings    float64
+	EarningsPerHour float64
+	// The same figures valued with the prices locked in when each item dropped (see ValueAtDrop).
+	TotalEarningsAtDrop   float64
+	MeanEarningsAtDrop    float64
+	EarningsPerHourAtDrop float64
+	// Cost of the items consumed by the runs (entry costs included) and the resulting
+	// net earnings, both at current prices.
+	TotalCost          float64
+	MeanCost           float64
+	NetEarningsPerHour float64
+	// DropRate is the average count of each ConfigBaseID picked up per run.
+	DropRate map[int]float64
+}
+
+// ByMap groups completed map sessions by MapKey and computes per-map statistics.
+// Sessions that are still active are skipped. The result is sorted by
+// EarningsPerHour (highest first), then by MapKey.
+func ByMap(sessions []tracker.MapSession, price PriceFunc) []MapStats {
+	type group struct {
+		region   string
+		durs     []time.Duration
+		earnings float64
+		atDrop   float64
+		cost     float64
+		drops    map[int]int
+	}
+	groups := make(map[string]*group)
+	for _, m := range sessions {
+		if m.Active || m.EndedAt.IsZero() {
+			continue
+		}
+		g := groups[m.MapKey]
+		if g == nil {
+			g = &group{region: m.Region, drops: make(map[int]int)}
+			groups[m.MapKey] = g
+		}
+		g.durs = append(g.durs, m.Duration())
+		g.earnings += Earnings(m.Tally, price)
+		g.atDrop += ValueAtDrop(m, price)
+		g.cost += Cost(m, price)
+		for id, n := range m.Tally {
+			g.drops[id] += n
+		}
+	}
+
+	out := make([]MapStats, 0, len(groups))
+	for key, g := range groups {
+		runs := len(g.durs)
+		sort.Slice(g.durs, func(i, j int) bool { return g.durs[i] < g.durs[j] })
+		var total time.Duration
+		for _, d := range g.durs {
+			total += d
+		}
+		ms := MapStats{
+			MapKey:              key,
+			Region:              g.region,
+			Runs:                runs,
+			MeanDuration:        total / time.Duration(runs),
+			MedianDuration:      median(g.durs),
+			P90Duration:         percentile(g.durs, 0.9),
+			TotalEarnings:       g.earnings,
+			MeanEarnings:        g.earnings / float64(runs),
+			TotalEarningsAtDrop: g.atDrop,
+			MeanEarningsAtDrop:  g.atDrop / float64(runs),
+			TotalCost:           g.cost,
+			MeanCost:            g.cost / float64(runs),
+			DropRate:            make(map[int]float64, len(g.drops)),
+		}
+		if h := total.Hours(); h > 0 {
+			ms.EarningsPerHour = g.earnings / h
+			ms.EarningsPerHourAtDrop = g.atDrop / h
+			ms.NetEarningsPerHour = (g.earnings - g.cost) / h
+		}
+		for id, n := range g.drops {
+			ms.DropRate[id] = float64(n) / float64(runs)
+		}
+		out = append(out, ms)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].EarningsPerHour != out[j].EarningsPerHour {
+			return out[i].EarningsPerHour > out[j].EarningsPerHour
+		}
+		return out[i].MapKey < out[j].MapKey
+	})
+	return out
+}
+
+// ValueAtDrop values a session with the prices locked in when each item dropped (MapSession.Value),
+// falling back to price for items without a locked value, e.g. sessions tracked without pricing.
+func ValueAtDrop(m tracker.MapSession, price PriceFunc) float64 {
+	return locked(m.Tally, m.Value, price)
+}
+
+// Cost values the items consumed in a session (MapSession.Spent) at current prices.
+func Cost(m tracker.MapSession, price PriceFunc) float64 {
+	return Earnings(m.Spent, price)
+}
+
+// CostAtUse values the items consumed in a session with the prices locked in when each
+// was consumed (MapSession.SpentValue), falling back to price like ValueAtDrop.
+func CostAtUse(m tracker.MapSession, price PriceFunc) float64 {
+	return locked(m.Spent, m.SpentValue, price)
+}
+
+// locked sums the locked values of a tally, pricing ids without one with price.
+func locked(tally map[int]int, value map[int]float64, price PriceFunc) float64 {
+	var sum float64
+	for id, n := range tally {
+		if v, ok := value[id]; ok {
+			sum += v
+		} else if price != nil {
+			sum += float64(n) * price(id)
+		}
+	}
+	return sum
+}
+
+// Earnings values a tally with the given price function.
+func Earnings(tally map[int]int, price PriceFunc) float64 {
+	if price == nil {
+		return 0
+	}
+	var sum float64
+	for id, n := range tally {
+		sum += float64(n) * price(id)
+	}
+	return sum
+}
+
+// median expects sorted input.
+func median(sorted []time.Duration) time.Duration {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// percentile returns the nearest-rank percentile p (0..1) of sorted input.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(n))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= n {
+		rank = n - 1
+	}
+	return sorted[rank]
+}
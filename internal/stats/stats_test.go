@@ -0,0 +1,109 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"GoTorch/internal/tracker"
+)
+
+func run(key string, start time.Time, d time.Duration, tally map[int]int) tracker.MapSession {
+	return tracker.MapSession{MapKey: key, Region: "07YJ", StartedAt: start, EndedAt: start.Add(d), Tally: tally}
+}
+
+func TestByMapAggregates(t *testing.T) {
+	base := time.Date(2025, 11, 4, 19, 0, 0, 0, time.UTC)
+	sessions := []tracker.MapSession{
+		run("A", base, 1*time.Minute, map[int]int{1: 2}),
+		run("A", base, 2*time.Minute, map[int]int{1: 1, 2: 1}),
+		run("A", base, 3*time.Minute, nil),
+		run("A", base, 10*time.Minute, map[int]int{2: 3}),
+		run("B", base, 6*time.Minute, map[int]int{1: 1}),
+		{MapKey: "A", StartedAt: base, Active: true, Tally: map[int]int{1: 100}},
+	}
+	price := func(id int) float64 {
+		if id == 1 {
+			return 10
+		}
+		return 1
+	}
+	got := ByMap(sessions, price)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 map groups, got %d", len(got))
+	}
+	a := got[0]
+	if a.MapKey != "A" || a.Runs != 4 {
+		t.Fatalf("expected A first with 4 runs, got %+v", a)
+	}
+	if a.MeanDuration != 4*time.Minute {
+		t.Fatalf("mean duration = %v", a.MeanDuration)
+	}
+	if a.MedianDuration != 150*time.Second {
+		t.Fatalf("median duration = %v", a.MedianDuration)
+	}
+	if a.P90Duration != 10*time.Minute {
+		t.Fatalf("p90 duration = %v", a.P90Duration)
+	}
+	// earnings: 20 + (10+1) + 0 + 3 = 34 over 16 minutes
+	if a.TotalEarnings != 34 || a.MeanEarnings != 8.5 {
+		t.Fatalf("earnings total=%v mean=%v", a.TotalEarnings, a.MeanEarnings)
+	}
+	if want := 34 / (16.0 / 60.0); a.EarningsPerHour != want {
+		t.Fatalf("earnings/hour = %v want %v", a.EarningsPerHour, want)
+	}
+	if a.DropRate[1] != 0.75 || a.DropRate[2] != 1 {
+		t.Fatalf("drop rates = %v", a.DropRate)
+	}
+	if got[1].MapKey != "B" || got[1].EarningsPerHour != 100 {
+		t.Fatalf("unexpected B stats: %+v", got[1])
+	}
+}
+
+func TestByMapEmptyAndNilPrice(t *testing.T) {
+	if got := ByMap(nil, nil); len(got) != 0 {
+		t.Fatalf("expected no stats, got %+v", got)
+	}
+	base := time.Now()
+	got := ByMap([]tracker.MapSession{run("A", base, time.Minute, map[int]int{1: 1})}, nil)
+	if len(got) != 1 || got[0].TotalEarnings != 0 || got[0].DropRate[1] != 1 {
+		t.Fatalf("unexpected stats with nil price: %+v", got)
+	}
+}
+
+func TestValueAtDropPrefersLockedValue(t *testing.T) {
+	now := func(id int) float64 { return 10 }
+	start := time.Now()
+	m := tracker.MapSession{
+		StartedAt: start, EndedAt: start.Add(time.Hour),
+		Tally: map[int]int{1: 2, 2: 1},
+		Value: map[int]float64{1: 3}, // item 2 was never priced at drop time
+	}
+	if got := ValueAtDrop(m, now); got != 13 {
+		t.Fatalf("ValueAtDrop = %v want 13", got)
+	}
+	ms := ByMap([]tracker.MapSession{m}, now)
+	if ms[0].TotalEarnings != 30 || ms[0].TotalEarningsAtDrop != 13 || ms[0].EarningsPerHourAtDrop != 13 {
+		t.Fatalf("unexpected stats: %+v", ms[0])
+	}
+}
+
+func TestCostAndNetEarnings(t *testing.T) {
+	now := func(id int) float64 { return 10 }
+	start := time.Now()
+	m := tracker.MapSession{
+		StartedAt: start, EndedAt: start.Add(30 * time.Minute),
+		Tally:      map[int]int{1: 5},
+		Spent:      map[int]int{2: 2},
+		SpentValue: map[int]float64{2: 8},
+	}
+	if got := Cost(m, now); got != 20 {
+		t.Fatalf("Cost = %v want 20", got)
+	}
+	if got := CostAtUse(m, now); got != 8 {
+		t.Fatalf("CostAtUse = %v want 8", got)
+	}
+	ms := ByMap([]tracker.MapSession{m}, now)
+	if ms[0].TotalCost != 20 || ms[0].MeanCost != 20 || ms[0].NetEarningsPerHour != 60 {
+		t.Fatalf("unexpected stats: %+v", ms[0])
+	}
+}
@@ -0,0 +1,523 @@
+package tailer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Line is a line read by a Multi, tagged with the file it came from.
+type Line struct {
+	Source string // path of the file
+	Text   string
+	// Disjoint marks, in MergeSources, the first line after every earlier source has ended:
+	// its source does not overlap them in time.
+	Disjoint bool
+}
+
+// TimestampFunc reads the time of a log line; it reports false for lines without one.
+type TimestampFunc func(line string) (time.Time, bool)
+
+// MultiOptions control a Multi.
+type MultiOptions struct {
+	// Paths are files or glob patterns. A file that does not exist yet is waited for, and
+	// files that match a pattern later are followed from their start.
+	Paths []string
+	// Options apply to every file; Path and Resume are ignored. With FromStart, the lines
+	// already in the files are merged in timestamp order before the files are followed.
+	Options
+	// Timestamp orders the merged lines. A line without a timestamp keeps the time of the
+	// line before it; without Timestamp the files are read one after another.
+	Timestamp TimestampFunc
+	// Rescan is how often patterns are expanded again (default 5s).
+	Rescan time.Duration
+}
+
+// Multi follows several log files, such as the standalone and Steam clients' logs or
+// archived backups, with one Tailer per file. New lines are sent in the order they are read.
+type Multi struct {
+	opt MultiOptions
+
+	mu      sync.Mutex
+	tailers []*Tailer
+	seen    []os.FileInfo // files already read, so a log renamed to a backup is not read again
+	merged  Counters      // lines sent by the initial merge
+	can     context.CancelFunc
+}
+
+func NewMulti(opt MultiOptions) *Multi {
+	if opt.Rescan <= 0 {
+		opt.Rescan = 5 * time.Second
+	}
+	return &Multi{opt: opt}
+}
+
+// IsPattern reports whether path is a glob pattern rather than a file name.
+func IsPattern(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// Expand replaces glob patterns in paths by the files they match, in name order, and drops
+// duplicates. Plain paths are kept even if the file does not exist.
+func Expand(paths []string) []string {
+	var out []string
+	have := map[string]bool{}
+	add := func(p string) {
+		p = filepath.Clean(p)
+		if !have[p] {
+			have[p] = true
+			out = append(out, p)
+		}
+	}
+	for _, p := range paths {
+		if !IsPattern(p) {
+			add(p)
+			continue
+		}
+		// a malformed pattern matches nothing
+		matches, _ := filepath.Glob(p)
+		for _, m := range matches {
+			add(m)
+		}
+	}
+	return out
+}
+
+// Start follows the files until ctx is done or a file's Tailer fails.
+func (m *Multi) Start(ctx context.Context, out chan<- Line) error {
+	if len(m.opt.Paths) == 0 {
+		return errors.New("tailer: no paths")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	m.mu.Lock()
+	m.can = cancel
+	m.mu.Unlock()
+	defer cancel()
+
+	paths := Expand(m.opt.Paths)
+	for _, p := range paths {
+		if st, err := os.Stat(p); err == nil {
+			m.remember(st)
+		}
+	}
+	resume := map[string]*Checkpoint{}
+	if m.opt.FromStart {
+		cps, err := mergeFiles(paths, m.opt.Timestamp, func(l Line) error {
+			select {
+			case out <- l:
+				m.mu.Lock()
+				m.merged.LinesSent++
+				m.mu.Unlock()
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, &m.mu, &m.merged)
+		if err != nil {
+			return err
+		}
+		resume = cps
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	failed := make(chan error, 1)
+	follow := func(path string, fromStart bool, cp *Checkpoint) {
+		opt := m.opt.Options
+		opt.Path, opt.FromStart, opt.Resume = path, fromStart, cp
+		t := New(opt)
+		m.mu.Lock()
+		m.tailers = append(m.tailers, t)
+		m.mu.Unlock()
+		lines := make(chan string, 64)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := t.Start(ctx, lines); err != nil && ctx.Err() == nil {
+				select {
+				case failed <- err:
+				default:
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case s := <-lines:
+					select {
+					case out <- Line{Source: path, Text: s}:
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	for _, p := range paths {
+		follow(p, m.opt.FromStart, resume[p])
+	}
+
+	rescan := time.NewTicker(m.opt.Rescan)
+	defer rescan.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-failed:
+			cancel()
+			return err
+		case <-rescan.C:
+			for _, p := range m.newFiles() {
+				follow(p, true, nil)
+			}
+		}
+	}
+}
+
+// newFiles returns files that started matching a pattern and were not read before under
+// another name.
+func (m *Multi) newFiles() []string {
+	m.mu.Lock()
+	have := map[string]bool{}
+	for _, t := range m.tailers {
+		have[t.opt.Path] = true
+		t.mu.Lock()
+		st := t.st
+		t.mu.Unlock()
+		if st != nil {
+			m.rememberLocked(st)
+		}
+	}
+	m.mu.Unlock()
+
+	var out []string
+	for _, p := range Expand(m.opt.Paths) {
+		if have[p] {
+			continue
+		}
+		st, err := os.Stat(p)
+		if err != nil || m.known(st) {
+			continue
+		}
+		m.remember(st)
+		out = append(out, p)
+	}
+	return out
+}
+
+func (m *Multi) remember(st os.FileInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rememberLocked(st)
+}
+
+func (m *Multi) rememberLocked(st os.FileInfo) {
+	for _, s := range m.seen {
+		if os.SameFile(s, st) {
+			return
+		}
+	}
+	m.seen = append(m.seen, st)
+}
+
+func (m *Multi) known(st os.FileInfo) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.seen {
+		if os.SameFile(s, st) {
+			return true
+		}
+	}
+	return false
+}
+
+// Stats returns a snapshot of every followed file, in the order they were added.
+func (m *Multi) Stats() []Stats {
+	m.mu.Lock()
+	tailers := append([]*Tailer(nil), m.tailers...)
+	m.mu.Unlock()
+	out := make([]Stats, len(tailers))
+	for i, t := range tailers {
+		out[i] = t.Stats()
+	}
+	return out
+}
+
+// Counters returns the totals of the initial merge and all files.
+func (m *Multi) Counters() Counters {
+	m.mu.Lock()
+	c := m.merged
+	tailers := append([]*Tailer(nil), m.tailers...)
+	m.mu.Unlock()
+	for _, t := range tailers {
+		tc := t.Counters()
+		c.LinesRead += tc.LinesRead
+		c.BytesRead += tc.BytesRead
+		c.LinesSent += tc.LinesSent
+		c.LinesDropped += tc.LinesDropped
+		c.LinesSpilled += tc.LinesSpilled
+		c.Queued += tc.Queued
+	}
+	return c
+}
+
+// Watching reports whether any file is followed through change notifications.
+func (m *Multi) Watching() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tailers {
+		if t.Watching() {
+			return true
+		}
+	}
+	return false
+}
+
+// Options returns the options every file is tailed with.
+func (m *Multi) Options() Options {
+	return m.opt.Options
+}
+
+// Stop cancels the tailing context if started.
+func (m *Multi) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.can != nil {
+		m.can()
+	}
+}
+
+// Source is a named log for MergeSources. Open is called once to read the time of the first
+// line and once more to read the log; every call must return the log from its start.
+type Source struct {
+	Name string
+	Open func() (io.ReadCloser, error)
+}
+
+// MergeSources calls fn for every line of the sources in timestamp order, including a last
+// line without a terminator; ties go to the source listed first. A source is only opened when
+// the merge reaches its first line and is closed at its end, so only sources whose lines
+// overlap in time are open together; a line following a source that starts after the ones
+// before it have ended is marked Disjoint. It stops at the first error from fn.
+func MergeSources(srcs []Source, ts TimestampFunc, fn func(Line) error) error {
+	type waiting struct {
+		idx int
+		at  time.Time // of its first line
+	}
+	queue := make([]waiting, 0, len(srcs))
+	for i, src := range srcs {
+		at, err := firstTime(src, ts)
+		if err != nil {
+			return err
+		}
+		queue = append(queue, waiting{idx: i, at: at})
+	}
+	sort.SliceStable(queue, func(i, j int) bool { return queue[i].at.Before(queue[j].at) })
+
+	var open []*mergeFile
+	var sent, disjoint bool
+	defer func() {
+		for _, s := range open {
+			s.c.Close()
+		}
+	}()
+	for {
+		var pick *mergeFile
+		for _, s := range open {
+			if pick == nil || s.at.Before(pick.at) || (s.at.Equal(pick.at) && s.idx < pick.idx) {
+				pick = s
+			}
+		}
+		// a source starting no later than the earliest open line may come first
+		if len(queue) > 0 && (pick == nil || !queue[0].at.After(pick.at)) {
+			w := queue[0]
+			queue = queue[1:]
+			if len(open) == 0 && sent {
+				disjoint = true
+			}
+			rc, err := srcs[w.idx].Open()
+			if err != nil {
+				return err
+			}
+			s := &mergeFile{path: srcs[w.idx].Name, r: bufio.NewReaderSize(rc, 64*1024), partial: true, idx: w.idx, c: rc}
+			open = append(open, s)
+			if err := s.advance(ts); err != nil {
+				return err
+			}
+			open = closeDone(open)
+			continue
+		}
+		if pick == nil {
+			return nil
+		}
+		if err := fn(Line{Source: pick.path, Text: pick.next, Disjoint: disjoint}); err != nil {
+			return err
+		}
+		sent, disjoint = true, false
+		if err := pick.advance(ts); err != nil {
+			return err
+		}
+		open = closeDone(open)
+	}
+}
+
+// firstTime opens src and returns the time of its first line; zero if it has none.
+func firstTime(src Source, ts TimestampFunc) (time.Time, error) {
+	rc, err := src.Open()
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer rc.Close()
+	s := &mergeFile{r: bufio.NewReader(rc), partial: true}
+	if err := s.advance(ts); err != nil {
+		return time.Time{}, err
+	}
+	return s.at, nil
+}
+
+// closeDone closes and drops the sources that have no line left.
+func closeDone(open []*mergeFile) []*mergeFile {
+	keep := open[:0]
+	for _, s := range open {
+		if s.ok {
+			keep = append(keep, s)
+		} else {
+			s.c.Close()
+		}
+	}
+	return keep
+}
+
+// mergeFile is one file being merged; next is its earliest line not yet sent.
+type mergeFile struct {
+	path    string
+	f       *os.File
+	r       *bufio.Reader
+	id      *fileID
+	at      time.Time
+	partial bool      // send a last line without a terminator rather than leave it to a tailer
+	idx     int       // position in the MergeSources list
+	c       io.Closer // closes a MergeSources source
+
+	next    string
+	raw     []byte // next with its terminator
+	ok      bool
+	off     int64 // just after the last sent line
+	lastLen int
+	last    uint64
+}
+
+// advance reads the next complete line; a trailing partial line is left to the tailer
+// unless s.partial is set.
+func (s *mergeFile) advance(ts TimestampFunc) error {
+	b, err := s.r.ReadBytes('\n')
+	if err != nil {
+		s.ok = false
+		if !errors.Is(err, io.EOF) {
+			return err
+		}
+		if len(b) == 0 || !s.partial {
+			return nil
+		}
+	}
+	s.raw = b
+	text := strings.TrimSuffix(strings.TrimSuffix(string(b), "\n"), "\r")
+	s.next, s.ok = text, true
+	if ts != nil {
+		if at, ok := ts(text); ok {
+			s.at = at
+		}
+	}
+	return nil
+}
+
+// mergeFiles merges the lines the files hold now and returns, per file, a checkpoint after
+// its last complete line for the Tailer that follows it. Counts are added to c under mu.
+func mergeFiles(paths []string, ts TimestampFunc, fn func(Line) error, mu *sync.Mutex, c *Counters) (map[string]*Checkpoint, error) {
+	var files []*mergeFile
+	defer func() {
+		for _, s := range files {
+			s.f.Close()
+		}
+	}()
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			// a missing file is waited for by its tailer
+			continue
+		}
+		st, err := f.Stat()
+		if err != nil {
+			f.Close()
+			continue
+		}
+		id, err := readFileID(f, st.Size())
+		if err != nil {
+			f.Close()
+			continue
+		}
+		files = append(files, &mergeFile{path: p, f: f, id: id, r: bufio.NewReaderSize(io.NewSectionReader(f, 0, st.Size()), 64*1024)})
+	}
+	if err := merge(files, ts, fn, mu, c); err != nil {
+		return nil, err
+	}
+
+	cps := make(map[string]*Checkpoint, len(files))
+	for _, s := range files {
+		cps[s.path] = &Checkpoint{
+			Path:     s.path,
+			HeadLen:  s.id.headLen,
+			HeadHash: strconv.FormatUint(s.id.headHash, 16),
+			Offset:   s.off,
+			LineLen:  s.lastLen,
+			LineHash: strconv.FormatUint(s.last, 16),
+		}
+	}
+	return cps, nil
+}
+
+// merge sends the lines of files, earliest first; ties go to the file listed first.
+func merge(files []*mergeFile, ts TimestampFunc, fn func(Line) error, mu *sync.Mutex, c *Counters) error {
+	for _, s := range files {
+		if err := s.advance(ts); err != nil {
+			return err
+		}
+	}
+	for {
+		var pick *mergeFile
+		for _, s := range files {
+			if s.ok && (pick == nil || s.at.Before(pick.at)) {
+				pick = s
+			}
+		}
+		if pick == nil {
+			break
+		}
+		if err := fn(Line{Source: pick.path, Text: pick.next}); err != nil {
+			return err
+		}
+		pick.off += int64(len(pick.raw))
+		pick.lastLen, pick.last = len(pick.raw), hashBytes(pick.raw)
+		if mu != nil {
+			mu.Lock()
+			c.LinesRead++
+			c.BytesRead += uint64(len(pick.raw))
+			mu.Unlock()
+		}
+		if err := pick.advance(ts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
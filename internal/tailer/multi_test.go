@@ -0,0 +1,236 @@
+package tailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"GoTorch/internal/parser"
+)
+
+func recvTagged(t *testing.T, out <-chan Line) Line {
+	t.Helper()
+	select {
+	case l := <-out:
+		return l
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for line")
+		return Line{}
+	}
+}
+
+func TestMultiMergesHistoryByTimestampThenFollows(t *testing.T) {
+	dir := t.TempDir()
+	steam := filepath.Join(dir, "steam", "UE_game.log")
+	standalone := filepath.Join(dir, "standalone", "UE_game.log")
+	for _, p := range []string{steam, standalone} {
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	writeAppend(t, steam, "[2025.11.04-19.20.45:000][1]s1\n  continued\n[2025.11.04-19.20.47:000][1]s2\n")
+	// the trailing partial line is left to the tailer
+	writeAppend(t, standalone, "[2025.11.04-19.20.44:000][1]a1\n[2025.11.04-19.20.46:000][1]a2\n[2025.11.04-19.20.48")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := make(chan Line, 16)
+	m := NewMulti(MultiOptions{
+		Paths:     []string{steam, standalone},
+		Options:   Options{FromStart: true, Mode: ModePoll, PollEvery: 20 * time.Millisecond},
+		Timestamp: parser.New().Timestamp,
+	})
+	go func() { _ = m.Start(ctx, out) }()
+
+	want := []Line{
+		{Source: standalone, Text: "[2025.11.04-19.20.44:000][1]a1"},
+		{Source: steam, Text: "[2025.11.04-19.20.45:000][1]s1"},
+		{Source: steam, Text: "  continued"},
+		{Source: standalone, Text: "[2025.11.04-19.20.46:000][1]a2"},
+		{Source: steam, Text: "[2025.11.04-19.20.47:000][1]s2"},
+	}
+	for i, w := range want {
+		if got := recvTagged(t, out); got != w {
+			t.Fatalf("line %d: got %+v want %+v", i, got, w)
+		}
+	}
+
+	writeAppend(t, standalone, ":000][1]a3\n")
+	if got := recvTagged(t, out); got != (Line{Source: standalone, Text: "[2025.11.04-19.20.48:000][1]a3"}) {
+		t.Fatalf("completed partial line: got %+v", got)
+	}
+	writeAppend(t, steam, "s3\n")
+	if got := recvTagged(t, out); got != (Line{Source: steam, Text: "s3"}) {
+		t.Fatalf("live line: got %+v", got)
+	}
+	if c := m.Counters(); c.LinesSent != 7 {
+		t.Fatalf("expected 7 lines sent, got %+v", c)
+	}
+	if len(m.Stats()) != 2 {
+		t.Fatalf("expected stats for 2 files, got %+v", m.Stats())
+	}
+}
+
+func TestMultiPicksUpNewMatchesButNotRenamedLogs(t *testing.T) {
+	dir := t.TempDir()
+	game := filepath.Join(dir, "UE_game.log")
+	writeAppend(t, game, "old\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := make(chan Line, 16)
+	m := NewMulti(MultiOptions{
+		Paths:   []string{filepath.Join(dir, "*.log")},
+		Options: Options{FromStart: true, Mode: ModePoll, PollEvery: 20 * time.Millisecond},
+		Rescan:  30 * time.Millisecond,
+	})
+	go func() { _ = m.Start(ctx, out) }()
+	if got := recvTagged(t, out); got != (Line{Source: game, Text: "old"}) {
+		t.Fatalf("got %+v", got)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// Unreal renames the log to a backup and starts a new one: the backup was already read
+	if err := os.Rename(game, filepath.Join(dir, "UE_game-backup-2025.11.04-19.20.45.log")); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	writeAppend(t, game, "new\n")
+	if got := recvTagged(t, out); got != (Line{Source: game, Text: "new"}) {
+		t.Fatalf("got %+v", got)
+	}
+
+	other := filepath.Join(dir, "other.log")
+	writeAppend(t, other, "first\n")
+	if got := recvTagged(t, out); got != (Line{Source: other, Text: "first"}) {
+		t.Fatalf("got %+v", got)
+	}
+	select {
+	case l := <-out:
+		t.Fatalf("unexpected line %+v", l)
+	case <-time.After(150 * time.Millisecond):
+	}
+}
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	for _, n := range []string{"b.log", "a.log", "c.txt"} {
+		writeAppend(t, filepath.Join(dir, n), "x\n")
+	}
+	a, b := filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")
+	missing := filepath.Join(dir, "later.log")
+	got := Expand([]string{filepath.Join(dir, "*.log"), a, missing})
+	want := []string{a, b, missing}
+	if len(got) != len(want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v want %v", got, want)
+		}
+	}
+}
+
+// stringSource is a Source reading text, counting how many of its kind are open in *open.
+func stringSource(name, text string, open, peak *int) Source {
+	return Source{Name: name, Open: func() (io.ReadCloser, error) {
+		if *open++; *open > *peak {
+			*peak = *open
+		}
+		return countedReader{strings.NewReader(text), open}, nil
+	}}
+}
+
+type countedReader struct {
+	io.Reader
+	open *int
+}
+
+func (r countedReader) Close() error {
+	*r.open--
+	return nil
+}
+
+func TestMergeSourcesKeepsUnterminatedLastLine(t *testing.T) {
+	var got []Line
+	var open, peak int
+	err := MergeSources([]Source{
+		stringSource("b", "[2025.11.04-19.20.46:000]b1\n[2025.11.04-19.20.48:000]b2", &open, &peak),
+		stringSource("a", "[2025.11.04-19.20.45:000]a1\r\n[2025.11.04-19.20.47:000]a2\n", &open, &peak),
+	}, parser.New().Timestamp, func(l Line) error {
+		got = append(got, l)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("MergeSources: %v", err)
+	}
+	if open != 0 {
+		t.Fatalf("%d sources left open", open)
+	}
+	want := []Line{
+		{Source: "a", Text: "[2025.11.04-19.20.45:000]a1"},
+		{Source: "b", Text: "[2025.11.04-19.20.46:000]b1"},
+		{Source: "a", Text: "[2025.11.04-19.20.47:000]a2"},
+		{Source: "b", Text: "[2025.11.04-19.20.48:000]b2"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %+v want %+v", got, want)
+		}
+	}
+}
+
+func TestMergeSourcesOpensOnlyOverlappingSources(t *testing.T) {
+	var open, peak int
+	var srcs []Source
+	for i := range 20 {
+		// consecutive backups, each covering its own minute
+		text := fmt.Sprintf("[2025.11.04-19.%02d.00:000]x%d\n[2025.11.04-19.%02d.30:000]y%d\n", i, i, i, i)
+		srcs = append(srcs, stringSource(fmt.Sprint(i), text, &open, &peak))
+	}
+	// a live log overlapping the last backup, listed last
+	srcs = append(srcs, stringSource("live", "[2025.11.04-19.19.10:000]live\n", &open, &peak))
+	var got []string
+	err := MergeSources(srcs, parser.New().Timestamp, func(l Line) error {
+		got = append(got, l.Source)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("MergeSources: %v", err)
+	}
+	if peak != 2 || open != 0 {
+		t.Fatalf("peak open sources = %d, left open %d; want 2 and 0", peak, open)
+	}
+	if len(got) != 41 || got[0] != "0" || got[38] != "19" || got[39] != "live" || got[40] != "19" {
+		t.Fatalf("unexpected merge order %v", got)
+	}
+}
+
+func TestMergeSourcesMarksDisjointSources(t *testing.T) {
+	var open, peak int
+	srcs := []Source{
+		stringSource("a", "[2025.11.04-19.20.00:000]a1\n[2025.11.04-19.21.00:000]a2\n", &open, &peak),
+		stringSource("b", "[2025.11.04-19.20.30:000]b1\n", &open, &peak), // overlaps a
+		stringSource("c", "[2025.11.04-20.00.00:000]c1\n[2025.11.04-20.01.00:000]c2\n", &open, &peak),
+	}
+	var got []string
+	err := MergeSources(srcs, parser.New().Timestamp, func(l Line) error {
+		if l.Disjoint {
+			got = append(got, l.Text)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("MergeSources: %v", err)
+	}
+	if len(got) != 1 || got[0] != "[2025.11.04-20.00.00:000]c1" {
+		t.Fatalf("expected only the first line of c marked disjoint, got %v", got)
+	}
+}
@@ -0,0 +1,314 @@
+package tailer
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Overflow decides what the tailer does with new lines while the consumer is not keeping up.
+type Overflow int
+
+const (
+	// OverflowBlock stops reading until the consumer takes the next line (or ctx is done).
+	OverflowBlock Overflow = iota
+	// OverflowDropOldest keeps up to QueueSize lines and discards the oldest when full.
+	OverflowDropOldest
+	// OverflowSpill keeps up to QueueSize lines in memory and the rest in a temporary file.
+	OverflowSpill
+)
+
+// DefaultQueueSize is the number of lines buffered by the tailer when Options.QueueSize is unset.
+const DefaultQueueSize = 4096
+
+func (o Overflow) String() string {
+	switch o {
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowSpill:
+		return "spill"
+	}
+	return "block"
+}
+
+// ParseOverflow reads an overflow policy name: block, drop-oldest or spill.
+func ParseOverflow(s string) (Overflow, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "block":
+		return OverflowBlock, nil
+	case "drop-oldest", "drop":
+		return OverflowDropOldest, nil
+	case "spill":
+		return OverflowSpill, nil
+	}
+	return OverflowBlock, fmt.Errorf("tailer: unknown overflow policy %q (want block, drop-oldest or spill)", s)
+}
+
+// Counters are running totals of a tailer's work.
+type Counters struct {
+	LinesRead    uint64 `json:"lines_read"`    // complete lines read from the file
+	BytesRead    uint64 `json:"bytes_read"`    // bytes read from the file
+	LinesSent    uint64 `json:"lines_sent"`    // lines handed to the consumer
+	LinesDropped uint64 `json:"lines_dropped"` // lines discarded by OverflowDropOldest
+	LinesSpilled uint64 `json:"lines_spilled"` // lines written to the spill file by OverflowSpill
+	Queued       int    `json:"queued"`        // lines read but not yet sent, in memory or on disk
+}
+
+// Counters returns a snapshot of the tailer's counters.
+func (t *Tailer) Counters() Counters {
+	t.mu.Lock()
+	c := t.counters
+	t.mu.Unlock()
+	if q := t.queue; q != nil {
+		c.Queued = q.len()
+	}
+	return c
+}
+
+// line is a read line with the file position after it, recorded as a mark once it is sent.
+type line struct {
+	text string
+	mark mark
+}
+
+// queue buffers lines between the read loop and the consumer for the non-blocking policies.
+type queue struct {
+	policy Overflow
+	max    int
+	dir    string
+	wake   chan struct{}
+
+	mu    sync.Mutex
+	lines []line
+	spill *spillFile // lines after the in-memory ones, oldest first
+}
+
+func newQueue(policy Overflow, max int, dir string) *queue {
+	return &queue{policy: policy, max: max, dir: dir, wake: make(chan struct{}, 1)}
+}
+
+// push adds l, applying the overflow policy. It reports whether a line was dropped and
+// whether l was spilled to disk.
+func (q *queue) push(l line) (dropped, spilled bool, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	defer q.signal()
+	// once spilling, later lines must follow the spilled ones
+	if q.policy == OverflowSpill && (len(q.lines) >= q.max || q.spill.len() > 0) {
+		if q.spill == nil {
+			if q.spill, err = newSpillFile(q.dir); err != nil {
+				return false, false, err
+			}
+		}
+		return false, true, q.spill.write(l)
+	}
+	if len(q.lines) >= q.max {
+		q.lines = q.lines[1:]
+		dropped = true
+	}
+	q.lines = append(q.lines, l)
+	return dropped, false, nil
+}
+
+// pop returns the oldest line, refilling memory from the spill file once it is empty.
+func (q *queue) pop() (line, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.lines) == 0 && q.spill.len() > 0 {
+		for len(q.lines) < q.max && q.spill.len() > 0 {
+			l, err := q.spill.read()
+			if err != nil {
+				return line{}, false, err
+			}
+			q.lines = append(q.lines, l)
+		}
+		if q.spill.len() == 0 {
+			q.spill.reset()
+		}
+	}
+	if len(q.lines) == 0 {
+		return line{}, false, nil
+	}
+	l := q.lines[0]
+	q.lines = q.lines[1:]
+	return l, true, nil
+}
+
+func (q *queue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.lines) + q.spill.len()
+}
+
+func (q *queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *queue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.spill.close()
+	q.spill = nil
+	q.lines = nil
+}
+
+// emit hands a read line to the consumer: directly for OverflowBlock, else through the queue.
+func (t *Tailer) emit(ctx context.Context, out chan<- string, l line) error {
+	if t.queue == nil {
+		select {
+		case out <- l.text:
+			t.sentLine(l)
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	dropped, spilled, err := t.queue.push(l)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	if dropped {
+		t.counters.LinesDropped++
+	}
+	if spilled {
+		t.counters.LinesSpilled++
+	}
+	t.mu.Unlock()
+	return nil
+}
+
+// deliver sends queued lines to out until ctx is done.
+func (t *Tailer) deliver(ctx context.Context, out chan<- string) error {
+	for {
+		l, ok, err := t.queue.pop()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-t.queue.wake:
+			}
+			continue
+		}
+		select {
+		case out <- l.text:
+			t.sentLine(l)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// sentLine counts a line taken by the consumer and records its checkpoint mark.
+// Dropped lines get no mark, so consumed line counts keep matching checkpoints.
+func (t *Tailer) sentLine(l line) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counters.LinesSent++
+	t.sent++
+	m := l.mark
+	m.seq = t.sent
+	if len(t.marks) < markWindow {
+		t.marks = append(t.marks, m)
+		return
+	}
+	t.marks[int(t.sent%markWindow)] = m
+}
+
+// spillFile is an append-only temporary file of length-prefixed JSON records read back in order.
+type spillFile struct {
+	f        *os.File
+	readOff  int64
+	writeOff int64
+	n        int
+}
+
+// spillRecord is a line on disk. The file identity is copied, so a spilled line's mark does
+// not follow later growth of a short file's identity (its checkpoint then no longer matches).
+type spillRecord struct {
+	Text     string `json:"t"`
+	Off      int64  `json:"o"`
+	LineLen  int    `json:"l"`
+	LineHash uint64 `json:"h"`
+	HeadLen  int    `json:"hl"`
+	HeadHash uint64 `json:"hh"`
+}
+
+func newSpillFile(dir string) (*spillFile, error) {
+	f, err := os.CreateTemp(dir, "gotorch-spill-*")
+	if err != nil {
+		return nil, err
+	}
+	return &spillFile{f: f}, nil
+}
+
+func (s *spillFile) len() int {
+	if s == nil {
+		return 0
+	}
+	return s.n
+}
+
+func (s *spillFile) write(l line) error {
+	r := spillRecord{Text: l.text, Off: l.mark.off, LineLen: l.mark.lineLen, LineHash: l.mark.lineHash}
+	if l.mark.id != nil {
+		r.HeadLen, r.HeadHash = l.mark.id.headLen, l.mark.id.headHash
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	rec := binary.LittleEndian.AppendUint32(nil, uint32(len(b)))
+	rec = append(rec, b...)
+	if _, err := s.f.WriteAt(rec, s.writeOff); err != nil {
+		return err
+	}
+	s.writeOff += int64(len(rec))
+	s.n++
+	return nil
+}
+
+func (s *spillFile) read() (line, error) {
+	var hdr [4]byte
+	if _, err := s.f.ReadAt(hdr[:], s.readOff); err != nil {
+		return line{}, err
+	}
+	b := make([]byte, binary.LittleEndian.Uint32(hdr[:]))
+	if _, err := s.f.ReadAt(b, s.readOff+4); err != nil && err != io.EOF {
+		return line{}, err
+	}
+	var r spillRecord
+	if err := json.Unmarshal(b, &r); err != nil {
+		return line{}, err
+	}
+	s.readOff += 4 + int64(len(b))
+	s.n--
+	return line{text: r.Text, mark: mark{id: &fileID{headLen: r.HeadLen, headHash: r.HeadHash},
+		off: r.Off, lineLen: r.LineLen, lineHash: r.LineHash}}, nil
+}
+
+// reset empties a fully read file so it does not grow across bursts.
+func (s *spillFile) reset() {
+	if s.f.Truncate(0) == nil {
+		s.readOff, s.writeOff = 0, 0
+	}
+}
+
+func (s *spillFile) close() {
+	if s == nil {
+		return
+	}
+	s.f.Close()
+	os.Remove(s.f.Name())
+}
@@ -0,0 +1,103 @@
+package tailer
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"time"
+)
+
+// Health is what a tailer is currently doing with its file.
+type Health int
+
+const (
+	// HealthWaiting means the file is not open: it does not exist (yet) or disappeared.
+	HealthWaiting Health = iota
+	// HealthFollowing means the file is open and read as it grows.
+	HealthFollowing
+	// HealthRotated means the file was replaced or truncated and nothing was read from the new one yet.
+	HealthRotated
+	// HealthErrored means the file cannot be read, or Start stopped on an error.
+	HealthErrored
+)
+
+func (h Health) String() string {
+	switch h {
+	case HealthFollowing:
+		return "following"
+	case HealthRotated:
+		return "rotated"
+	case HealthErrored:
+		return "errored"
+	}
+	return "waiting"
+}
+
+// MarshalText encodes the health by name.
+func (h Health) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// Stats is a snapshot of a tailer's position and health. A file that keeps its size while
+// LastData gets older means the game is not logging; a growing one is being followed.
+type Stats struct {
+	Path      string    `json:"path"`
+	Health    Health    `json:"health"`
+	Err       string    `json:"error,omitempty"` // the error behind HealthErrored
+	Waiting   bool      `json:"waiting"`         // no file is open
+	Watching  bool      `json:"watching"`
+	Offset    int64     `json:"offset"`    // bytes of the current file read so far
+	Size      int64     `json:"size"`      // file size at the last check
+	LastData  time.Time `json:"last_data"` // when data was last read; zero before any
+	Rotations int       `json:"rotations"` // replacements and truncations seen
+	Counters
+}
+
+// Stats returns a snapshot of the tailer's position, health and counters.
+func (t *Tailer) Stats() Stats {
+	t.mu.Lock()
+	s := Stats{
+		Path:      t.opt.Path,
+		Health:    t.health,
+		Waiting:   t.f == nil,
+		Watching:  t.watching,
+		Offset:    t.pos,
+		Size:      t.size,
+		LastData:  t.lastData,
+		Rotations: t.rotations,
+		Counters:  t.counters,
+	}
+	if t.health == HealthErrored && t.lastErr != nil {
+		s.Err = t.lastErr.Error()
+	}
+	t.mu.Unlock()
+	if q := t.queue; q != nil {
+		s.Queued = q.len()
+	}
+	return s
+}
+
+// setHealth records the tailer's health; err is kept for HealthErrored.
+func (t *Tailer) setHealth(h Health, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.health = h
+	t.lastErr = err
+}
+
+// fileGone records that the file could not be opened or checked: a missing file is waited
+// for, anything else (e.g. permissions) is an error.
+func (t *Tailer) fileGone(err error) {
+	if errors.Is(err, fs.ErrNotExist) {
+		t.setHealth(HealthWaiting, nil)
+		return
+	}
+	t.setHealth(HealthErrored, err)
+}
+
+// stopped records the error Start returns, unless it only reports that ctx is done.
+func (t *Tailer) stopped(ctx context.Context, err error) {
+	if err != nil && ctx.Err() == nil {
+		t.setHealth(HealthErrored, err)
+	}
+}
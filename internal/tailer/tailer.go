@@ -4,8 +4,10 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"hash/fnv"
 	"io"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -14,11 +16,55 @@ import (
 type Options struct {
 	Path      string        // Log file path
 	FromStart bool          // If true, start reading from start, else from end
-	PollEvery time.Duration // How often to poll for new data
+	PollEvery time.Duration // How often to poll for new data (ModePoll, or ModeAuto without a watcher)
 	ReadChunk int           // Read buffer size per iteration
+	// Mode chooses between watching the file for changes and polling it (default ModeAuto).
+	Mode Mode
+	// Overflow is what happens to new lines while the consumer falls behind (default OverflowBlock).
+	// QueueSize bounds the lines held in memory by the other policies (default DefaultQueueSize)
+	// and SpillDir is where OverflowSpill keeps the rest (default os.TempDir()).
+	Overflow  Overflow
+	QueueSize int
+	SpillDir  string
+	// Resume, if set, continues from a saved checkpoint when it still matches the file.
+	// Otherwise (rotated, truncated or replaced file) FromStart decides where to begin.
+	Resume *Checkpoint
 }
 
-// Tailer tails a single file with simple polling. Cross-platform (Windows/macOS/Linux).
+// Checkpoint identifies a line boundary in a file so tailing can resume after a restart.
+type Checkpoint struct {
+	Path     string `json:"path"`
+	HeadLen  int    `json:"head_len"`  // number of leading bytes hashed for file identity
+	HeadHash string `json:"head_hash"` // hash of the first HeadLen bytes
+	Offset   int64  `json:"offset"`    // byte offset just after the last consumed line
+	LineLen  int    `json:"line_len"`  // length of the last consumed line including its terminator
+	LineHash string `json:"line_hash"` // hash of the last consumed line including its terminator
+}
+
+// headBytes is how much of the file start is hashed to identify it across restarts.
+// Unreal logs begin with a "Log file open" timestamp, so this is unique per file.
+const headBytes = 512
+
+// markWindow is how many emitted lines keep a checkpoint; it must exceed any consumer buffer.
+const markWindow = 8192
+
+// fileID is the identity of the currently open file.
+type fileID struct {
+	headLen  int
+	headHash uint64
+}
+
+// mark records the file position after the seq-th emitted line.
+type mark struct {
+	seq      uint64
+	id       *fileID
+	off      int64
+	lineLen  int
+	lineHash uint64
+}
+
+// Tailer tails a single file. It is woken by file change notifications where available
+// (inotify on Linux) and polls otherwise. Cross-platform (Windows/macOS/Linux).
 type Tailer struct {
 	opt Options
 	mu  sync.Mutex
@@ -27,6 +73,20 @@ type Tailer struct {
 	st  os.FileInfo
 	ctx context.Context
 	can context.CancelFunc
+
+	id    *fileID
+	sent  uint64
+	marks []mark // ring of the last markWindow line boundaries
+	// set by Start: whether change notifications drive the read loop
+	watching bool
+	counters Counters
+	queue    *queue // nil for OverflowBlock
+
+	health    Health
+	lastErr   error
+	size      int64
+	lastData  time.Time
+	rotations int
 }
 
 func New(opt Options) *Tailer {
@@ -36,11 +96,25 @@ func New(opt Options) *Tailer {
 	if opt.ReadChunk <= 0 {
 		opt.ReadChunk = 64 * 1024
 	}
-	return &Tailer{opt: opt}
+	if opt.QueueSize <= 0 {
+		opt.QueueSize = DefaultQueueSize
+	}
+	t := &Tailer{opt: opt}
+	if opt.Overflow != OverflowBlock {
+		t.queue = newQueue(opt.Overflow, opt.QueueSize, opt.SpillDir)
+	}
+	return t
 }
 
-// Start begins tailing. It sends complete lines on out. It returns when ctx is done or on fatal error.
+// Start begins tailing. It sends complete lines on out. It returns when ctx is done or on fatal error;
+// no send blocks past ctx, and lines still queued then are discarded.
 func (t *Tailer) Start(ctx context.Context, out chan<- string) error {
+	err := t.start(ctx, out)
+	t.stopped(ctx, err)
+	return err
+}
+
+func (t *Tailer) start(ctx context.Context, out chan<- string) error {
 	if t.opt.Path == "" {
 		return errors.New("tailer: empty path")
 	}
@@ -49,9 +123,34 @@ func (t *Tailer) Start(ctx context.Context, out chan<- string) error {
 	t.can = cancel
 	defer cancel()
 
+	if t.queue != nil {
+		delivered := make(chan error, 1)
+		go func() { delivered <- t.deliver(ctx, out) }()
+		defer func() {
+			cancel()
+			<-delivered
+			t.queue.close()
+		}()
+	}
+
+	w, watching, err := newWaker(t.opt.Mode, t.opt.Path)
+	if err != nil {
+		return err
+	}
+	defer w.close()
+	t.mu.Lock()
+	t.watching = watching
+	t.mu.Unlock()
+	// a watcher wakes the loop on changes; the interval is only a safety net then
+	interval := t.opt.PollEvery
+	if watching {
+		interval = watchFallback
+	}
+
 	retryDelay := 500 * time.Millisecond
 	var pending []byte
 
+	resume := t.opt.Resume
 	openFile := func() error {
 		f, err := os.Open(t.opt.Path)
 		if err != nil {
@@ -62,12 +161,25 @@ func (t *Tailer) Start(ctx context.Context, out chan<- string) error {
 			f.Close()
 			return err
 		}
+		id, err := readFileID(f, st.Size())
+		if err != nil {
+			f.Close()
+			return err
+		}
 		var startPos int64
-		if t.opt.FromStart {
+		var startLen int
+		var startHash uint64
+		if resume != nil && checkpointMatches(f, st.Size(), *resume) {
+			startPos = resume.Offset
+			startLen = resume.LineLen
+			startHash, _ = strconv.ParseUint(resume.LineHash, 16, 64)
+		} else if t.opt.FromStart {
 			startPos = 0
 		} else {
 			startPos = st.Size()
 		}
+		// a checkpoint only applies to the first open; rotations follow FromStart
+		resume = nil
 		if _, err := f.Seek(startPos, io.SeekStart); err != nil {
 			f.Close()
 			return err
@@ -76,6 +188,11 @@ func (t *Tailer) Start(ctx context.Context, out chan<- string) error {
 		t.f = f
 		t.st = st
 		t.pos = startPos
+		t.size = st.Size()
+		t.id = id
+		if t.sent == 0 {
+			t.marks = append(t.marks[:0], mark{id: id, off: startPos, lineLen: startLen, lineHash: startHash})
+		}
 		t.mu.Unlock()
 		return nil
 	}
@@ -83,44 +200,65 @@ func (t *Tailer) Start(ctx context.Context, out chan<- string) error {
 	// Wait for file to exist if needed
 	for {
 		if err := openFile(); err != nil {
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(retryDelay):
-				continue
+			t.fileGone(err)
+			if err := w.wait(ctx, retryDelay); err != nil {
+				return err
 			}
+			continue
 		}
 		break
 	}
+	t.setHealth(HealthFollowing, nil)
 
 	buf := make([]byte, t.opt.ReadChunk)
 	reader := bufio.NewReaderSize(nil, t.opt.ReadChunk)
 
-	flushLines := func(b []byte) {
+	// flushLines emits complete lines from b, which begins at file offset base.
+	flushLines := func(b []byte, base int64) error {
 		// Split on \n; handle Windows \r\n
 		start := 0
 		for i := 0; i < len(b); i++ {
 			if b[i] == '\n' {
-				line := b[start:i]
+				raw := b[start : i+1]
+				text := b[start:i]
 				// trim trailing \r
-				if len(line) > 0 && line[len(line)-1] == '\r' {
-					line = line[:len(line)-1]
+				if len(text) > 0 && text[len(text)-1] == '\r' {
+					text = text[:len(text)-1]
+				}
+				t.mu.Lock()
+				t.counters.LinesRead++
+				m := mark{id: t.id, off: base + int64(i+1), lineLen: len(raw), lineHash: hashBytes(raw)}
+				t.mu.Unlock()
+				if err := t.emit(ctx, out, line{text: string(text), mark: m}); err != nil {
+					return err
 				}
-				out <- string(line)
 				start = i + 1
 			}
 		}
 		pending = append(pending[:0], b[start:]...)
+		return nil
 	}
 
-	// readLoop
+	// readLoop; right after opening a file or a full read there may be data that no change
+	// notification will announce, so read again without waiting
+	more := true
+	// a file was replaced, truncated or removed and nothing was read from its successor yet
+	rotatedPending := false
+	rotatedAway := func() {
+		rotatedPending = true
+		t.mu.Lock()
+		t.rotations++
+		t.mu.Unlock()
+	}
 	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(t.opt.PollEvery):
-			// fallthrough
+		if more {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		} else if err := w.wait(ctx, interval); err != nil {
+			return err
 		}
+		more = false
 
 		t.mu.Lock()
 		f := t.f
@@ -132,9 +270,16 @@ func (t *Tailer) Start(ctx context.Context, out chan<- string) error {
 		curSt, err := os.Stat(t.opt.Path)
 		if err != nil {
 			// File temporarily missing; try reopen later
+			if f != nil {
+				rotatedAway()
+			}
 			t.closeFile()
+			t.fileGone(err)
 			continue
 		}
+		t.mu.Lock()
+		t.size = curSt.Size()
+		t.mu.Unlock()
 		rotated := false
 		if !sameFile(st, curSt) {
 			rotated = true
@@ -143,20 +288,31 @@ func (t *Tailer) Start(ctx context.Context, out chan<- string) error {
 		}
 		if rotated {
 			t.closeFile()
+			rotatedAway()
 			if err := openFile(); err != nil {
 				// wait and retry next tick
+				t.fileGone(err)
 				continue
 			}
+			t.setHealth(HealthRotated, nil)
 			pending = pending[:0]
 			reader.Reset(t.f)
+			more = true
 			continue
 		}
 
 		if f == nil {
 			if err := openFile(); err != nil {
+				t.fileGone(err)
 				continue
 			}
+			if rotatedPending {
+				t.setHealth(HealthRotated, nil)
+			} else {
+				t.setHealth(HealthFollowing, nil)
+			}
 			reader.Reset(t.f)
+			more = true
 			continue
 		}
 
@@ -169,18 +325,40 @@ func (t *Tailer) Start(ctx context.Context, out chan<- string) error {
 		if err != nil && !errors.Is(err, io.EOF) {
 			// transient read error, try reopening
 			t.closeFile()
+			t.setHealth(HealthErrored, err)
 			continue
 		}
 		if n > 0 {
+			more = n == len(buf)
+			rotatedPending = false
+			base := pos - int64(len(pending))
 			data := append(pending, buf[:n]...)
-			flushLines(data)
 			t.mu.Lock()
 			t.pos += int64(n)
+			t.counters.BytesRead += uint64(n)
+			t.lastData = time.Now()
+			t.health, t.lastErr = HealthFollowing, nil
 			t.mu.Unlock()
+			if err := flushLines(data, base); err != nil {
+				return err
+			}
+			t.growFileID(f)
 		}
 	}
 }
 
+// Options returns the tailer's options with defaults applied.
+func (t *Tailer) Options() Options {
+	return t.opt
+}
+
+// Watching reports whether Start is driven by file change notifications rather than polling.
+func (t *Tailer) Watching() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.watching
+}
+
 func (t *Tailer) closeFile() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -192,6 +370,99 @@ func (t *Tailer) closeFile() {
 	t.pos = 0
 }
 
+// growFileID extends the identity hash of a file that was shorter than headBytes when opened.
+// Marks share the fileID, and the head of an append-only file does not change, so older
+// marks stay valid.
+func (t *Tailer) growFileID(f *os.File) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.id == nil || t.id.headLen >= headBytes || t.pos <= int64(t.id.headLen) {
+		return
+	}
+	if id, err := readFileID(f, t.pos); err == nil {
+		*t.id = *id
+	}
+}
+
+// Checkpoint returns the resume point after the first consumed lines emitted by Start.
+// Consumers count the lines they have fully processed and pass that count, so a saved
+// checkpoint never skips or repeats a line. It reports false if the position is unknown
+// (not started yet, or consumed lags more than markWindow lines behind).
+func (t *Tailer) Checkpoint(consumed uint64) (Checkpoint, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, m := range t.marks {
+		if m.seq == consumed && m.id != nil {
+			return Checkpoint{
+				Path:     t.opt.Path,
+				HeadLen:  m.id.headLen,
+				HeadHash: strconv.FormatUint(m.id.headHash, 16),
+				Offset:   m.off,
+				LineLen:  m.lineLen,
+				LineHash: strconv.FormatUint(m.lineHash, 16),
+			}, true
+		}
+	}
+	return Checkpoint{}, false
+}
+
+// ValidCheckpoint reports whether cp still points at a line boundary of the same file.
+func ValidCheckpoint(cp Checkpoint) bool {
+	f, err := os.Open(cp.Path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return checkpointMatches(f, st.Size(), cp)
+}
+
+// checkpointMatches compares the file head and the line before cp.Offset with the checkpoint.
+func checkpointMatches(f *os.File, size int64, cp Checkpoint) bool {
+	if cp.Offset < 0 || cp.Offset > size || int64(cp.HeadLen) > size {
+		return false
+	}
+	id, err := readFileID(f, int64(cp.HeadLen))
+	if err != nil || strconv.FormatUint(id.headHash, 16) != cp.HeadHash {
+		return false
+	}
+	if cp.LineLen > 0 {
+		if int64(cp.LineLen) > cp.Offset {
+			return false
+		}
+		b := make([]byte, cp.LineLen)
+		if _, err := f.ReadAt(b, cp.Offset-int64(cp.LineLen)); err != nil {
+			return false
+		}
+		if strconv.FormatUint(hashBytes(b), 16) != cp.LineHash {
+			return false
+		}
+	}
+	return true
+}
+
+// readFileID hashes up to headBytes (and at most limit) bytes from the start of f.
+func readFileID(f *os.File, limit int64) (*fileID, error) {
+	n := int64(headBytes)
+	if limit < n {
+		n = limit
+	}
+	b := make([]byte, n)
+	if _, err := f.ReadAt(b, 0); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return &fileID{headLen: int(n), headHash: hashBytes(b)}, nil
+}
+
+func hashBytes(b []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	return h.Sum64()
+}
+
 // Stop cancels the tailing context if started.
 func (t *Tailer) Stop() {
 	if t.can != nil {
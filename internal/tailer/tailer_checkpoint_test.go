@@ -0,0 +1,116 @@
+package tailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func recvLine(t *testing.T, out <-chan string) string {
+	t.Helper()
+	select {
+	case s := <-out:
+		return s
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for line")
+		return ""
+	}
+}
+
+func TestTailerResumeFromCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "log.txt")
+	if err := os.WriteFile(p, []byte("header\r\nl1\r\nl2\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan string, 8)
+	tlr := New(Options{Path: p, FromStart: true, PollEvery: 20 * time.Millisecond})
+	go func() { _ = tlr.Start(ctx, out) }()
+	if s := recvLine(t, out); s != "header" {
+		t.Fatalf("got %q want header", s)
+	}
+	if s := recvLine(t, out); s != "l1" {
+		t.Fatalf("got %q want l1", s)
+	}
+	// Two lines consumed; l2 may already be buffered in out but must not be skipped on resume
+	cp, ok := tlr.Checkpoint(2)
+	cancel()
+	if !ok {
+		t.Fatal("expected checkpoint after 2 lines")
+	}
+	if cp.Offset != int64(len("header\r\nl1\r\n")) || cp.LineLen != len("l1\r\n") {
+		t.Fatalf("unexpected checkpoint: %+v", cp)
+	}
+	if !ValidCheckpoint(cp) {
+		t.Fatalf("expected checkpoint to be valid: %+v", cp)
+	}
+
+	// App was down while more lines were logged
+	writeAppend(t, p, "l3\n")
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	out2 := make(chan string, 8)
+	tlr2 := New(Options{Path: p, FromStart: false, PollEvery: 20 * time.Millisecond, Resume: &cp})
+	go func() { _ = tlr2.Start(ctx2, out2) }()
+	if s := recvLine(t, out2); s != "l2" {
+		t.Fatalf("got %q want l2 after resume", s)
+	}
+	if s := recvLine(t, out2); s != "l3" {
+		t.Fatalf("got %q want l3 after resume", s)
+	}
+	// The resumed tailer reports positions relative to the same file
+	if cp2, ok := tlr2.Checkpoint(2); !ok || cp2.Offset != int64(len("header\r\nl1\r\nl2\nl3\n")) || !ValidCheckpoint(cp2) {
+		t.Fatalf("unexpected resumed checkpoint: %+v ok=%v", cp2, ok)
+	}
+}
+
+func TestTailerResumeFallsBackWhenRotated(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "log.txt")
+	if err := os.WriteFile(p, []byte("old header\nold1\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan string, 8)
+	tlr := New(Options{Path: p, FromStart: true, PollEvery: 20 * time.Millisecond})
+	go func() { _ = tlr.Start(ctx, out) }()
+	recvLine(t, out)
+	recvLine(t, out)
+	cp, ok := tlr.Checkpoint(2)
+	cancel()
+	if !ok {
+		t.Fatal("expected checkpoint")
+	}
+
+	// Rotate: a new file with different content replaces the old one
+	if err := os.Rename(p, p+".old"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if err := os.WriteFile(p, []byte("new header\nnew1\nnew2\n"), 0o644); err != nil {
+		t.Fatalf("write new: %v", err)
+	}
+	if ValidCheckpoint(cp) {
+		t.Fatal("expected checkpoint to be invalid after rotation")
+	}
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	out2 := make(chan string, 8)
+	tlr2 := New(Options{Path: p, FromStart: true, PollEvery: 20 * time.Millisecond, Resume: &cp})
+	go func() { _ = tlr2.Start(ctx2, out2) }()
+	if s := recvLine(t, out2); s != "new header" {
+		t.Fatalf("got %q want new header after fallback", s)
+	}
+}
+
+func TestCheckpointUnknownPosition(t *testing.T) {
+	tlr := New(Options{Path: "unused"})
+	if _, ok := tlr.Checkpoint(0); ok {
+		t.Fatal("expected no checkpoint before Start")
+	}
+	if ValidCheckpoint(Checkpoint{Path: filepath.Join(t.TempDir(), "missing.log")}) {
+		t.Fatal("expected missing file checkpoint to be invalid")
+	}
+}
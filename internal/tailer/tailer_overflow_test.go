@@ -0,0 +1,145 @@
+package tailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// numbered returns lines "l1".."ln" joined with newlines.
+func numbered(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "l%d\n", i)
+	}
+	return b.String()
+}
+
+// waitRead waits until the tailer has read n lines.
+func waitRead(t *testing.T, tlr *Tailer, n uint64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for tlr.Counters().LinesRead < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("read %d lines, want %d", tlr.Counters().LinesRead, n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// drain receives lines until none arrives for a short while.
+func drain(out <-chan string) []string {
+	var got []string
+	for {
+		select {
+		case s := <-out:
+			got = append(got, s)
+		case <-time.After(200 * time.Millisecond):
+			return got
+		}
+	}
+}
+
+func TestBlockingSendHonorsContext(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "log.txt")
+	if err := os.WriteFile(p, []byte(numbered(3)), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan string) // never read: the consumer is gone
+	tlr := New(Options{Path: p, FromStart: true, PollEvery: 20 * time.Millisecond})
+	done := make(chan error, 1)
+	go func() { done <- tlr.Start(ctx, out) }()
+	waitRead(t, tlr, 1)
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("Start = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Start stayed blocked on a send after cancel")
+	}
+	if c := tlr.Counters(); c.LinesSent != 0 || c.BytesRead != uint64(len(numbered(3))) {
+		t.Fatalf("unexpected counters %+v", c)
+	}
+}
+
+func TestDropOldestKeepsNewestLines(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "log.txt")
+	if err := os.WriteFile(p, []byte(numbered(10)), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := make(chan string)
+	tlr := New(Options{Path: p, FromStart: true, PollEvery: 20 * time.Millisecond, Overflow: OverflowDropOldest, QueueSize: 3})
+	go func() { _ = tlr.Start(ctx, out) }()
+	waitRead(t, tlr, 10)
+
+	got := drain(out)
+	c := tlr.Counters()
+	// the line already taken by the sender is kept besides the newest QueueSize lines
+	if c.LinesDropped < 6 || int(c.LinesDropped)+len(got) != 10 || strings.Join(got[len(got)-3:], ",") != "l8,l9,l10" {
+		t.Fatalf("got %v with counters %+v", got, c)
+	}
+	if c.LinesSent != uint64(len(got)) || c.Queued != 0 {
+		t.Fatalf("unexpected counters %+v", c)
+	}
+	// checkpoints count delivered lines only, so consuming all of them resumes at the end
+	cp, ok := tlr.Checkpoint(uint64(len(got)))
+	if !ok || cp.Offset != int64(len(numbered(10))) {
+		t.Fatalf("Checkpoint = %+v, %v", cp, ok)
+	}
+}
+
+func TestSpillKeepsEveryLineInOrder(t *testing.T) {
+	dir := t.TempDir()
+	spillDir := t.TempDir()
+	p := filepath.Join(dir, "log.txt")
+	if err := os.WriteFile(p, []byte(numbered(50)), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan string)
+	tlr := New(Options{Path: p, FromStart: true, PollEvery: 20 * time.Millisecond, ReadChunk: 64,
+		Overflow: OverflowSpill, QueueSize: 2, SpillDir: spillDir})
+	done := make(chan struct{})
+	go func() { _ = tlr.Start(ctx, out); close(done) }()
+	waitRead(t, tlr, 50)
+	if c := tlr.Counters(); c.LinesSpilled == 0 || c.Queued < 45 {
+		t.Fatalf("expected lines spilled to disk, got %+v", c)
+	}
+
+	got := drain(out)
+	if strings.Join(got, "\n")+"\n" != numbered(50) {
+		t.Fatalf("lines lost or reordered: %v", got)
+	}
+	writeAppend(t, p, "l51\n")
+	if got := drain(out); len(got) != 1 || got[0] != "l51" {
+		t.Fatalf("expected l51 after the spill drained, got %v", got)
+	}
+	if c := tlr.Counters(); c.LinesSent != 51 || c.Queued != 0 || c.LinesDropped != 0 {
+		t.Fatalf("unexpected counters %+v", c)
+	}
+	cancel()
+	<-done
+	if left, _ := os.ReadDir(spillDir); len(left) != 0 {
+		t.Fatalf("spill file left behind: %v", left)
+	}
+}
+
+func TestParseOverflow(t *testing.T) {
+	for in, want := range map[string]Overflow{"": OverflowBlock, "block": OverflowBlock, "drop-oldest": OverflowDropOldest, "Spill": OverflowSpill} {
+		if got, err := ParseOverflow(in); err != nil || got != want {
+			t.Fatalf("ParseOverflow(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseOverflow("grow"); err == nil {
+		t.Fatal("expected an unknown policy to be rejected")
+	}
+}
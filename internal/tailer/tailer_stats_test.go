@@ -0,0 +1,91 @@
+package tailer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitStats polls the tailer until ok accepts its stats.
+func waitStats(t *testing.T, tlr *Tailer, what string, ok func(Stats) bool) Stats {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		s := tlr.Stats()
+		if ok(s) {
+			return s
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s: got %+v", what, s)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStatsTrackHealthThroughRotation(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "game.log")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := make(chan string, 8)
+	tlr := New(Options{Path: p, FromStart: true, Mode: ModePoll, PollEvery: 20 * time.Millisecond})
+	go func() { _ = tlr.Start(ctx, out) }()
+
+	waitStats(t, tlr, "waiting for the file", func(s Stats) bool { return s.Health == HealthWaiting && s.Waiting })
+
+	writeAppend(t, p, "a1\n")
+	if got := recvLine(t, out); got != "a1" {
+		t.Fatalf("got %q want a1", got)
+	}
+	s := waitStats(t, tlr, "following", func(s Stats) bool { return s.Health == HealthFollowing })
+	if s.Waiting || s.Offset != 3 || s.Size != 3 || s.LastData.IsZero() || s.Rotations != 0 || s.LinesRead != 1 {
+		t.Fatalf("unexpected stats after first line: %+v", s)
+	}
+
+	// a new, still empty file is reported until the game writes to it
+	if err := os.Rename(p, p+".old"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if err := os.WriteFile(p, nil, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	s = waitStats(t, tlr, "rotated", func(s Stats) bool { return s.Health == HealthRotated })
+	if s.Rotations != 1 || s.Offset != 0 || s.Size != 0 {
+		t.Fatalf("unexpected stats after rotation: %+v", s)
+	}
+
+	writeAppend(t, p, "b1\n")
+	if got := recvLine(t, out); got != "b1" {
+		t.Fatalf("got %q want b1", got)
+	}
+	waitStats(t, tlr, "following the new file", func(s Stats) bool {
+		return s.Health == HealthFollowing && s.Rotations == 1 && s.Offset == 3
+	})
+}
+
+func TestStatsReportErrors(t *testing.T) {
+	tlr := New(Options{})
+	if err := tlr.Start(context.Background(), make(chan string)); err == nil {
+		t.Fatal("expected an empty path to fail")
+	}
+	s := tlr.Stats()
+	if s.Health != HealthErrored || s.Err == "" {
+		t.Fatalf("expected an errored health, got %+v", s)
+	}
+	b, err := json.Marshal(s)
+	if err != nil || !strings.Contains(string(b), `"health":"errored"`) {
+		t.Fatalf("marshal: %s, %v", b, err)
+	}
+
+	// cancellation is not an error
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tlr = New(Options{Path: filepath.Join(t.TempDir(), "missing.log")})
+	_ = tlr.Start(ctx, make(chan string))
+	if h := tlr.Stats().Health; h != HealthWaiting {
+		t.Fatalf("health after cancel = %v, want waiting", h)
+	}
+}
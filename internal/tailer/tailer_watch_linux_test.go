@@ -0,0 +1,122 @@
+//go:build linux
+
+package tailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// startTailer runs a tailer that can only be woken by inotify in time: polling and the
+// watch fallback are both slower than the test's wait.
+func startTailer(t *testing.T, opt Options) (*Tailer, <-chan string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan string, 16)
+	tlr := New(opt)
+	go func() { _ = tlr.Start(ctx, out) }()
+	t.Cleanup(cancel)
+	return tlr, out
+}
+
+func expectLine(t *testing.T, out <-chan string, want string, within time.Duration) {
+	t.Helper()
+	select {
+	case s := <-out:
+		if s != want {
+			t.Fatalf("got %q want %q", s, want)
+		}
+	case <-time.After(within):
+		t.Fatalf("no %q within %v", want, within)
+	}
+}
+
+func TestWatchModeWakesOnAppendFasterThanPolling(t *testing.T) {
+	dir := t.TempDir()
+	watched := filepath.Join(dir, "watched.log")
+	polled := filepath.Join(dir, "polled.log")
+	for _, p := range []string{watched, polled} {
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	wt, wout := startTailer(t, Options{Path: watched, Mode: ModeWatch, PollEvery: time.Hour})
+	pt, pout := startTailer(t, Options{Path: polled, Mode: ModePoll, PollEvery: time.Second})
+	time.Sleep(100 * time.Millisecond)
+	if !wt.Watching() || pt.Watching() {
+		t.Fatalf("Watching() = %v, %v; want true, false", wt.Watching(), pt.Watching())
+	}
+
+	begin := time.Now()
+	writeAppend(t, watched, "w1\n")
+	writeAppend(t, polled, "p1\n")
+	expectLine(t, wout, "w1", 500*time.Millisecond)
+	watchLatency := time.Since(begin)
+	expectLine(t, pout, "p1", 2*time.Second)
+	pollLatency := time.Since(begin)
+	if watchLatency >= pollLatency {
+		t.Fatalf("expected watching to be faster: watch %v, poll %v", watchLatency, pollLatency)
+	}
+}
+
+func TestWatchModeFollowsRotationTruncationAndSplitLines(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "log.txt")
+	if err := os.WriteFile(p, []byte("a1\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_, out := startTailer(t, Options{Path: p, FromStart: true, Mode: ModeWatch, PollEvery: time.Hour, ReadChunk: 4})
+	within := time.Second // below watchFallback, so only inotify can deliver in time
+	expectLine(t, out, "a1", within)
+
+	// a line split across writes and reads is emitted once complete
+	writeAppend(t, p, "hel")
+	select {
+	case s := <-out:
+		t.Fatalf("unexpected line before newline: %q", s)
+	case <-time.After(100 * time.Millisecond):
+	}
+	writeAppend(t, p, "lo world\n")
+	expectLine(t, out, "hello world", within)
+
+	// rotation: rename and recreate
+	if err := os.Rename(p, p+".old"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if err := os.WriteFile(p, []byte("b1 after rotation\n"), 0o644); err != nil {
+		t.Fatalf("write new: %v", err)
+	}
+	expectLine(t, out, "b1 after rotation", within)
+
+	// truncation in place
+	if err := os.Truncate(p, 0); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	writeAppend(t, p, "c1\n")
+	expectLine(t, out, "c1", within)
+}
+
+func TestWatchModeWaitsForFile(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "later.log")
+	_, out := startTailer(t, Options{Path: p, FromStart: true, Mode: ModeWatch, PollEvery: time.Hour})
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(p, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	// the open retry runs every 500ms without a watcher
+	expectLine(t, out, "hello", 400*time.Millisecond)
+}
+
+func TestParseMode(t *testing.T) {
+	for in, want := range map[string]Mode{"": ModeAuto, "auto": ModeAuto, "poll": ModePoll, "Watch": ModeWatch, "inotify": ModeWatch} {
+		if got, err := ParseMode(in); err != nil || got != want {
+			t.Fatalf("ParseMode(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseMode("fsevents"); err == nil {
+		t.Fatal("expected an unknown mode to be rejected")
+	}
+}
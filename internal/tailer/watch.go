@@ -0,0 +1,106 @@
+package tailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Mode selects how the tailer notices new data.
+type Mode int
+
+const (
+	// ModeAuto watches the file where the platform supports it (inotify on Linux) and polls otherwise.
+	ModeAuto Mode = iota
+	// ModePoll stats and reads the file every PollEvery.
+	ModePoll
+	// ModeWatch requires a file watcher; Start fails with ErrWatchUnsupported without one.
+	ModeWatch
+)
+
+// ErrWatchUnsupported is returned by Start in ModeWatch when no file watcher is available.
+var ErrWatchUnsupported = errors.New("tailer: file watching not supported")
+
+// watchFallback is how often a watching tailer checks the file anyway, in case an event was missed.
+const watchFallback = 2 * time.Second
+
+func (m Mode) String() string {
+	switch m {
+	case ModePoll:
+		return "poll"
+	case ModeWatch:
+		return "watch"
+	}
+	return "auto"
+}
+
+// ParseMode reads a mode name: auto, poll or watch (inotify is an alias of watch).
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "auto":
+		return ModeAuto, nil
+	case "poll":
+		return ModePoll, nil
+	case "watch", "inotify":
+		return ModeWatch, nil
+	}
+	return ModeAuto, fmt.Errorf("tailer: unknown mode %q (want auto, poll or watch)", s)
+}
+
+// waker blocks the read loop until the file may have changed.
+type waker interface {
+	// wait returns after a change notification or after d, whichever comes first,
+	// or with ctx's error once ctx is done.
+	wait(ctx context.Context, d time.Duration) error
+	close()
+}
+
+// pollWaker only waits out d.
+type pollWaker struct{}
+
+func (pollWaker) wait(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+func (pollWaker) close() {}
+
+// chanWaker wakes on notifications sent to c by a platform watcher.
+type chanWaker struct {
+	c    <-chan struct{}
+	stop func()
+}
+
+func (w chanWaker) wait(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-w.c:
+		return nil
+	case <-time.After(d):
+		return nil
+	}
+}
+
+func (w chanWaker) close() { w.stop() }
+
+// newWaker returns the waker for mode and whether it watches the file.
+func newWaker(mode Mode, path string) (waker, bool, error) {
+	if mode == ModePoll {
+		return pollWaker{}, false, nil
+	}
+	w, err := watchFile(path)
+	if err == nil {
+		return w, true, nil
+	}
+	if mode == ModeWatch {
+		return nil, false, fmt.Errorf("%w: %v", ErrWatchUnsupported, err)
+	}
+	return pollWaker{}, false, nil
+}
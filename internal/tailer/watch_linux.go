@@ -0,0 +1,68 @@
+//go:build linux
+
+package tailer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// watchFile watches the directory of path with inotify, so creation, rotation, truncation and
+// appends of path all wake the tailer, even before the file exists.
+func watchFile(path string) (waker, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	const mask = syscall.IN_MODIFY | syscall.IN_ATTRIB | syscall.IN_CLOSE_WRITE | syscall.IN_CREATE |
+		syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+	if _, err := syscall.InotifyAddWatch(fd, dir, mask); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+	// a non-blocking fd goes through the runtime poller, so Close unblocks the reader
+	f := os.NewFile(uintptr(fd), "inotify")
+	c := make(chan struct{}, 1)
+	go func() {
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+			if touches(buf[:n], base) {
+				select {
+				case c <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return chanWaker{c: c, stop: func() { f.Close() }}, nil
+}
+
+// touches reports whether any inotify event in b concerns the file name base, the watched
+// directory itself or lost events.
+func touches(b []byte, base string) bool {
+	for len(b) >= syscall.SizeofInotifyEvent {
+		mask := binary.NativeEndian.Uint32(b[4:8])
+		nameLen := int(binary.NativeEndian.Uint32(b[12:16]))
+		end := syscall.SizeofInotifyEvent + nameLen
+		if end > len(b) {
+			return true
+		}
+		name := string(bytes.TrimRight(b[syscall.SizeofInotifyEvent:end], "\x00"))
+		if name == base || nameLen == 0 || mask&syscall.IN_Q_OVERFLOW != 0 {
+			return true
+		}
+		b = b[end:]
+	}
+	return false
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package tailer
+
+import "errors"
+
+// watchFile has no implementation on this platform; ModeAuto falls back to polling.
+func watchFile(path string) (waker, error) {
+	return nil, errors.New("no file watcher on this platform")
+}
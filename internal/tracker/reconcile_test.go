@@ -0,0 +1,207 @@
+package tracker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"GoTorch/internal/parser"
+)
+
+const (
+	mapStartLine = "[2025.11.04-19.20.00:000][  1]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = World'/Game/Art/Maps/01SD/XZ_YuJinZhiXiBiNanSuo200/XZ_YuJinZhiXiBiNanSuo200.XZ_YuJinZhiXiBiNanSuo200' NextSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200'"
+	mapEndLine   = "[2025.11.04-19.30.00:000][  1]GameLog: Display: [Game] PageApplyBase@ _UpdateGameEnd: LastSceneName = World'/Game/Art/Maps/07YJ/YJ_YongZhouHuiLang200/YJ_YongZhouHuiLang200.YJ_YongZhouHuiLang200' NextSceneName = World'/Game/Art/Maps/01SD/XZ_YuJinZhiXiBiNanSuo200/XZ_YuJinZhiXiBiNanSuo200.XZ_YuJinZhiXiBiNanSuo200'"
+)
+
+// replay feeds log lines through the parser into a fresh tracker.
+func replay(t *testing.T, log string) *Tracker {
+	t.Helper()
+	p := parser.New()
+	trk := New()
+	for _, line := range strings.Split(strings.TrimSpace(log), "\n") {
+		ev := p.Parse(strings.TrimSpace(line))
+		if ev == nil {
+			t.Fatalf("unparsed line: %s", line)
+		}
+		trk.OnEvent(ev)
+	}
+	return trk
+}
+
+func TestRearrangementsAreNotDrops(t *testing.T) {
+	cases := []struct {
+		name  string
+		log   string
+		tally map[int]int
+		spent map[int]int
+	}{
+		{
+			name: "move stack to empty slot",
+			log: `
+[2025.11.04-19.19.50:000][  2]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 0 ConfigBaseId = 5210 Num = 12
+` + mapStartLine + `
+[2025.11.04-19.21.10:100][ 40]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 102 SlotId = 7 ConfigBaseId = 5210 Num = 12
+[2025.11.04-19.21.10:100][ 40]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 102 SlotId = 0 ConfigBaseId = 5210 Num = 0
+` + mapEndLine,
+			tally: map[int]int{},
+			spent: map[int]int{},
+		},
+		{
+			name: "split stack",
+			log: `
+[2025.11.04-19.19.50:000][  2]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 0 ConfigBaseId = 5210 Num = 12
+` + mapStartLine + `
+[2025.11.04-19.21.10:100][ 40]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 102 SlotId = 0 ConfigBaseId = 5210 Num = 7
+[2025.11.04-19.21.10:200][ 41]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 102 SlotId = 3 ConfigBaseId = 5210 Num = 5
+` + mapEndLine,
+			tally: map[int]int{},
+			spent: map[int]int{},
+		},
+		{
+			name: "sort swaps slots without emptying them",
+			log: `
+[2025.11.04-19.19.50:000][  2]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 103 SlotId = 0 ConfigBaseId = 100300 Num = 4
+[2025.11.04-19.19.50:000][  2]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 103 SlotId = 1 ConfigBaseId = 200100 Num = 9
+` + mapStartLine + `
+[2025.11.04-19.22.00:000][ 60]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 103 SlotId = 0 ConfigBaseId = 200100 Num = 9
+[2025.11.04-19.22.00:000][ 60]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 103 SlotId = 1 ConfigBaseId = 100300 Num = 4
+` + mapEndLine,
+			tally: map[int]int{},
+			spent: map[int]int{},
+		},
+		{
+			name: "sort merging a pickup keeps only the net gain",
+			log: `
+[2025.11.04-19.19.50:000][  2]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 0 ConfigBaseId = 5210 Num = 3
+[2025.11.04-19.19.50:000][  2]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 1 ConfigBaseId = 5210 Num = 4
+` + mapStartLine + `
+[2025.11.04-19.23.00:000][ 70]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 102 SlotId = 2 ConfigBaseId = 5210 Num = 2
+[2025.11.04-19.23.05:000][ 71]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 102 SlotId = 0 ConfigBaseId = 5210 Num = 9
+[2025.11.04-19.23.05:000][ 71]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 102 SlotId = 1 ConfigBaseId = 5210 Num = 0
+[2025.11.04-19.23.05:000][ 71]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 102 SlotId = 2 ConfigBaseId = 5210 Num = 0
+` + mapEndLine,
+			tally: map[int]int{5210: 2},
+			spent: map[int]int{},
+		},
+		{
+			name: "move between pages",
+			log: `
+[2025.11.04-19.19.50:000][  2]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 101 SlotId = 5 ConfigBaseId = 300200 Num = 1
+` + mapStartLine + `
+[2025.11.04-19.24.00:000][ 80]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 101 SlotId = 5 ConfigBaseId = 300200 Num = 0
+[2025.11.04-19.24.01:000][ 81]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 104 SlotId = 0 ConfigBaseId = 300200 Num = 1
+` + mapEndLine,
+			tally: map[int]int{},
+			spent: map[int]int{},
+		},
+		{
+			name: "consumption and drops in separate moments still count",
+			log: `
+[2025.11.04-19.19.50:000][  2]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 103 SlotId = 0 ConfigBaseId = 100300 Num = 4
+` + mapStartLine + `
+[2025.11.04-19.21.00:000][ 50]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 103 SlotId = 0 ConfigBaseId = 100300 Num = 3
+[2025.11.04-19.25.00:000][ 90]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 103 SlotId = 4 ConfigBaseId = 100300 Num = 1
+` + mapEndLine,
+			tally: map[int]int{100300: 1},
+			spent: map[int]int{100300: 1},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := replay(t, tc.log).GetState()
+			if len(st.Completed) != 1 {
+				t.Fatalf("expected one completed map, got %d", len(st.Completed))
+			}
+			m := st.Completed[0]
+			if !sameCounts(m.Tally, tc.tally) || !sameCounts(m.Spent, tc.spent) {
+				t.Fatalf("tally %v spent %v; want tally %v spent %v", m.Tally, m.Spent, tc.tally, tc.spent)
+			}
+			var drops int
+			for _, n := range tc.tally {
+				drops += n
+			}
+			if st.TotalDrops != drops {
+				t.Fatalf("TotalDrops = %d want %d", st.TotalDrops, drops)
+			}
+		})
+	}
+}
+
+func TestTownRearrangementIsNotEntryCost(t *testing.T) {
+	st := replay(t, `
+[2025.11.04-19.19.00:000][  2]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 103 SlotId = 0 ConfigBaseId = 100300 Num = 4
+[2025.11.04-19.19.40:000][  3]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 103 SlotId = 6 ConfigBaseId = 100300 Num = 4
+[2025.11.04-19.19.40:000][  3]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 103 SlotId = 0 ConfigBaseId = 100300 Num = 0
+[2025.11.04-19.19.55:000][  4]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 103 SlotId = 6 ConfigBaseId = 100300 Num = 3
+`+mapStartLine+"\n"+mapEndLine).GetState()
+	if got := st.Completed[0].Spent; !sameCounts(got, map[int]int{100300: 1}) {
+		t.Fatalf("expected only the compass used to open the map as cost, got %v", got)
+	}
+}
+
+func sameCounts(got, want map[int]int) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for id, n := range want {
+		if got[id] != n {
+			return false
+		}
+	}
+	return true
+}
+
+func TestOnDropConfirmsOnlyUnmatchedDrops(t *testing.T) {
+	p := parser.New()
+	trk := New()
+	var drops []Drop
+	trk.OnDrop(func(d Drop) { drops = append(drops, d) })
+	feed := func(log string) {
+		for _, line := range strings.Split(strings.TrimSpace(log), "\n") {
+			trk.OnEvent(p.Parse(strings.TrimSpace(line)))
+		}
+	}
+	feed(`
+[2025.11.04-19.19.50:000][  2]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 0 ConfigBaseId = 5210 Num = 12
+` + mapStartLine + `
+[2025.11.04-19.21.00:000][ 40]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 103 SlotId = 0 ConfigBaseId = 100300 Num = 3
+[2025.11.04-19.21.01:000][ 41]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 102 SlotId = 7 ConfigBaseId = 5210 Num = 12`)
+	if len(drops) != 0 {
+		t.Fatalf("expected drops to wait for the reconcile window, got %+v", drops)
+	}
+	feed(`
+[2025.11.04-19.21.01:000][ 41]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 102 SlotId = 0 ConfigBaseId = 5210 Num = 0
+[2025.11.04-19.21.05:000][ 42]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 103 SlotId = 1 ConfigBaseId = 300200 Num = 1`)
+	if len(drops) != 1 || drops[0].ItemID != 100300 || drops[0].Count != 3 || drops[0].MapKey != "YJ_YongZhouHuiLang200" {
+		t.Fatalf("expected only the elementium confirmed, got %+v", drops)
+	}
+	feed(mapEndLine)
+	if len(drops) != 2 || drops[1].ItemID != 300200 {
+		t.Fatalf("expected MapEnd to confirm the last drop, got %+v", drops)
+	}
+}
+
+func TestSettleConfirmsDropsOfAQuietLog(t *testing.T) {
+	p := parser.New()
+	trk := New()
+	var drops []Drop
+	trk.OnDrop(func(d Drop) { drops = append(drops, d) })
+	for _, line := range strings.Split(strings.TrimSpace(`
+[2025.11.04-19.19.50:000][  2]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 103 SlotId = 0 ConfigBaseId = 100300 Num = 0
+`+mapStartLine+`
+[2025.11.04-19.21.00:000][ 40]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 103 SlotId = 0 ConfigBaseId = 100300 Num = 3`), "\n") {
+		trk.OnEvent(p.Parse(strings.TrimSpace(line)))
+	}
+	trk.Settle(time.Second)
+	if len(drops) != 0 {
+		t.Fatalf("expected the drop to wait for the reconcile window, got %+v", drops)
+	}
+	// no further lines; the log has been quiet for longer than the window
+	trk.Settle(ReconcileWindow + time.Second)
+	if len(drops) != 1 || drops[0].ItemID != 100300 || drops[0].Count != 3 {
+		t.Fatalf("expected the drop confirmed without further events, got %+v", drops)
+	}
+	if !trk.GetState().InMap {
+		t.Fatal("expected Settle to leave the map open")
+	}
+}
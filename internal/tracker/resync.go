@@ -0,0 +1,130 @@
+package tracker
+
+import (
+	"time"
+
+	"GoTorch/internal/types"
+)
+
+// ResyncWindow is the largest gap between InitBagData lines of the same burst.
+const ResyncWindow = 500 * time.Millisecond
+
+// resyncKey is the slot reported for per-item changes found by a resync, which are
+// net over every resynced page rather than tied to one slot.
+var resyncKey = slotKey{PageID: -1, SlotID: -1}
+
+func sameBurst(last, at time.Time) bool {
+	return !at.Before(last) && at.Sub(last) <= ResyncWindow
+}
+
+// Flush applies a pending burst of InitBagData lines and confirms the drops still waiting to be
+// reconciled. Both otherwise happen as later events arrive; call Flush at the end of the input,
+// e.g. after reading a whole log.
+func (t *Tracker) Flush() {
+	t.mu.Lock()
+	t.flushInits()
+	t.settleMoves()
+	t.unlockAndNotify()
+}
+
+// Settle applies what a quiet log leaves waiting for the next event: an InitBagData burst that
+// has ended, and the drops logged more than ReconcileWindow ago. quiet is how long no line has
+// been read; the log's clock is taken to have moved on by as much since its last event. Call it
+// periodically while following a log.
+func (t *Tracker) Settle(quiet time.Duration) {
+	t.mu.Lock()
+	now := t.state.LastEventAt.Add(quiet)
+	if len(t.inits) > 0 && !sameBurst(t.initsAt, now) {
+		t.flushInits()
+	}
+	t.pruneMoves(now)
+	t.unlockAndNotify()
+}
+
+// flushInits applies the pending InitBagData burst page by page:
+//   - a page without a known baseline (first sync, or after Reset) only sets the baseline;
+//   - a page whose burst re-lists several of its occupied slots, or all of them, is a full resync:
+//     its contents are replaced and the per-item differences against the old baseline, summed over
+//     all resynced pages, count like any other bag change;
+//   - otherwise each init updates its own slot, e.g. a pickup that did not stack or a lone occupied
+//     slot reported again, and counts like a BagMod.
+//
+// t.mu must be held.
+func (t *Tracker) flushInits() {
+	inits, at := t.inits, t.initsAt
+	t.inits = nil
+	if len(inits) == 0 {
+		return
+	}
+	byPage := make(map[int][]types.BagEvent)
+	var pages []int
+	for _, b := range inits {
+		if _, ok := byPage[b.PageID]; !ok {
+			pages = append(pages, b.PageID)
+		}
+		byPage[b.PageID] = append(byPage[b.PageID], b)
+	}
+	occupied := make(map[[2]int]bool)
+	known := make(map[int]bool)
+	slots := make(map[int]int) // occupied slots per page
+	for k, n := range t.state.Inventory {
+		known[k.PageID] = true
+		if n > 0 && !occupied[[2]int{k.PageID, k.SlotID}] {
+			occupied[[2]int{k.PageID, k.SlotID}] = true
+			slots[k.PageID]++
+		}
+	}
+
+	diff := make(map[int]int) // per-item change over the resynced pages
+	var resynced bool
+	for _, page := range pages {
+		lines := byPage[page]
+		relisted := make(map[int]bool)
+		for _, b := range lines {
+			if occupied[[2]int{b.PageID, b.SlotID}] {
+				relisted[b.SlotID] = true
+			}
+		}
+		full := len(relisted) > 0 && (len(relisted) >= 2 || len(relisted) == slots[page])
+		switch {
+		case !known[page]:
+			for _, b := range lines {
+				key := slotKey{PageID: b.PageID, SlotID: b.SlotID, ConfigBaseID: b.ConfigBaseID}
+				t.vacate(key)
+				t.state.Inventory[key] = b.Num
+			}
+		case full:
+			resynced = true
+			for k, n := range t.state.Inventory {
+				if k.PageID == page {
+					diff[k.ConfigBaseID] -= n
+					delete(t.state.Inventory, k)
+				}
+			}
+			for _, b := range lines {
+				key := slotKey{PageID: b.PageID, SlotID: b.SlotID, ConfigBaseID: b.ConfigBaseID}
+				t.vacate(key)
+				t.state.Inventory[key] = b.Num
+				diff[b.ConfigBaseID] += b.Num
+			}
+		default:
+			for _, b := range lines {
+				key := slotKey{PageID: b.PageID, SlotID: b.SlotID, ConfigBaseID: b.ConfigBaseID}
+				for id, n := range t.vacate(key) {
+					t.change(key, id, -n, at)
+				}
+				if delta := b.Num - t.state.Inventory[key]; delta != 0 {
+					t.state.Inventory[key] = b.Num
+					t.change(key, b.ConfigBaseID, delta, at)
+				}
+			}
+		}
+	}
+	if resynced {
+		for id, d := range diff {
+			if d != 0 {
+				t.change(resyncKey, id, d, at)
+			}
+		}
+	}
+}
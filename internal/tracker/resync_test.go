@@ -0,0 +1,98 @@
+package tracker
+
+import (
+	"testing"
+)
+
+func TestBagInitBursts(t *testing.T) {
+	const baseline = `
+[2025.11.04-19.19.50:000][  2]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 0 ConfigBaseId = 5210 Num = 3
+[2025.11.04-19.19.50:000][  2]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 1 ConfigBaseId = 100300 Num = 2
+`
+	cases := []struct {
+		name  string
+		log   string
+		tally map[int]int
+		spent map[int]int
+	}{
+		{
+			name: "resync after zone change reveals hidden drops",
+			log: baseline + mapStartLine + `
+[2025.11.04-19.20.01:000][  5]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 0 ConfigBaseId = 5210 Num = 5
+[2025.11.04-19.20.01:000][  5]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 1 ConfigBaseId = 100300 Num = 2
+[2025.11.04-19.20.01:000][  5]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 2 ConfigBaseId = 777 Num = 1
+` + mapEndLine,
+			tally: map[int]int{5210: 2, 777: 1},
+			spent: map[int]int{},
+		},
+		{
+			name: "resync with rearranged slots only counts the net change",
+			log: baseline + mapStartLine + `
+[2025.11.04-19.20.01:000][  5]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 0 ConfigBaseId = 100300 Num = 1
+[2025.11.04-19.20.01:000][  5]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 1 ConfigBaseId = 5210 Num = 3
+` + mapEndLine,
+			tally: map[int]int{},
+			spent: map[int]int{100300: 1},
+		},
+		{
+			name: "single init filling a new slot is a drop",
+			log: baseline + mapStartLine + `
+[2025.11.04-19.21.00:000][ 30]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 4 ConfigBaseId = 777 Num = 1
+` + mapEndLine,
+			tally: map[int]int{777: 1},
+			spent: map[int]int{},
+		},
+		{
+			name: "several pickups into new slots at once are not a resync",
+			log: baseline + mapStartLine + `
+[2025.11.04-19.21.00:000][ 30]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 4 ConfigBaseId = 777 Num = 1
+[2025.11.04-19.21.00:000][ 30]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 5 ConfigBaseId = 888 Num = 2
+` + mapEndLine,
+			tally: map[int]int{777: 1, 888: 2},
+			spent: map[int]int{},
+		},
+		{
+			name: "single init of an occupied slot only changes that slot",
+			log: baseline + `[2025.11.04-19.19.50:000][  2]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 2 ConfigBaseId = 777 Num = 9
+` + mapStartLine + `
+[2025.11.04-19.21.00:000][ 30]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 0 ConfigBaseId = 5210 Num = 4
+` + mapEndLine,
+			tally: map[int]int{5210: 1},
+			spent: map[int]int{},
+		},
+		{
+			name: "first sync after a reset only sets the baseline",
+			log: mapStartLine + `
+[2025.11.04-19.20.01:000][  5]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 0 ConfigBaseId = 5210 Num = 40
+[2025.11.04-19.20.01:000][  5]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 1 ConfigBaseId = 100300 Num = 2
+[2025.11.04-19.21.00:000][ 30]GameLog: Display: [Game] BagMgr@:Modfy BagItem PageId = 102 SlotId = 0 ConfigBaseId = 5210 Num = 41
+` + mapEndLine,
+			tally: map[int]int{5210: 1},
+			spent: map[int]int{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := replay(t, tc.log).GetState()
+			if len(st.Completed) != 1 {
+				t.Fatalf("expected one completed map, got %d", len(st.Completed))
+			}
+			m := st.Completed[0]
+			if !sameCounts(m.Tally, tc.tally) || !sameCounts(m.Spent, tc.spent) {
+				t.Fatalf("tally %v spent %v; want tally %v spent %v", m.Tally, m.Spent, tc.tally, tc.spent)
+			}
+		})
+	}
+}
+
+func TestFlushAppliesTrailingBurst(t *testing.T) {
+	trk := replay(t, mapStartLine+`
+[2025.11.04-19.20.01:000][  5]GameLog: Display: [Game] BagMgr@:InitBagData PageId = 102 SlotId = 0 ConfigBaseId = 5210 Num = 4`)
+	if n := len(trk.GetState().Inventory); n != 0 {
+		t.Fatalf("burst applied before it ended: %d slots", n)
+	}
+	trk.Flush()
+	if got := trk.GetState().Inventory[slotKey{PageID: 102, SlotID: 0, ConfigBaseID: 5210}]; got != 4 {
+		t.Fatalf("expected baseline 4 after Flush, got %d", got)
+	}
+}
@@ -0,0 +1,200 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"GoTorch/internal/types"
+)
+
+// SnapshotVersion is the current snapshot format; bump it whenever the format changes.
+// Version 2 added the changes still being reconciled: the pending InitBagData burst, recent
+// per-item changes and consumptions that may be charged to the next map. Restore accepts
+// older versions, which lack them, and rejects newer ones.
+const SnapshotVersion = 2
+
+// ErrSnapshotVersion is returned by Restore for snapshots of an unsupported format.
+var ErrSnapshotVersion = errors.New("tracker: unsupported snapshot version")
+
+// snapshot is the serialized form of State.
+type snapshot struct {
+	Version          int              `json:"version"`
+	InMap            bool             `json:"in_map"`
+	Current          MapSession       `json:"current"`
+	Completed        []MapSession     `json:"completed"`
+	SessionStartedAt time.Time        `json:"session_started_at"`
+	SessionEndedAt   time.Time        `json:"session_ended_at"`
+	TotalDrops       int              `json:"total_drops"`
+	LastEvents       []types.Event    `json:"last_events"`
+	Inventory        []inventoryEntry `json:"inventory"`
+	InMapTime        time.Duration    `json:"in_map_time"`
+	TownTime         time.Duration    `json:"town_time"`
+	IdleTime         time.Duration    `json:"idle_time"`
+	LastEventAt      time.Time        `json:"last_event_at"`
+	IdleThreshold    *time.Duration   `json:"idle_threshold,omitempty"` // nil in snapshots older than the setting
+	Paused           bool             `json:"paused"`
+	PausedTime       time.Duration    `json:"paused_time"`
+	Pauses           []PauseInterval  `json:"pauses"`
+	// since version 2
+	Inits         []inventoryEntry `json:"inits"`
+	InitsAt       time.Time        `json:"inits_at"`
+	Moves         []moveEntry      `json:"moves"`
+	PendingSpends []spendEntry     `json:"pending_spends"`
+}
+
+// inventoryEntry is one Inventory slot; slotKey is unexported so it is flattened here.
+type inventoryEntry struct {
+	PageID       int `json:"page_id"`
+	SlotID       int `json:"slot_id"`
+	ConfigBaseID int `json:"config_base_id"`
+	Num          int `json:"num"`
+}
+
+// moveEntry is a move, a recent change that a later opposite change may still cancel.
+type moveEntry struct {
+	PageID       int        `json:"page_id"`
+	SlotID       int        `json:"slot_id"`
+	ConfigBaseID int        `json:"config_base_id"`
+	Num          int        `json:"num"`
+	Gain         bool       `json:"gain"`
+	Where        moveTarget `json:"where"`
+	Unit         float64    `json:"unit"`
+	At           time.Time  `json:"at"`
+}
+
+// spendEntry is a consumption outside a map that may still be charged to the next one.
+type spendEntry struct {
+	ConfigBaseID int       `json:"config_base_id"`
+	Num          int       `json:"num"`
+	Value        float64   `json:"value"`
+	At           time.Time `json:"at"`
+}
+
+// Snapshot serializes the full tracker state, including the inventory baseline and the
+// changes still being reconciled, in a versioned JSON format that Restore accepts.
+func (t *Tracker) Snapshot() ([]byte, error) {
+	t.mu.Lock()
+	st := t.copyState()
+	inits := make([]inventoryEntry, 0, len(t.inits))
+	for _, b := range t.inits {
+		inits = append(inits, inventoryEntry{PageID: b.PageID, SlotID: b.SlotID, ConfigBaseID: b.ConfigBaseID, Num: b.Num})
+	}
+	moves := make([]moveEntry, 0, len(t.moves))
+	for _, mv := range t.moves {
+		moves = append(moves, moveEntry{PageID: mv.page, SlotID: mv.slot, ConfigBaseID: mv.id, Num: mv.n, Gain: mv.gain, Where: mv.where, Unit: mv.unit, At: mv.at})
+	}
+	spends := make([]spendEntry, 0, len(t.pending))
+	for _, sp := range t.pending {
+		spends = append(spends, spendEntry{ConfigBaseID: sp.id, Num: sp.n, Value: sp.value, At: sp.at})
+	}
+	initsAt := t.initsAt
+	t.mu.Unlock()
+
+	snap := snapshot{
+		Version:          SnapshotVersion,
+		InMap:            st.InMap,
+		Current:          st.Current,
+		Completed:        st.Completed,
+		SessionStartedAt: st.SessionStartedAt,
+		SessionEndedAt:   st.SessionEndedAt,
+		TotalDrops:       st.TotalDrops,
+		LastEvents:       st.LastEvents,
+		Inventory:        make([]inventoryEntry, 0, len(st.Inventory)),
+		InMapTime:        st.InMapTime,
+		TownTime:         st.TownTime,
+		IdleTime:         st.IdleTime,
+		LastEventAt:      st.LastEventAt,
+		IdleThreshold:    &st.IdleThreshold,
+		Paused:           st.Paused,
+		PausedTime:       st.PausedTime,
+		Pauses:           st.Pauses,
+		Inits:            inits,
+		InitsAt:          initsAt,
+		Moves:            moves,
+		PendingSpends:    spends,
+	}
+	for k, n := range st.Inventory {
+		snap.Inventory = append(snap.Inventory, inventoryEntry{PageID: k.PageID, SlotID: k.SlotID, ConfigBaseID: k.ConfigBaseID, Num: n})
+	}
+	return json.Marshal(snap)
+}
+
+// Restore creates a tracker from data produced by Snapshot. Timestamps are restored
+// as the same instants in the local time zone.
+func Restore(data []byte) (*Tracker, error) {
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	if snap.Version < 1 || snap.Version > SnapshotVersion {
+		return nil, fmt.Errorf("%w: %d", ErrSnapshotVersion, snap.Version)
+	}
+	t := New()
+	t.state.InMap = snap.InMap
+	t.state.Current = restoreSession(snap.Current)
+	t.state.Completed = make([]MapSession, 0, len(snap.Completed))
+	for _, m := range snap.Completed {
+		t.state.Completed = append(t.state.Completed, restoreSession(m))
+	}
+	t.state.SessionStartedAt = localTime(snap.SessionStartedAt)
+	t.state.SessionEndedAt = localTime(snap.SessionEndedAt)
+	t.state.TotalDrops = snap.TotalDrops
+	t.state.InMapTime = snap.InMapTime
+	t.state.TownTime = snap.TownTime
+	t.state.IdleTime = snap.IdleTime
+	t.state.LastEventAt = localTime(snap.LastEventAt)
+	t.state.Paused = snap.Paused
+	t.state.PausedTime = snap.PausedTime
+	for _, p := range snap.Pauses {
+		t.state.Pauses = append(t.state.Pauses, PauseInterval{Start: localTime(p.Start), End: localTime(p.End)})
+	}
+	if snap.IdleThreshold != nil {
+		t.state.IdleThreshold = *snap.IdleThreshold
+	}
+	t.state.LastEvents = snap.LastEvents
+	for i := range t.state.LastEvents {
+		t.state.LastEvents[i].Time = localTime(t.state.LastEvents[i].Time)
+	}
+	for _, e := range snap.Inventory {
+		t.state.Inventory[slotKey{PageID: e.PageID, SlotID: e.SlotID, ConfigBaseID: e.ConfigBaseID}] = e.Num
+	}
+	for _, e := range snap.Inits {
+		t.inits = append(t.inits, types.BagEvent{PageID: e.PageID, SlotID: e.SlotID, ConfigBaseID: e.ConfigBaseID, Num: e.Num})
+	}
+	t.initsAt = localTime(snap.InitsAt)
+	for _, e := range snap.Moves {
+		t.moves = append(t.moves, move{page: e.PageID, slot: e.SlotID, id: e.ConfigBaseID, n: e.Num, gain: e.Gain, where: e.Where, unit: e.Unit, at: localTime(e.At)})
+	}
+	for _, e := range snap.PendingSpends {
+		t.pending = append(t.pending, spend{id: e.ConfigBaseID, n: e.Num, value: e.Value, at: localTime(e.At)})
+	}
+	return t, nil
+}
+
+func restoreSession(m MapSession) MapSession {
+	m.StartedAt = localTime(m.StartedAt)
+	m.EndedAt = localTime(m.EndedAt)
+	if m.Tally == nil {
+		m.Tally = make(map[int]int)
+	}
+	if m.Value == nil {
+		m.Value = make(map[int]float64)
+	}
+	if m.Spent == nil {
+		m.Spent = make(map[int]int)
+	}
+	if m.SpentValue == nil {
+		m.SpentValue = make(map[int]float64)
+	}
+	return m
+}
+
+// localTime converts a decoded timestamp to the local zone, keeping zero times zero.
+func localTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Time{}
+	}
+	return t.Local()
+}
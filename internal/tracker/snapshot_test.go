@@ -0,0 +1,199 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"GoTorch/internal/types"
+)
+
+func TestSnapshotRestoreContinuesMidMap(t *testing.T) {
+	trk := New()
+	start := time.Now()
+	trk.OnEvent(&types.Event{Kind: types.EventBagInit, Time: start, Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 5, Num: 10}})
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start, Scene: &types.SceneEvent{MapKey: "A"}})
+	trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start.Add(time.Second), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 5, Num: 12}})
+
+	b, err := trk.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	restored, err := Restore(b)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	// The inventory baseline survives, so the next delta is +1 rather than +13
+	restored.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start.Add(2 * time.Second), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 5, Num: 13}})
+	st := restored.GetState()
+	if !st.InMap || st.Current.MapKey != "A" || st.Current.Tally[5] != 3 || st.TotalDrops != 3 {
+		t.Fatalf("unexpected restored state: %+v", st)
+	}
+	if !st.SessionStartedAt.Equal(start) {
+		t.Fatalf("session start = %v want %v", st.SessionStartedAt, start)
+	}
+}
+
+func TestSnapshotRoundTripIsExact(t *testing.T) {
+	trk := New()
+	base := time.Date(2025, 11, 4, 19, 20, 45, 474e6, time.Local)
+	scene := &types.SceneEvent{Path: "/Game/Art/Maps/07YJ/YJ_A/YJ_A.YJ_A", MapKey: "YJ_A", Region: "07YJ", PrevPath: "/Game/Art/Maps/UI/LoginScene/LoginScene"}
+	events := []*types.Event{
+		{Kind: types.EventBagInit, Time: base, Line: "init", Bag: &types.BagEvent{PageID: 102, SlotID: 3, ConfigBaseID: 100300, Num: 40}},
+		{Kind: types.EventMapStart, Time: base.Add(time.Second), Line: "start", Scene: scene},
+		{Kind: types.EventBagMod, Time: base.Add(2 * time.Second), Line: "mod", Bag: &types.BagEvent{PageID: 102, SlotID: 3, ConfigBaseID: 100300, Num: 47}},
+		{Kind: types.EventMapEnd, Time: base.Add(3 * time.Minute), Line: "end", Scene: &types.SceneEvent{MapKey: "XZ"}},
+		{Kind: types.EventMapStart, Time: base.Add(4 * time.Minute), Line: "start2", Scene: scene},
+		{Kind: types.EventBagMod, Time: base.Add(5 * time.Minute), Line: "mod2", Bag: &types.BagEvent{PageID: 102, SlotID: 4, ConfigBaseID: 5210, Num: 2}},
+	}
+	for _, ev := range events {
+		trk.OnEvent(ev)
+	}
+	b, err := trk.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	restored, err := Restore(b)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	want, got := trk.GetState(), restored.GetState()
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("round trip mismatch\nwant %+v\ngot  %+v", want, got)
+	}
+	// A snapshot of the restored tracker is byte-identical apart from map ordering
+	b2, _ := restored.Snapshot()
+	var s1, s2 snapshot
+	_ = json.Unmarshal(b, &s1)
+	_ = json.Unmarshal(b2, &s2)
+	if len(s1.Inventory) != len(s2.Inventory) || s1.TotalDrops != s2.TotalDrops || len(s1.LastEvents) != len(s2.LastEvents) {
+		t.Fatalf("re-snapshot differs: %+v vs %+v", s1, s2)
+	}
+}
+
+func TestRestoreRejectsBadInput(t *testing.T) {
+	if _, err := Restore([]byte("{")); err == nil {
+		t.Fatal("expected error for invalid snapshot")
+	}
+	if _, err := Restore([]byte(`{"version":99}`)); !errors.Is(err, ErrSnapshotVersion) {
+		t.Fatalf("expected ErrSnapshotVersion, got %v", err)
+	}
+	if _, err := Restore([]byte(`{"in_map":true}`)); !errors.Is(err, ErrSnapshotVersion) {
+		t.Fatalf("expected ErrSnapshotVersion for unversioned snapshot, got %v", err)
+	}
+}
+
+func TestRestoreEmptyTracker(t *testing.T) {
+	b, err := New().Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	restored, err := Restore(b)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	st := restored.GetState()
+	if st.InMap || !st.SessionStartedAt.IsZero() || len(st.Completed) != 0 || len(st.Inventory) != 0 {
+		t.Fatalf("unexpected state from empty snapshot: %+v", st)
+	}
+}
+
+func TestSnapshotMidReconcileWindowKeepsPendingChanges(t *testing.T) {
+	base := time.Date(2025, 11, 4, 19, 20, 45, 0, time.Local)
+	bag := func(kind types.EventKind, at time.Duration, page, slot, id, num int) *types.Event {
+		return &types.Event{Kind: kind, Time: base.Add(at), Bag: &types.BagEvent{PageID: page, SlotID: slot, ConfigBaseID: id, Num: num}}
+	}
+	trk := New()
+	trk.OnEvent(bag(types.EventBagInit, 0, 1, 1, 5, 10))
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: base.Add(time.Second), Scene: &types.SceneEvent{MapKey: "A"}})
+	// half of a move to another slot: counted as a drop until the other half arrives
+	trk.OnEvent(bag(types.EventBagMod, 2*time.Second, 1, 2, 5, 3))
+
+	b, err := trk.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	restored, err := Restore(b)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if !reflect.DeepEqual(trk.moves, restored.moves) {
+		t.Fatalf("moves = %+v want %+v", restored.moves, trk.moves)
+	}
+	restored.OnEvent(bag(types.EventBagMod, 2500*time.Millisecond, 1, 1, 5, 7))
+	st := restored.GetState()
+	if st.TotalDrops != 0 || len(st.Current.Tally) != 0 || len(st.Current.Spent) != 0 {
+		t.Fatalf("move across the snapshot was not reconciled: drops=%d tally=%v spent=%v", st.TotalDrops, st.Current.Tally, st.Current.Spent)
+	}
+}
+
+func TestSnapshotKeepsPendingSpendsAndInits(t *testing.T) {
+	base := time.Date(2025, 11, 4, 19, 20, 45, 0, time.Local)
+	bag := func(kind types.EventKind, at time.Duration, page, slot, id, num int) *types.Event {
+		return &types.Event{Kind: kind, Time: base.Add(at), Bag: &types.BagEvent{PageID: page, SlotID: slot, ConfigBaseID: id, Num: num}}
+	}
+	trk := New()
+	trk.OnEvent(bag(types.EventBagInit, 0, 1, 1, 5, 10))
+	// consumed outside a map: held for the next map
+	trk.OnEvent(bag(types.EventBagMod, time.Second, 1, 1, 5, 8))
+	// a burst still waiting for its end
+	trk.OnEvent(bag(types.EventBagInit, 1200*time.Millisecond, 2, 1, 7, 1))
+
+	b, err := trk.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	restored, err := Restore(b)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if !reflect.DeepEqual(trk.pending, restored.pending) || !reflect.DeepEqual(trk.inits, restored.inits) || !trk.initsAt.Equal(restored.initsAt) {
+		t.Fatalf("pending state differs: pending=%+v inits=%+v at %v", restored.pending, restored.inits, restored.initsAt)
+	}
+	restored.OnEvent(&types.Event{Kind: types.EventMapStart, Time: base.Add(2 * time.Second), Scene: &types.SceneEvent{MapKey: "A"}})
+	st := restored.GetState()
+	if st.Current.Spent[5] != 2 {
+		t.Fatalf("pending spend not charged to the map: %v", st.Current.Spent)
+	}
+	if st.Inventory[slotKey{PageID: 2, SlotID: 1, ConfigBaseID: 7}] != 1 {
+		t.Fatalf("pending init burst not applied: %v", st.Inventory)
+	}
+}
+
+func TestRestoreAcceptsVersion1(t *testing.T) {
+	restored, err := Restore([]byte(`{"version":1,"total_drops":3}`))
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if st := restored.GetState(); st.TotalDrops != 3 {
+		t.Fatalf("unexpected state from a version 1 snapshot: %+v", st)
+	}
+}
+
+func TestSnapshotKeepsIdleThreshold(t *testing.T) {
+	for _, d := range []time.Duration{0, 2 * time.Minute} {
+		trk := New()
+		trk.SetIdleThreshold(d)
+		b, err := trk.Snapshot()
+		if err != nil {
+			t.Fatalf("Snapshot: %v", err)
+		}
+		restored, err := Restore(b)
+		if err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if got := restored.GetState().IdleThreshold; got != d {
+			t.Fatalf("idle threshold = %v want %v", got, d)
+		}
+	}
+	// snapshots from before the setting get the default
+	restored, err := Restore([]byte(`{"version":1}`))
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got := restored.GetState().IdleThreshold; got != DefaultIdleThreshold {
+		t.Fatalf("idle threshold = %v want %v", got, DefaultIdleThreshold)
+	}
+}
@@ -17,8 +17,104 @@ type MapSession struct {
 	StartedAt time.Time
 	EndedAt   time.Time
 	Active    bool
+	// Map identity taken from the MapStart scene transition
+	MapKey    string // e.g. YJ_YongZhouHuiLang200
+	Region    string // e.g. 07YJ
+	ScenePath string // full NextSceneName path
+	PrevScene string // LastSceneName before entering the map
 	// Tally by ConfigBaseID -> total picked up during this session
 	Tally map[int]int
+	// Value by ConfigBaseID -> worth of those drops at the unit price in effect when each dropped.
+	// Only filled when the tracker has a PriceFunc (see SetPricing).
+	Value map[int]float64
+	// Spent by ConfigBaseID -> total consumed during this session, including entry costs
+	// (compasses, beacons) used up to PreMapSpendWindow before MapStart.
+	Spent map[int]int
+	// SpentValue by ConfigBaseID -> worth of those items at the unit price in effect when each was consumed.
+	SpentValue map[int]float64
+	// PausedTime is how long tracking was paused during the map; set when the map is finalized.
+	PausedTime time.Duration
+}
+
+// Duration returns the run time of a finalized map, excluding paused time.
+func (m MapSession) Duration() time.Duration {
+	d := m.EndedAt.Sub(m.StartedAt) - m.PausedTime
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// PreMapSpendWindow is how long before a MapStart consumed items are still charged to that map.
+const PreMapSpendWindow = 60 * time.Second
+
+// ReconcileWindow is how close in time an increase and a decrease of the same item in different
+// slots must be to cancel out as a rearrangement rather than count as a drop and a consumption.
+const ReconcileWindow = 2 * time.Second
+
+// clone returns a copy of the session with its own maps.
+func (m MapSession) clone() MapSession {
+	cm := m
+	cm.Tally = make(map[int]int, len(m.Tally))
+	for k, v := range m.Tally {
+		cm.Tally[k] = v
+	}
+	cm.Value = make(map[int]float64, len(m.Value))
+	for k, v := range m.Value {
+		cm.Value[k] = v
+	}
+	cm.Spent = make(map[int]int, len(m.Spent))
+	for k, v := range m.Spent {
+		cm.Spent[k] = v
+	}
+	cm.SpentValue = make(map[int]float64, len(m.SpentValue))
+	for k, v := range m.SpentValue {
+		cm.SpentValue[k] = v
+	}
+	return cm
+}
+
+// spend is an item consumption seen outside a map, held until the next MapStart.
+type spend struct {
+	id    int
+	n     int
+	value float64
+	at    time.Time
+}
+
+// moveTarget says what a per-item change was counted as.
+type moveTarget int
+
+const (
+	uncounted moveTarget = iota // a gain outside a map
+	inMap                       // a drop or consumption of the current map
+	preMap                      // a consumption held in Tracker.pending
+)
+
+// move is a recent per-item change that a later opposite change may still cancel.
+type move struct {
+	page  int
+	slot  int
+	id    int
+	n     int // items not matched by an opposite change yet
+	gain  bool
+	where moveTarget
+	unit  float64 // unit price when the change happened
+	at    time.Time
+}
+
+// addSpent charges n consumed items of id to the session.
+func (m *MapSession) addSpent(id, n int, value float64, priced bool) {
+	if m.Spent == nil {
+		m.Spent = make(map[int]int)
+	}
+	m.Spent[id] += n
+	if priced {
+		if m.SpentValue == nil {
+			m.SpentValue = make(map[int]float64)
+		}
+		m.SpentValue[id] += value
+	}
 }
 
 // State holds the overall tracking state across the whole run ("session").
@@ -32,22 +128,155 @@ type State struct {
 	TotalDrops       int
 	LastEvents       []types.Event
 	Inventory        map[slotKey]int // latest known counts per slot+item
+	// Time since the session started, split by activity up to LastEventAt (see Activity).
+	// A gap between events longer than IdleThreshold is idle time.
+	InMapTime     time.Duration
+	TownTime      time.Duration
+	IdleTime      time.Duration
+	LastEventAt   time.Time
+	IdleThreshold time.Duration
+	// While Paused, bag changes only update Inventory and time goes to PausedTime.
+	// Pauses is the timeline of paused intervals.
+	Paused     bool
+	PausedTime time.Duration
+	Pauses     []PauseInterval
+}
+
+// PauseInterval is a paused stretch of the session; End is zero while still paused.
+type PauseInterval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// PausedBetween returns how much of [from, to] was paused. An open pause lasts until to.
+func (st State) PausedBetween(from, to time.Time) time.Duration {
+	var d time.Duration
+	for _, p := range st.Pauses {
+		start, end := p.Start, p.End
+		if end.IsZero() || end.After(to) {
+			end = to
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(start) {
+			d += end.Sub(start)
+		}
+	}
+	return d
+}
+
+// CurrentDuration returns the run time of the current map up to now (or its end), excluding paused time.
+func (st State) CurrentDuration(now time.Time) time.Duration {
+	m := st.Current
+	if m.StartedAt.IsZero() {
+		return 0
+	}
+	if !m.Active {
+		return m.Duration()
+	}
+	d := now.Sub(m.StartedAt) - st.PausedBetween(m.StartedAt, now)
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// DefaultIdleThreshold is the gap between events after which the player is considered idle.
+const DefaultIdleThreshold = 5 * time.Minute
+
+// Activity splits session time into time in maps, active time in town, idle and paused time.
+type Activity struct {
+	InMap  time.Duration
+	Town   time.Duration
+	Idle   time.Duration
+	Paused time.Duration
+}
+
+// Active returns the non-idle time.
+func (a Activity) Active() time.Duration {
+	return a.InMap + a.Town
+}
+
+// Activity returns the session time split by activity up to now. The time since the last
+// event counts like any other gap, so it becomes idle once it exceeds IdleThreshold.
+func (st State) Activity(now time.Time) Activity {
+	a := Activity{InMap: st.InMapTime, Town: st.TownTime, Idle: st.IdleTime, Paused: st.PausedTime}
+	if !st.SessionStartedAt.IsZero() && now.After(st.LastEventAt) {
+		st.addGap(&a, now.Sub(st.LastEventAt))
+	}
+	return a
+}
+
+// addGap adds the time between two events to a, as paused time while paused, as in map or
+// town time depending on the current map, or as idle time when it exceeds IdleThreshold.
+func (st State) addGap(a *Activity, gap time.Duration) {
+	switch {
+	case st.Paused:
+		a.Paused += gap
+	case st.IdleThreshold > 0 && gap > st.IdleThreshold:
+		a.Idle += gap
+	case st.InMap && st.Current.Active:
+		a.InMap += gap
+	default:
+		a.Town += gap
+	}
+}
+
+// MapCompleteFunc is called after a map session has been finalized.
+type MapCompleteFunc func(sessionStartedAt time.Time, m MapSession)
+
+// MapStartFunc is called when a map session starts, after the previous one was finalized.
+type MapStartFunc func(sessionStartedAt time.Time, m MapSession)
+
+// Drop is a counted drop that can no longer be cancelled as a rearrangement, i.e. one that
+// stayed unmatched for ReconcileWindow or until the map ended.
+type Drop struct {
+	ItemID int
+	Count  int
+	Unit   float64 // unit price when it dropped; 0 without SetPricing
+	At     time.Time
+	MapKey string
 }
 
+// DropFunc is called for each confirmed drop.
+type DropFunc func(d Drop)
+
+// PriceFunc returns the unit price of an item ConfigBaseID at the given time (0 when unknown).
+type PriceFunc func(id int, at time.Time) float64
+
 type Tracker struct {
 	mu    sync.Mutex
 	state State
 	// configuration knobs may go here later (filters, value tables)
+	onMapComplete MapCompleteFunc
+	onMapStart    MapStartFunc
+	onDrop        DropFunc
+	price         PriceFunc
+	// confirmed drops not yet passed to onDrop
+	drops []Drop
+	// consumptions outside a map that may still be the entry cost of the next one
+	pending []spend
+	// recent per-item changes, oldest first, for reconciling rearrangements
+	moves []move
+	// the current burst of InitBagData lines and the time of the latest one
+	inits   []types.BagEvent
+	initsAt time.Time
 }
 
 func New() *Tracker {
-	return &Tracker{state: State{Inventory: make(map[slotKey]int)}}
+	return &Tracker{state: State{Inventory: make(map[slotKey]int), IdleThreshold: DefaultIdleThreshold}}
 }
 
 // GetState returns a snapshot copy of current state for use by UI/CLI.
 func (t *Tracker) GetState() State {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	return t.copyState()
+}
+
+// copyState returns a copy of the state. t.mu must be held.
+func (t *Tracker) copyState() State {
 	// Deep-ish copy for safe reading
 	st := State{
 		InMap:            t.state.InMap,
@@ -56,36 +285,99 @@ func (t *Tracker) GetState() State {
 		LastEvents:       make([]types.Event, len(t.state.LastEvents)),
 		SessionStartedAt: t.state.SessionStartedAt,
 		SessionEndedAt:   t.state.SessionEndedAt,
-		Current: MapSession{
-			StartedAt: t.state.Current.StartedAt,
-			EndedAt:   t.state.Current.EndedAt,
-			Active:    t.state.Current.Active,
-			Tally:     make(map[int]int, len(t.state.Current.Tally)),
-		},
-		Completed: make([]MapSession, 0, len(t.state.Completed)),
+		InMapTime:        t.state.InMapTime,
+		TownTime:         t.state.TownTime,
+		IdleTime:         t.state.IdleTime,
+		LastEventAt:      t.state.LastEventAt,
+		IdleThreshold:    t.state.IdleThreshold,
+		Paused:           t.state.Paused,
+		PausedTime:       t.state.PausedTime,
+		Pauses:           append([]PauseInterval(nil), t.state.Pauses...),
+		Current:          t.state.Current.clone(),
+		Completed:        make([]MapSession, 0, len(t.state.Completed)),
 	}
 	for k, v := range t.state.Inventory {
 		st.Inventory[k] = v
 	}
 	copy(st.LastEvents, t.state.LastEvents)
-	for k, v := range t.state.Current.Tally {
-		st.Current.Tally[k] = v
-	}
 	for _, m := range t.state.Completed {
-		cm := MapSession{
-			StartedAt: m.StartedAt,
-			EndedAt:   m.EndedAt,
-			Active:    m.Active,
-			Tally:     make(map[int]int, len(m.Tally)),
-		}
-		for k, v := range m.Tally {
-			cm.Tally[k] = v
-		}
-		st.Completed = append(st.Completed, cm)
+		st.Completed = append(st.Completed, m.clone())
 	}
 	return st
 }
 
+// OnMapComplete registers fn to be called whenever a map session is finalized.
+// The callback runs outside the tracker lock, so it may call GetState.
+func (t *Tracker) OnMapComplete(fn MapCompleteFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onMapComplete = fn
+}
+
+// OnMapStart registers fn to be called whenever a map session starts.
+// The callback runs outside the tracker lock, so it may call GetState.
+func (t *Tracker) OnMapStart(fn MapStartFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onMapStart = fn
+}
+
+// OnDrop registers fn to be called for every drop once it is confirmed (see Drop), which is
+// up to ReconcileWindow after it was logged. The callback runs outside the tracker lock.
+func (t *Tracker) OnDrop(fn DropFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onDrop = fn
+}
+
+// SetIdleThreshold sets the gap between events after which time counts as idle (0 disables idle detection).
+func (t *Tracker) SetIdleThreshold(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state.IdleThreshold = d
+}
+
+// Pause stops counting drops, consumed items and time from at until Resume. Events are still
+// applied to the inventory baseline and map transitions. It reports false if already paused.
+func (t *Tracker) Pause(at time.Time) bool {
+	t.mu.Lock()
+	if t.state.Paused {
+		t.mu.Unlock()
+		return false
+	}
+	t.flushInits()
+	t.account(at)
+	t.settleMoves()
+	t.state.Paused = true
+	t.state.Pauses = append(t.state.Pauses, PauseInterval{Start: at})
+	t.unlockAndNotify()
+	return true
+}
+
+// Resume continues counting after Pause, closing the paused interval at at. It reports false if not paused.
+func (t *Tracker) Resume(at time.Time) bool {
+	t.mu.Lock()
+	if !t.state.Paused {
+		t.mu.Unlock()
+		return false
+	}
+	t.flushInits()
+	t.account(at)
+	t.settleMoves()
+	t.state.Paused = false
+	t.state.Pauses[len(t.state.Pauses)-1].End = at
+	t.unlockAndNotify()
+	return true
+}
+
+// SetPricing registers fn to value each drop as it is counted, locking in the price
+// in effect at that moment (MapSession.Value). fn runs with the tracker lock held.
+func (t *Tracker) SetPricing(fn PriceFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.price = fn
+}
+
 func (t *Tracker) appendEvent(ev types.Event) {
 	const max = 100
 	t.state.LastEvents = append(t.state.LastEvents, ev)
@@ -102,59 +394,318 @@ func (t *Tracker) OnEvent(ev *types.Event) {
 		return
 	}
 	t.mu.Lock()
-	defer t.mu.Unlock()
+	done := t.apply(ev)
+	fn, startFn := t.onMapComplete, t.onMapStart
+	sessionStart := t.state.SessionStartedAt
+	var started *MapSession
+	if ev.Kind == types.EventMapStart && startFn != nil {
+		c := t.state.Current.clone()
+		started = &c
+	}
+	t.unlockAndNotify()
+	if fn != nil && done != nil {
+		fn(sessionStart, *done)
+	}
+	if started != nil {
+		startFn(sessionStart, *started)
+	}
+}
+
+// EndGame ends the game session whose log has run out, as reading each log with a new tracker
+// would: a pending bag sync is applied, a map still open ends at the last event, and the
+// inventory baseline is dropped so the first bag sync of the next log only sets a new one.
+// Completed maps and the session totals are kept.
+func (t *Tracker) EndGame() {
+	t.mu.Lock()
+	t.flushInits()
+	done := t.endMap(t.state.LastEventAt)
+	t.settleMoves()
+	t.pending = nil
+	t.state.Inventory = make(map[slotKey]int)
+	fn, sessionStart := t.onMapComplete, t.state.SessionStartedAt
+	t.unlockAndNotify()
+	if fn != nil && done != nil {
+		fn(sessionStart, *done)
+	}
+}
+
+// endMap finalizes the current map at the given time, if one is open, and returns a copy of it.
+// t.mu must be held.
+func (t *Tracker) endMap(at time.Time) *MapSession {
+	if !t.state.InMap {
+		return nil
+	}
+	t.state.InMap = false
+	s := t.state.Current
+	s.Active = false
+	s.EndedAt = at
+	s.PausedTime = t.state.PausedBetween(s.StartedAt, s.EndedAt)
+	// append to completed
+	t.state.Completed = append(t.state.Completed, s)
+	c := s.clone()
+	// set session end
+	t.state.SessionEndedAt = at
+	// reset current
+	t.state.Current = s
+	t.settleMoves()
+	return &c
+}
+
+// unlockAndNotify releases t.mu and passes the drops confirmed meanwhile to onDrop.
+func (t *Tracker) unlockAndNotify() {
+	drops, fn := t.drops, t.onDrop
+	t.drops = nil
+	t.mu.Unlock()
+	if fn == nil {
+		return
+	}
+	for _, d := range drops {
+		fn(d)
+	}
+}
+
+// apply updates state for ev with t.mu held. It returns a copy of the map session
+// finalized by this event, if any.
+func (t *Tracker) apply(ev *types.Event) (done *MapSession) {
 	// record for debug view
 	t.appendEvent(*ev)
+	t.account(ev.Time)
+	t.pruneMoves(ev.Time)
+	// InitBagData lines are held until their burst ends (see resync.go)
+	if len(t.inits) > 0 && (ev.Kind != types.EventBagInit || ev.Bag == nil || !sameBurst(t.initsAt, ev.Time)) {
+		t.flushInits()
+	}
 
 	switch ev.Kind {
 	case types.EventMapStart:
+		// changes before the transition must not be undone from the new map
+		t.settleMoves()
 		// Start a new map; if one is active, finalize it first and store it
 		if t.state.InMap && t.state.Current.Active {
 			s := t.state.Current
 			s.Active = false
 			s.EndedAt = ev.Time
+			s.PausedTime = t.state.PausedBetween(s.StartedAt, s.EndedAt)
 			// append completed map
 			t.state.Completed = append(t.state.Completed, s)
+			c := s.clone()
+			done = &c
 		}
 		// set session start if this is the first map after reset
 		if t.state.SessionStartedAt.IsZero() {
 			t.state.SessionStartedAt = ev.Time
 		}
 		t.state.InMap = true
-		t.state.Current = MapSession{StartedAt: ev.Time, Active: true, Tally: make(map[int]int)}
-	case types.EventMapEnd:
-		if t.state.InMap {
-			t.state.InMap = false
-			s := t.state.Current
-			s.Active = false
-			s.EndedAt = ev.Time
-			// append to completed
-			t.state.Completed = append(t.state.Completed, s)
-			// set session end
-			t.state.SessionEndedAt = ev.Time
-			// reset current
-			t.state.Current = s
+		t.state.Current = MapSession{StartedAt: ev.Time, Active: true, Tally: make(map[int]int), Value: make(map[int]float64),
+			Spent: make(map[int]int), SpentValue: make(map[int]float64)}
+		// charge items consumed just before entering (e.g. the compass that opened the map)
+		for _, sp := range t.pending {
+			if !t.state.Paused && !sp.at.Before(ev.Time.Add(-PreMapSpendWindow)) {
+				t.state.Current.addSpent(sp.id, sp.n, sp.value, t.price != nil)
+			}
 		}
+		t.pending = nil
+		if ev.Scene != nil {
+			t.state.Current.MapKey = ev.Scene.MapKey
+			t.state.Current.Region = ev.Scene.Region
+			t.state.Current.ScenePath = ev.Scene.Path
+			t.state.Current.PrevScene = ev.Scene.PrevPath
+		}
+	case types.EventMapEnd:
+		done = t.endMap(ev.Time)
 	case types.EventBagInit:
 		if ev.Bag == nil {
-			return
+			return nil
 		}
-		// Initialize/refresh inventory snapshot but do not count towards drops.
-		key := slotKey{PageID: ev.Bag.PageID, SlotID: ev.Bag.SlotID, ConfigBaseID: ev.Bag.ConfigBaseID}
-		t.state.Inventory[key] = ev.Bag.Num
+		t.inits = append(t.inits, *ev.Bag)
+		t.initsAt = ev.Time
 	case types.EventBagMod:
 		if ev.Bag == nil {
-			return
+			return nil
 		}
 		key := slotKey{PageID: ev.Bag.PageID, SlotID: ev.Bag.SlotID, ConfigBaseID: ev.Bag.ConfigBaseID}
+		// A different item taking over the slot means its previous occupant moved out (e.g. a sort)
+		for id, n := range t.vacate(key) {
+			t.change(key, id, -n, ev.Time)
+		}
 		prev := t.state.Inventory[key]
 		delta := ev.Bag.Num - prev
 		// Update inventory regardless of map state
 		t.state.Inventory[key] = ev.Bag.Num
-		// Count only positive increments while inside a map
-		if delta > 0 && t.state.InMap && t.state.Current.Active {
-			t.state.Current.Tally[ev.Bag.ConfigBaseID] += delta
-			t.state.TotalDrops += delta
+		if delta != 0 {
+			t.change(key, ev.Bag.ConfigBaseID, delta, ev.Time)
+		}
+	}
+	return done
+}
+
+// account adds the time since the previous event to the session's activity split.
+func (t *Tracker) account(at time.Time) {
+	st := &t.state
+	if !st.SessionStartedAt.IsZero() && at.After(st.LastEventAt) && !st.LastEventAt.IsZero() {
+		a := Activity{InMap: st.InMapTime, Town: st.TownTime, Idle: st.IdleTime, Paused: st.PausedTime}
+		st.addGap(&a, at.Sub(st.LastEventAt))
+		st.InMapTime, st.TownTime, st.IdleTime, st.PausedTime = a.InMap, a.Town, a.Idle, a.Paused
+	}
+	if at.After(st.LastEventAt) {
+		st.LastEventAt = at
+	}
+}
+
+// vacate removes the entries of other items recorded in key's slot and returns their counts.
+func (t *Tracker) vacate(key slotKey) map[int]int {
+	var out map[int]int
+	for k, n := range t.state.Inventory {
+		if k.PageID != key.PageID || k.SlotID != key.SlotID || k.ConfigBaseID == key.ConfigBaseID {
+			continue
+		}
+		delete(t.state.Inventory, k)
+		if n > 0 {
+			if out == nil {
+				out = make(map[int]int)
+			}
+			out[k.ConfigBaseID] += n
+		}
+	}
+	return out
+}
+
+// change applies a count change of an item in key's slot. Increases and decreases of the same item
+// in different slots within ReconcileWindow cancel out (moves, splits, sorts, page transfers); only
+// the net gain counts as a drop while in a map and only the net loss as consumed (see spend).
+func (t *Tracker) change(key slotKey, id, delta int, at time.Time) {
+	if t.state.Paused {
+		return
+	}
+	gain := delta > 0
+	n := delta
+	if !gain {
+		n = -delta
+	}
+	if n = t.reconcile(key, id, n, gain); n == 0 {
+		return
+	}
+	mv := move{page: key.PageID, slot: key.SlotID, id: id, n: n, gain: gain, at: at}
+	if t.price != nil {
+		mv.unit = t.price(id, at)
+	}
+	switch {
+	case !gain:
+		mv.where = t.spend(id, n, mv.unit, at)
+	case t.state.InMap && t.state.Current.Active:
+		// Count only net increments while inside a map
+		mv.where = inMap
+		t.state.Current.Tally[id] += n
+		t.state.TotalDrops += n
+		if t.price != nil {
+			if t.state.Current.Value == nil {
+				t.state.Current.Value = make(map[int]float64)
+			}
+			t.state.Current.Value[id] += float64(n) * mv.unit
+		}
+	}
+	t.moves = append(t.moves, mv)
+}
+
+// reconcile cancels up to n items of id against recent opposite changes in other slots, newest
+// first, undoing whatever they were counted as. It returns the part of n left unmatched.
+func (t *Tracker) reconcile(key slotKey, id, n int, gain bool) int {
+	for i := len(t.moves) - 1; i >= 0 && n > 0; i-- {
+		mv := &t.moves[i]
+		if mv.id != id || mv.gain == gain || mv.n == 0 || (mv.page == key.PageID && mv.slot == key.SlotID) {
+			continue
+		}
+		k := min(n, mv.n)
+		mv.n -= k
+		n -= k
+		switch {
+		case mv.where == inMap && mv.gain:
+			t.state.TotalDrops -= k
+			cur := &t.state.Current
+			cur.Tally[id] -= k
+			cur.Value[id] -= float64(k) * mv.unit
+			if cur.Tally[id] <= 0 {
+				delete(cur.Tally, id)
+				delete(cur.Value, id)
+			}
+		case mv.where == inMap:
+			cur := &t.state.Current
+			cur.Spent[id] -= k
+			cur.SpentValue[id] -= float64(k) * mv.unit
+			if cur.Spent[id] <= 0 {
+				delete(cur.Spent, id)
+				delete(cur.SpentValue, id)
+			}
+		case mv.where == preMap:
+			t.unspend(id, k, mv.unit)
+		}
+	}
+	return n
+}
+
+// pruneMoves forgets changes too old to be reconciled at now, confirming the drops among them.
+func (t *Tracker) pruneMoves(now time.Time) {
+	i := 0
+	for i < len(t.moves) && (t.moves[i].n == 0 || t.moves[i].at.Before(now.Add(-ReconcileWindow))) {
+		t.confirm(t.moves[i])
+		i++
+	}
+	t.moves = t.moves[i:]
+}
+
+// settleMoves forgets all recent changes, confirming the drops among them.
+func (t *Tracker) settleMoves() {
+	for _, mv := range t.moves {
+		t.confirm(mv)
+	}
+	t.moves = nil
+}
+
+// confirm queues the unmatched part of an in-map gain for onDrop.
+func (t *Tracker) confirm(mv move) {
+	if t.onDrop == nil || !mv.gain || mv.where != inMap || mv.n == 0 {
+		return
+	}
+	t.drops = append(t.drops, Drop{ItemID: mv.id, Count: mv.n, Unit: mv.unit, At: mv.at, MapKey: t.state.Current.MapKey})
+}
+
+// spend records n consumed items of id worth unit each: inside a map they are charged to it,
+// otherwise they are held for PreMapSpendWindow in case a map starts. A completed map is
+// already saved and published, so nothing consumed after its end is charged to it. t.mu must be held.
+func (t *Tracker) spend(id, n int, unit float64, at time.Time) moveTarget {
+	value := float64(n) * unit
+	if t.state.InMap && t.state.Current.Active {
+		t.state.Current.addSpent(id, n, value, t.price != nil)
+		return inMap
+	}
+	// forget consumptions that can no longer be charged to a map
+	keep := t.pending[:0]
+	for _, sp := range t.pending {
+		if !sp.at.Before(at.Add(-PreMapSpendWindow)) {
+			keep = append(keep, sp)
+		}
+	}
+	t.pending = append(keep, spend{id: id, n: n, value: value, at: at})
+	return preMap
+}
+
+// unspend takes back k items of id from the pending consumptions, newest first.
+func (t *Tracker) unspend(id, k int, unit float64) {
+	for i := len(t.pending) - 1; i >= 0 && k > 0; i-- {
+		sp := &t.pending[i]
+		if sp.id != id {
+			continue
+		}
+		m := min(k, sp.n)
+		sp.n -= m
+		sp.value -= float64(m) * unit
+		k -= m
+	}
+	keep := t.pending[:0]
+	for _, sp := range t.pending {
+		if sp.n > 0 {
+			keep = append(keep, sp)
 		}
 	}
+	t.pending = keep
 }
@@ -1,6 +1,7 @@
 package tracker
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -70,3 +71,211 @@ func TestSessionRestartCreatesNewSession(t *testing.T) {
 		t.Fatalf("expected session start at %v, got %v", start2, st.Current.StartedAt)
 	}
 }
+
+func TestMapSessionKeepsSceneIdentity(t *testing.T) {
+	trk := New()
+	start := time.Now()
+	scene := &types.SceneEvent{Path: "/Game/Art/Maps/07YJ/YJ_A/YJ_A.YJ_A", MapKey: "YJ_A", Region: "07YJ", PrevPath: "/Game/Art/Maps/UI/LoginScene/LoginScene"}
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start, Scene: scene})
+	st := trk.GetState()
+	if st.Current.MapKey != "YJ_A" || st.Current.Region != "07YJ" || st.Current.ScenePath != scene.Path || st.Current.PrevScene != scene.PrevPath {
+		t.Fatalf("unexpected current map identity: %+v", st.Current)
+	}
+	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(time.Minute)})
+	st = trk.GetState()
+	if len(st.Completed) != 1 || st.Completed[0].MapKey != "YJ_A" || st.Completed[0].Region != "07YJ" {
+		t.Fatalf("expected completed map to keep identity, got %+v", st.Completed)
+	}
+}
+
+func TestOnMapCompleteCallback(t *testing.T) {
+	trk := New()
+	var got []MapSession
+	var sessionStarts []time.Time
+	trk.OnMapComplete(func(sessionStartedAt time.Time, m MapSession) {
+		// GetState must not deadlock from inside the callback
+		_ = trk.GetState()
+		sessionStarts = append(sessionStarts, sessionStartedAt)
+		got = append(got, m)
+	})
+	var order []string
+	trk.OnMapStart(func(sessionStartedAt time.Time, m MapSession) {
+		_ = trk.GetState()
+		if len(got) > 0 {
+			order = append(order, "end "+got[len(got)-1].MapKey)
+		}
+		order = append(order, "start "+m.MapKey)
+	})
+	start := time.Now()
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start, Scene: &types.SceneEvent{MapKey: "A"}})
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start.Add(time.Minute), Scene: &types.SceneEvent{MapKey: "B"}})
+	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(2 * time.Minute)})
+	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(3 * time.Minute)})
+	if len(got) != 2 || got[0].MapKey != "A" || got[1].MapKey != "B" {
+		t.Fatalf("unexpected completed maps: %+v", got)
+	}
+	if got[1].Active || !got[1].EndedAt.Equal(start.Add(2*time.Minute)) {
+		t.Fatalf("expected finalized session, got %+v", got[1])
+	}
+	if !sessionStarts[0].Equal(start) || !sessionStarts[1].Equal(start) {
+		t.Fatalf("unexpected session starts: %v", sessionStarts)
+	}
+	if strings.Join(order, ",") != "start A,end A,start B" {
+		t.Fatalf("expected map A finalized before B starts, got %v", order)
+	}
+}
+
+func TestPricingLocksValueAtDropTime(t *testing.T) {
+	trk := New()
+	price := 2.0
+	trk.SetPricing(func(id int, at time.Time) float64 { return price })
+	start := time.Now()
+	trk.OnEvent(&types.Event{Kind: types.EventBagInit, Time: start, Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 7, Num: 0}})
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start})
+	trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start.Add(time.Second), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 7, Num: 3}})
+	price = 10 // a later price refresh must not revalue earlier drops
+	trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: start.Add(2 * time.Second), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 7, Num: 4}})
+	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(time.Minute)})
+
+	st := trk.GetState()
+	if got := st.Completed[0].Value[7]; got != 3*2+10 {
+		t.Fatalf("expected value locked per drop (16), got %v", got)
+	}
+	data, err := trk.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	restored, err := Restore(data)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got := restored.GetState().Completed[0].Value[7]; got != 16 {
+		t.Fatalf("snapshot lost locked value: %v", got)
+	}
+}
+
+func TestNegativeDeltasTrackedAsSpent(t *testing.T) {
+	trk := New()
+	trk.SetPricing(func(id int, at time.Time) float64 { return 5 })
+	start := time.Now()
+	bag := func(at time.Duration, slot, id, num int) *types.Event {
+		return &types.Event{Kind: types.EventBagMod, Time: start.Add(at), Bag: &types.BagEvent{PageID: 1, SlotID: slot, ConfigBaseID: id, Num: num}}
+	}
+	trk.OnEvent(&types.Event{Kind: types.EventBagInit, Time: start, Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 50, Num: 10}})
+	trk.OnEvent(bag(0, 1, 50, 9))             // consumed long before the map: not charged
+	trk.OnEvent(bag(5*time.Minute, 1, 50, 8)) // compass used to open the map
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start.Add(5*time.Minute + 10*time.Second)})
+	trk.OnEvent(bag(6*time.Minute, 1, 50, 7)) // consumed inside the map
+	trk.OnEvent(bag(7*time.Minute, 2, 60, 3)) // a drop
+	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: start.Add(8 * time.Minute)})
+
+	m := trk.GetState().Completed[0]
+	if m.Spent[50] != 2 || m.SpentValue[50] != 10 {
+		t.Fatalf("expected 2 spent worth 10, got %d worth %v", m.Spent[50], m.SpentValue[50])
+	}
+	if m.Tally[60] != 3 || m.Tally[50] != 0 {
+		t.Fatalf("spent items must not count as drops: %v", m.Tally)
+	}
+	if trk.GetState().TotalDrops != 3 {
+		t.Fatalf("expected 3 drops, got %d", trk.GetState().TotalDrops)
+	}
+}
+
+func TestActivitySplitsInMapTownAndIdleTime(t *testing.T) {
+	trk := New()
+	trk.SetIdleThreshold(2 * time.Minute)
+	start := time.Now()
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	trk.OnEvent(&types.Event{Kind: types.EventBagInit, Time: at(0), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 1, Num: 1}})
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: at(time.Minute)}) // before the session: not counted
+	trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: at(2 * time.Minute), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 1, Num: 2}})
+	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: at(3 * time.Minute)})
+	trk.OnEvent(&types.Event{Kind: types.EventBagMod, Time: at(4 * time.Minute), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 1, Num: 3}})
+	// AFK for ten minutes in town
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: at(14 * time.Minute)})
+
+	st := trk.GetState()
+	a := st.Activity(at(15 * time.Minute))
+	if a.InMap != 3*time.Minute || a.Town != time.Minute || a.Idle != 10*time.Minute {
+		t.Fatalf("unexpected split: %+v", a)
+	}
+	if a.Active() != 4*time.Minute {
+		t.Fatalf("Active = %v", a.Active())
+	}
+	// an open gap past the threshold is idle
+	if a := st.Activity(at(20 * time.Minute)); a.Idle != 16*time.Minute || a.InMap != 2*time.Minute {
+		t.Fatalf("unexpected split with open idle gap: %+v", a)
+	}
+}
+
+func TestPauseKeepsBaselineButSkipsDropsAndTime(t *testing.T) {
+	trk := New()
+	start := time.Now()
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	bag := func(d time.Duration, num int) *types.Event {
+		return &types.Event{Kind: types.EventBagMod, Time: at(d), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 9, Num: num}}
+	}
+	trk.OnEvent(&types.Event{Kind: types.EventBagInit, Time: at(0), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 9, Num: 1}})
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: at(0)})
+	trk.OnEvent(bag(time.Minute, 2))
+	if !trk.Pause(at(2*time.Minute)) || trk.Pause(at(2*time.Minute)) {
+		t.Fatalf("expected only the first Pause to succeed")
+	}
+	trk.OnEvent(bag(3*time.Minute, 6)) // picked up while paused
+	if !trk.Resume(at(5 * time.Minute)) {
+		t.Fatalf("expected Resume to succeed")
+	}
+	trk.OnEvent(bag(6*time.Minute, 7)) // counted against the baseline kept while paused
+	trk.OnEvent(&types.Event{Kind: types.EventMapEnd, Time: at(7 * time.Minute)})
+
+	st := trk.GetState()
+	m := st.Completed[0]
+	if m.Tally[9] != 2 || st.TotalDrops != 2 {
+		t.Fatalf("expected 2 drops outside the pause, got tally %v total %d", m.Tally, st.TotalDrops)
+	}
+	if m.PausedTime != 3*time.Minute || m.Duration() != 4*time.Minute {
+		t.Fatalf("expected 3m paused of a 4m run, got %v paused, %v run", m.PausedTime, m.Duration())
+	}
+	if a := st.Activity(at(7 * time.Minute)); a.InMap != 4*time.Minute || a.Paused != 3*time.Minute {
+		t.Fatalf("unexpected activity: %+v", a)
+	}
+	if len(st.Pauses) != 1 || !st.Pauses[0].Start.Equal(at(2*time.Minute)) || !st.Pauses[0].End.Equal(at(5*time.Minute)) {
+		t.Fatalf("unexpected pause timeline: %+v", st.Pauses)
+	}
+
+	data, err := trk.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	restored, err := Restore(data)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if rs := restored.GetState(); len(rs.Pauses) != 1 || rs.PausedTime != 3*time.Minute || rs.Completed[0].PausedTime != 3*time.Minute {
+		t.Fatalf("snapshot lost pause state: %+v", rs.Pauses)
+	}
+}
+
+func TestEndGameEndsTheOpenMapAndBaseline(t *testing.T) {
+	trk := New()
+	var got []MapSession
+	trk.OnMapComplete(func(_ time.Time, m MapSession) { got = append(got, m) })
+	start := time.Now()
+	bag := func(d time.Duration, kind types.EventKind, num int) *types.Event {
+		return &types.Event{Kind: kind, Time: start.Add(d), Bag: &types.BagEvent{PageID: 1, SlotID: 1, ConfigBaseID: 42, Num: num}}
+	}
+	trk.OnEvent(&types.Event{Kind: types.EventMapStart, Time: start, Scene: &types.SceneEvent{MapKey: "A"}})
+	trk.OnEvent(bag(time.Second, types.EventBagInit, 0))
+	trk.OnEvent(bag(2*time.Minute, types.EventBagMod, 3))
+	trk.EndGame()
+	if len(got) != 1 || got[0].MapKey != "A" || !got[0].EndedAt.Equal(start.Add(2*time.Minute)) || got[0].Tally[42] != 3 {
+		t.Fatalf("expected the open map ended at the last event, got %+v", got)
+	}
+	// the next game lists the bag again: a new baseline, not a change
+	trk.OnEvent(bag(10*time.Hour, types.EventBagInit, 10))
+	trk.Flush()
+	st := trk.GetState()
+	if st.InMap || st.TotalDrops != 3 || len(st.Completed) != 1 || st.Completed[0].Tally[42] != 3 {
+		t.Fatalf("unexpected state after the next game's bag sync: %+v", st)
+	}
+}
@@ -36,10 +36,19 @@ type BagEvent struct {
 	Num          int
 }
 
+// SceneEvent captures the scene transition carried by MapStart/MapEnd lines.
+type SceneEvent struct {
+	Path     string // full NextSceneName path, e.g. /Game/Art/Maps/07YJ/YJ_.../YJ_....YJ_...
+	MapKey   string // normalized map name, e.g. YJ_YongZhouHuiLang200
+	Region   string // region folder under /Game/Art/Maps, e.g. 07YJ
+	PrevPath string // LastSceneName path, if present
+}
+
 // Event is a normalized parsed log event.
 type Event struct {
-	Kind EventKind
-	Time time.Time
-	Line string // original line
-	Bag  *BagEvent
+	Kind  EventKind
+	Time  time.Time
+	Line  string // original line
+	Bag   *BagEvent
+	Scene *SceneEvent
 }
@@ -0,0 +1,391 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Event types a sink can subscribe to.
+const (
+	MapStart   = "map_start"
+	MapEnd     = "map_end"
+	SessionEnd = "session_end"
+	GoalMet    = "goal_met"
+	// DropAlert is sent only to the webhook of the alert rule that matched.
+	DropAlert = "drop_alert"
+)
+
+// ErrInvalid is returned for unusable sink configurations.
+var ErrInvalid = errors.New("webhook: invalid config")
+
+// Event is a tracker lifecycle event. Exactly one of Map, Session, Goal and Alert is set, matching Type.
+type Event struct {
+	Type    string    `json:"type"`
+	At      time.Time `json:"at"`
+	Text    string    `json:"text"` // one-line summary, filled in by Publish when empty
+	Map     *Map      `json:"map,omitempty"`
+	Session *Session  `json:"session,omitempty"`
+	Goal    *Goal     `json:"goal,omitempty"`
+	Alert   *Alert    `json:"alert,omitempty"`
+}
+
+// Map is a started or finished map run. Values are in item table price units.
+type Map struct {
+	MapKey          string    `json:"map_key"`
+	Region          string    `json:"region"`
+	StartedAt       time.Time `json:"started_at"`
+	EndedAt         time.Time `json:"ended_at,omitzero"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Drops           int       `json:"drops"`
+	Earnings        float64   `json:"earnings"`
+	Cost            float64   `json:"cost"`
+	Net             float64   `json:"net"`
+	Items           []Item    `json:"items,omitempty"` // drops, most valuable first
+}
+
+// Item is one line of a map's tally.
+type Item struct {
+	ID    int     `json:"id"`
+	Name  string  `json:"name"`
+	Count int     `json:"count"`
+	Value float64 `json:"value"`
+}
+
+// Session summarizes a finished session.
+type Session struct {
+	StartedAt       time.Time `json:"started_at"`
+	EndedAt         time.Time `json:"ended_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Maps            int       `json:"maps"`
+	Drops           int       `json:"drops"`
+	Earnings        float64   `json:"earnings"`
+	Cost            float64   `json:"cost"`
+	Net             float64   `json:"net"`
+	EarningsPerHour float64   `json:"earnings_per_hour"`
+}
+
+// Goal is a met session goal.
+type Goal struct {
+	ID      string  `json:"id"`
+	Label   string  `json:"label"`
+	Target  float64 `json:"target"`
+	Current float64 `json:"current"`
+}
+
+// Alert is a drop matched by a drop alert rule. Values are in item table price units.
+type Alert struct {
+	Rule   string  `json:"rule"`
+	ItemID int     `json:"item_id"`
+	Name   string  `json:"name"`
+	Type   string  `json:"type"`
+	Count  int     `json:"count"`
+	Unit   float64 `json:"unit"`
+	Total  float64 `json:"total"`
+	MapKey string  `json:"map_key"`
+}
+
+// Config describes one webhook sink.
+type Config struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Events to send; all of them when empty.
+	Events []string `json:"events,omitempty"`
+	// Preset is a built-in template ("discord"); Template, when set, wins. Without either the
+	// event itself is sent as JSON.
+	Preset   string            `json:"preset,omitempty"`
+	Template string            `json:"template,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	// Retries is how many times a failed POST is retried (default 3); the wait starts at
+	// BackoffSeconds (default 1) and doubles up to 30s, or follows the server's Retry-After.
+	Retries        *int    `json:"retries,omitempty"`
+	BackoffSeconds float64 `json:"backoff_seconds,omitempty"`
+}
+
+// presets are the built-in templates, rendered with the Event as dot.
+var presets = map[string]string{
+	"discord": `{"username":"GoTorch","content":{{json .Text}}}`,
+}
+
+// funcs are available to templates: json encodes a value (use it for every string),
+// money formats a value with two decimals and duration formats seconds like 4m12s.
+var funcs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"money":    func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) },
+	"duration": func(s float64) string { return formatDuration(s) },
+}
+
+// DefaultPath returns the webhook config file location, honoring GOTORCH_WEBHOOKS.
+func DefaultPath() string {
+	if p := os.Getenv("GOTORCH_WEBHOOKS"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "gotorch_webhooks.json"
+	}
+	return filepath.Join(dir, "GoTorch", "webhooks.json")
+}
+
+// Load reads a JSON array of sink configs from path. A missing file means no sinks.
+func Load(path string) ([]Config, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cfgs []Config
+	if err := json.Unmarshal(b, &cfgs); err != nil {
+		return nil, fmt.Errorf("webhook: %s: %w", path, err)
+	}
+	return cfgs, nil
+}
+
+// Sink posts events to one URL.
+type Sink struct {
+	cfg     Config
+	tmpl    *template.Template // nil: send the event as JSON
+	retries int
+	backoff time.Duration
+	client  *http.Client
+}
+
+// NewSink validates cfg and parses its template. client may be nil for a client with a 10s timeout.
+func NewSink(cfg Config, client *http.Client) (*Sink, error) {
+	if !strings.HasPrefix(cfg.URL, "http://") && !strings.HasPrefix(cfg.URL, "https://") {
+		return nil, fmt.Errorf("%w %q: url must be http(s)", ErrInvalid, cfg.Name)
+	}
+	for _, ev := range cfg.Events {
+		if !slices.Contains([]string{MapStart, MapEnd, SessionEnd, GoalMet}, ev) {
+			return nil, fmt.Errorf("%w %q: unknown event %q", ErrInvalid, cfg.Name, ev)
+		}
+	}
+	s := &Sink{cfg: cfg, retries: 3, backoff: time.Second, client: client}
+	if cfg.Retries != nil {
+		if *cfg.Retries < 0 {
+			return nil, fmt.Errorf("%w %q: negative retries", ErrInvalid, cfg.Name)
+		}
+		s.retries = *cfg.Retries
+	}
+	if cfg.BackoffSeconds > 0 {
+		s.backoff = time.Duration(cfg.BackoffSeconds * float64(time.Second))
+	}
+	if s.client == nil {
+		s.client = &http.Client{Timeout: 10 * time.Second}
+	}
+	text := cfg.Template
+	if text == "" && cfg.Preset != "" {
+		var ok bool
+		if text, ok = presets[strings.ToLower(cfg.Preset)]; !ok {
+			return nil, fmt.Errorf("%w %q: unknown preset %q", ErrInvalid, cfg.Name, cfg.Preset)
+		}
+	}
+	if text != "" {
+		t, err := template.New(cfg.Name).Funcs(funcs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalid, cfg.Name, err)
+		}
+		s.tmpl = t
+	}
+	return s, nil
+}
+
+// Wants reports whether the sink subscribes to events of type typ.
+func (s *Sink) Wants(typ string) bool {
+	return len(s.cfg.Events) == 0 || slices.Contains(s.cfg.Events, typ)
+}
+
+// Render returns the request body for ev. Templates must produce valid JSON.
+func (s *Sink) Render(ev Event) ([]byte, error) {
+	if s.tmpl == nil {
+		return json.Marshal(ev)
+	}
+	var buf bytes.Buffer
+	if err := s.tmpl.Execute(&buf, ev); err != nil {
+		return nil, fmt.Errorf("webhook %q: %w", s.cfg.Name, err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook %q: template output is not valid JSON", s.cfg.Name)
+	}
+	return buf.Bytes(), nil
+}
+
+// Send renders ev and POSTs it, retrying network errors, 429 and 5xx responses with backoff.
+func (s *Sink) Send(ctx context.Context, ev Event) error {
+	body, err := s.Render(ev)
+	if err != nil {
+		return err
+	}
+	wait := s.backoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := s.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		var perm permanentError
+		if errors.As(err, &perm) || attempt >= s.retries {
+			return err
+		}
+		d := wait
+		if retryAfter > 0 {
+			d = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+		wait = min(2*wait, 30*time.Second)
+	}
+}
+
+// permanentError is a response that retrying will not fix (4xx other than 429).
+type permanentError struct{ error }
+
+// post makes one attempt. On 429 it returns the server's Retry-After, if any.
+func (s *Sink) post(ctx context.Context, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		secs, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return time.Duration(secs) * time.Second, fmt.Errorf("webhook %q: %s", s.cfg.Name, resp.Status)
+	case resp.StatusCode >= 500:
+		return 0, fmt.Errorf("webhook %q: %s", s.cfg.Name, resp.Status)
+	}
+	return 0, permanentError{fmt.Errorf("webhook %q: %s", s.cfg.Name, resp.Status)}
+}
+
+// Dispatcher delivers published events to its sinks in the background, in order, so
+// tracker callbacks never wait on the network. It is safe for concurrent use.
+type Dispatcher struct {
+	sinks  []*Sink
+	queue  chan Event
+	done   chan struct{}
+	cancel context.CancelFunc
+	// OnError is called with failed deliveries; it may be nil.
+	OnError func(err error)
+
+	mu      sync.Mutex
+	closed  bool
+	dropped int
+}
+
+// NewDispatcher starts delivering to sinks. Call Close to stop it.
+func NewDispatcher(sinks []*Sink) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{sinks: sinks, queue: make(chan Event, 64), done: make(chan struct{}), cancel: cancel}
+	go d.run(ctx)
+	return d
+}
+
+// Publish queues ev for every subscribed sink. When the queue is full the event is dropped.
+func (d *Dispatcher) Publish(ev Event) {
+	if ev.Text == "" {
+		ev.Text = Summary(ev)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	select {
+	case d.queue <- ev:
+	default:
+		d.dropped++
+	}
+}
+
+// Dropped returns how many events were dropped because the queue was full.
+func (d *Dispatcher) Dropped() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.dropped
+}
+
+// Close stops accepting events and waits up to timeout for queued ones to be delivered.
+func (d *Dispatcher) Close(timeout time.Duration) {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+	select {
+	case <-d.done:
+	case <-time.After(timeout):
+		d.cancel()
+		<-d.done
+	}
+}
+
+func (d *Dispatcher) run(ctx context.Context) {
+	defer close(d.done)
+	for ev := range d.queue {
+		for _, s := range d.sinks {
+			if !s.Wants(ev.Type) {
+				continue
+			}
+			if err := s.Send(ctx, ev); err != nil && d.OnError != nil {
+				d.OnError(err)
+			}
+		}
+	}
+}
+
+// Summary returns a one-line description of ev.
+func Summary(ev Event) string {
+	switch {
+	case ev.Map != nil && ev.Type == MapStart:
+		return "Map started: " + ev.Map.MapKey
+	case ev.Map != nil:
+		m := ev.Map
+		s := fmt.Sprintf("Map %s finished in %s: %d drops, earnings %.2f", m.MapKey, formatDuration(m.DurationSeconds), m.Drops, m.Earnings)
+		if m.Cost > 0 {
+			s += fmt.Sprintf(", net %.2f", m.Net)
+		}
+		return s
+	case ev.Session != nil:
+		ss := ev.Session
+		return fmt.Sprintf("Session ended after %s: %d maps, %d drops, earnings %.2f (net %.2f, %.2f/hour)",
+			formatDuration(ss.DurationSeconds), ss.Maps, ss.Drops, ss.Earnings, ss.Net, ss.EarningsPerHour)
+	case ev.Goal != nil:
+		return "Goal met: " + ev.Goal.Label
+	case ev.Alert != nil:
+		return fmt.Sprintf("%s x%d", ev.Alert.Name, ev.Alert.Count)
+	}
+	return ev.Type
+}
+
+func formatDuration(secs float64) string {
+	return time.Duration(secs * float64(time.Second)).Round(time.Second).String()
+}
@@ -0,0 +1,147 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func mapEnd() Event {
+	start := time.Date(2025, 11, 4, 19, 20, 0, 0, time.UTC)
+	return Event{Type: MapEnd, At: start.Add(4 * time.Minute), Map: &Map{
+		MapKey: "YJ_YongZhouHuiLang200", StartedAt: start, EndedAt: start.Add(4 * time.Minute), DurationSeconds: 252,
+		Drops: 7, Earnings: 120.5, Cost: 20, Net: 100.5, Items: []Item{{ID: 5210, Name: "Ember", Count: 7, Value: 120.5}},
+	}}
+}
+
+// recorder is a test server that fails the first n requests with status.
+type recorder struct {
+	mu     sync.Mutex
+	n      int
+	status int
+	bodies [][]byte
+	header http.Header
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, _ := io.ReadAll(req.Body)
+	r.bodies = append(r.bodies, b)
+	r.header = req.Header.Clone()
+	if len(r.bodies) <= r.n {
+		w.WriteHeader(r.status)
+	}
+}
+
+func TestSendRetriesWithBackoff(t *testing.T) {
+	rec := &recorder{n: 2, status: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	s, err := NewSink(Config{Name: "team", URL: srv.URL, BackoffSeconds: 0.01, Headers: map[string]string{"X-Token": "abc"}}, srv.Client())
+	if err != nil {
+		t.Fatalf("NewSink: %v", err)
+	}
+	if err := s.Send(context.Background(), mapEnd()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(rec.bodies) != 3 || rec.header.Get("X-Token") != "abc" || rec.header.Get("Content-Type") != "application/json" {
+		t.Fatalf("expected 3 attempts with headers, got %d %v", len(rec.bodies), rec.header)
+	}
+	var got Event
+	if err := json.Unmarshal(rec.bodies[2], &got); err != nil || got.Type != MapEnd || got.Map.Items[0].Name != "Ember" {
+		t.Fatalf("expected the event as JSON, got %s (%v)", rec.bodies[2], err)
+	}
+
+	// give up after Retries; client errors are not retried
+	none := 1
+	rec = &recorder{n: 10, status: http.StatusBadGateway}
+	srv.Config.Handler = rec
+	s, _ = NewSink(Config{URL: srv.URL, Retries: &none, BackoffSeconds: 0.01}, srv.Client())
+	if err := s.Send(context.Background(), mapEnd()); err == nil || len(rec.bodies) != 2 {
+		t.Fatalf("expected failure after 2 attempts, got %v after %d", err, len(rec.bodies))
+	}
+	rec = &recorder{n: 10, status: http.StatusBadRequest}
+	srv.Config.Handler = rec
+	if err := s.Send(context.Background(), mapEnd()); err == nil || len(rec.bodies) != 1 {
+		t.Fatalf("expected a single attempt for 400, got %v after %d", err, len(rec.bodies))
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	ev := mapEnd()
+	ev.Text = Summary(ev)
+	s, err := NewSink(Config{URL: "https://example.com", Preset: "discord"}, nil)
+	if err != nil {
+		t.Fatalf("NewSink: %v", err)
+	}
+	b, err := s.Render(ev)
+	want := `{"username":"GoTorch","content":"Map YJ_YongZhouHuiLang200 finished in 4m12s: 7 drops, earnings 120.50, net 100.50"}`
+	if err != nil || string(b) != want {
+		t.Fatalf("discord body = %s, %v", b, err)
+	}
+
+	custom := `{"text":{{json (printf "%s: %s in %s" .Type .Map.MapKey (duration .Map.DurationSeconds))}},"net":{{money .Map.Net}}}`
+	s, _ = NewSink(Config{URL: "https://example.com", Template: custom}, nil)
+	if b, err := s.Render(ev); err != nil || string(b) != `{"text":"map_end: YJ_YongZhouHuiLang200 in 4m12s","net":100.50}` {
+		t.Fatalf("custom body = %s, %v", b, err)
+	}
+	s, _ = NewSink(Config{URL: "https://example.com", Template: `{"text": {{.Text}}}`}, nil)
+	if _, err := s.Render(ev); err == nil {
+		t.Fatal("expected invalid JSON output to be rejected")
+	}
+
+	for _, cfg := range []Config{
+		{URL: "ftp://example.com"},
+		{URL: "https://example.com", Preset: "slack2"},
+		{URL: "https://example.com", Events: []string{"map_done"}},
+		{URL: "https://example.com", Template: "{{"},
+	} {
+		if _, err := NewSink(cfg, nil); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("NewSink(%+v) error = %v; want ErrInvalid", cfg, err)
+		}
+	}
+}
+
+func TestDispatcherDeliversSubscribedEvents(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	s, _ := NewSink(Config{URL: srv.URL, Events: []string{MapEnd, GoalMet}}, srv.Client())
+	d := NewDispatcher([]*Sink{s})
+	d.Publish(Event{Type: MapStart, Map: &Map{MapKey: "A"}})
+	d.Publish(mapEnd())
+	d.Publish(Event{Type: GoalMet, Goal: &Goal{ID: "g1", Label: "Run 30 maps"}})
+	d.Close(2 * time.Second)
+	d.Publish(mapEnd()) // ignored after Close
+
+	if len(rec.bodies) != 2 {
+		t.Fatalf("expected 2 deliveries, got %d", len(rec.bodies))
+	}
+	var got Event
+	json.Unmarshal(rec.bodies[1], &got)
+	if got.Type != GoalMet || got.Text != "Goal met: Run 30 maps" {
+		t.Fatalf("unexpected goal event %s", rec.bodies[1])
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	if cfgs, err := Load(filepath.Join(dir, "missing.json")); err != nil || cfgs != nil {
+		t.Fatalf("missing file: %v, %v", cfgs, err)
+	}
+	path := filepath.Join(dir, "webhooks.json")
+	os.WriteFile(path, []byte(`[{"name":"discord","url":"https://discord.example/hook","preset":"discord","events":["map_end"],"retries":0}]`), 0o644)
+	cfgs, err := Load(path)
+	if err != nil || len(cfgs) != 1 || cfgs[0].Preset != "discord" || cfgs[0].Retries == nil || *cfgs[0].Retries != 0 {
+		t.Fatalf("Load = %+v, %v", cfgs, err)
+	}
+}